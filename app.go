@@ -1,26 +1,157 @@
 package immersim
 
 import (
+	"context"
+	"math/bits"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/studiolambda/immersim/clock"
 	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/fault"
 	"github.com/studiolambda/immersim/storage"
 )
 
 type Application struct {
-	storage *storage.Storage
-	events  *event.Events
+	storage  *storage.Storage
+	events   *event.Events
+	clock    clock.Clock
+	seed     uint64
+	seeded   bool
+	injector *fault.Injector
+	done     chan struct{}
+	mutex    sync.Mutex
 }
 
-func NewApplication(storage *storage.Storage, events *event.Events) *Application {
+var closed = func() chan struct{} {
+	done := make(chan struct{})
+	close(done)
+
+	return done
+}()
+
+func NewApplication(storage *storage.Storage, events *event.Events, clock clock.Clock) *Application {
 	return &Application{
-		storage: storage,
-		events:  events,
+		storage:  storage,
+		events:   events,
+		clock:    clock,
+		seed:     0,
+		seeded:   false,
+		injector: fault.NewInjector(storage, events),
+		done:     nil,
+		mutex:    sync.Mutex{},
 	}
 }
 
+func (application *Application) Clock() clock.Clock {
+	return application.clock
+}
+
+func (application *Application) Events() *event.Events {
+	return application.events
+}
+
+func (application *Application) SetSeed(seed uint64) {
+	application.mutex.Lock()
+	defer application.mutex.Unlock()
+
+	application.seedWith(seed)
+}
+
+func (application *Application) SetSource(source rand.Source) {
+	application.SetSeed(derive(source))
+}
+
+func (application *Application) Seed() uint64 {
+	application.mutex.Lock()
+	defer application.mutex.Unlock()
+
+	return application.seed
+}
+
+func (application *Application) seedWith(seed uint64) {
+	application.seed = seed
+	application.seeded = true
+}
+
+func derive(source rand.Source) uint64 {
+	seed := source.Uint64() ^ bits.RotateLeft64(source.Uint64(), 32)
+	seed = (seed ^ seed>>30) * 0xbf58476d1ce4e5b9
+	seed = (seed ^ seed>>27) * 0x94d049bb133111eb
+
+	return seed ^ seed>>31
+}
+
+func (application *Application) Resources() []string {
+	return application.storage.Names()
+}
+
+func (application *Application) Has(resource string) bool {
+	_, ok := application.storage.Lookup(resource)
+
+	return ok
+}
+
 func (application *Application) Read(resource string) (any, error) {
 	return application.storage.Read(resource)
 }
 
+func (application *Application) ReadSample(resource string) (storage.Sample, error) {
+	return application.storage.ReadSample(resource)
+}
+
+func (application *Application) Add(ctx context.Context, resource string, value storage.Resource) error {
+	return application.storage.Add(ctx, resource, value)
+}
+
+func (application *Application) Remove(ctx context.Context, resource string, force bool) error {
+	return application.storage.Remove(ctx, resource, force)
+}
+
+func (application *Application) Replace(ctx context.Context, resource string, value storage.Resource) error {
+	return application.storage.Replace(ctx, resource, value)
+}
+
+func (application *Application) Mount(ctx context.Context, prefix string, mounted *storage.Storage) error {
+	return application.storage.Mount(ctx, prefix, mounted)
+}
+
+func (application *Application) Instantiate(ctx context.Context, prefix string, template *storage.Template, overrides storage.Parameters) error {
+	return application.storage.Instantiate(ctx, prefix, template, overrides)
+}
+
+func (application *Application) Browse(folder string) []storage.Entry {
+	return application.storage.Browse(folder)
+}
+
+func (application *Application) Folders() []string {
+	return application.storage.Folders()
+}
+
+func (application *Application) SetPropagation(propagation storage.Propagation) {
+	application.storage.SetPropagation(propagation)
+}
+
+func (application *Application) Graph() *storage.Graph {
+	return application.storage.Graph()
+}
+
+func (application *Application) Inspect(resource string) (storage.Info, bool) {
+	return application.storage.Inspect(resource)
+}
+
+func (application *Application) Inventory() []storage.Info {
+	return application.storage.Inventory()
+}
+
+func (application *Application) Metadata(resource string) (storage.Metadata, bool) {
+	return application.storage.Metadata(resource)
+}
+
+func (application *Application) Catalog() map[string]storage.Metadata {
+	return application.storage.Catalog()
+}
+
 func (application *Application) Write(resource string, value any) error {
 	return application.storage.Write(resource, value)
 }
@@ -33,10 +164,26 @@ func (application *Application) SubscribeChanges(resource string, listener chan
 	application.events.Subscribe(event.Changed(resource), listener)
 }
 
+func (application *Application) SubscribeChangesWith(resource string, listener chan any, policy event.Policy, capacity int) {
+	application.events.SubscribeWith(event.Changed(resource), listener, policy, capacity)
+}
+
 func (application *Application) UnsubscribeChanges(resource string, listener chan<- any) {
 	application.events.Unsubscribe(event.Changed(resource), listener)
 }
 
+func (application *Application) SubscribePattern(pattern string, listener chan any) error {
+	return application.events.SubscribePattern(pattern, listener)
+}
+
+func (application *Application) UnsubscribePattern(pattern string, listener chan<- any) {
+	application.events.UnsubscribePattern(pattern, listener)
+}
+
+func (application *Application) EventStats() []event.Stat {
+	return application.events.Stats()
+}
+
 func (application *Application) SubscribeAction(resource string, action string, listener chan any) {
 	application.events.Subscribe(event.Action(resource, action), listener)
 }
@@ -45,10 +192,72 @@ func (application *Application) UnsubscribeAction(resource string, action string
 	application.events.Unsubscribe(event.Action(resource, action), listener)
 }
 
-func (application *Application) Start() {
-	application.storage.Start(application.events)
+func (application *Application) Start(ctx context.Context) error {
+	application.mutex.Lock()
+	defer application.mutex.Unlock()
+
+	if application.done != nil {
+		return storage.ErrRunning
+	}
+
+	if !application.seeded {
+		application.seedWith(rand.Uint64())
+	}
+
+	if err := application.storage.Start(ctx, application.events, application.clock, application.seed); err != nil {
+		return err
+	}
+
+	application.injector.Start()
+	application.done = make(chan struct{})
+
+	go application.watch(ctx, application.done)
+
+	return nil
+}
+
+func (application *Application) Stop(ctx context.Context) error {
+	application.mutex.Lock()
+	defer application.mutex.Unlock()
+
+	if application.done == nil {
+		return nil
+	}
+
+	close(application.done)
+	application.done = nil
+	application.injector.Stop()
+
+	return application.storage.Stop(ctx)
+}
+
+func (application *Application) Done() <-chan struct{} {
+	application.mutex.Lock()
+	defer application.mutex.Unlock()
+
+	if application.done == nil {
+		return closed
+	}
+
+	return application.done
+}
+
+func (application *Application) watch(ctx context.Context, done chan struct{}) {
+	select {
+	case <-ctx.Done():
+		application.Stop(context.WithoutCancel(ctx))
+	case <-done:
+	}
+}
+
+func (application *Application) Inject(fault fault.Fault) error {
+	return application.injector.Inject(fault)
+}
+
+func (application *Application) ClearFaults(resource string, kinds ...fault.Kind) {
+	application.injector.Clear(resource, kinds...)
 }
 
-func (application *Application) Stop() {
-	application.storage.Stop()
+func (application *Application) Faults() []fault.Fault {
+	return application.injector.Active()
 }
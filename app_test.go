@@ -0,0 +1,106 @@
+package immersim
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func run(t *testing.T, seed func(application *Application)) []any {
+	t.Helper()
+
+	manual := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	application := NewApplication(storage.NewStorage(map[string]storage.Resource{
+		"level":   resource.NewRandom[float32](0, 100, time.Second),
+		"count":   resource.NewRandom[int32](0, 1000, time.Second),
+		"enabled": resource.NewRandom[bool](false, true, time.Second),
+	}), event.NewEvents(time.Second), manual)
+
+	seed(application)
+
+	if err := application.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	defer application.Stop(context.Background())
+
+	var values []any
+
+	for i := 0; i < 10; i++ {
+		manual.Advance(time.Second)
+
+		for _, name := range []string{"level", "count", "enabled"} {
+			value, _ := application.Read(name)
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func TestSeedIsReproducible(t *testing.T) {
+	first := run(t, func(application *Application) { application.SetSeed(42) })
+	second := run(t, func(application *Application) { application.SetSeed(42) })
+
+	if !slices.Equal(first, second) {
+		t.Fatalf("expected identical runs, got %v and %v", first, second)
+	}
+
+	if other := run(t, func(application *Application) { application.SetSeed(43) }); slices.Equal(first, other) {
+		t.Fatal("expected a different seed to produce different values")
+	}
+}
+
+func TestSourceIsReproducible(t *testing.T) {
+	first := run(t, func(application *Application) { application.SetSource(rand.NewPCG(1, 2)) })
+	second := run(t, func(application *Application) { application.SetSource(rand.NewPCG(1, 2)) })
+
+	if !slices.Equal(first, second) {
+		t.Fatalf("expected identical runs, got %v and %v", first, second)
+	}
+}
+
+func TestStartKeepsSeed(t *testing.T) {
+	application := NewApplication(storage.NewStorage(nil), event.NewEvents(time.Second), clock.NewReal())
+	application.SetSeed(7)
+
+	if err := application.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	defer application.Stop(context.Background())
+
+	if seed := application.Seed(); seed != 7 {
+		t.Fatalf("expected seed 7, got %d", seed)
+	}
+}
+
+func TestSeedConcurrentAccess(t *testing.T) {
+	application := NewApplication(storage.NewStorage(nil), event.NewEvents(time.Second), clock.NewReal())
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func(seed uint64) {
+			defer wg.Done()
+			application.SetSeed(seed)
+		}(uint64(i))
+
+		go func() {
+			defer wg.Done()
+			application.Seed()
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,242 @@
+package clock
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	Every(interval time.Duration, callback func(now time.Time)) Ticker
+	AfterFunc(delay time.Duration, callback func(now time.Time)) Ticker
+}
+
+type Ticker interface {
+	Reset(interval time.Duration)
+	Stop()
+}
+
+type Real struct{}
+
+type Scaled struct {
+	factor float64
+	origin time.Time
+}
+
+type realTimer struct {
+	timer *time.Timer
+	scale func(delay time.Duration) time.Duration
+}
+
+type realTicker struct {
+	now      func() time.Time
+	scale    func(interval time.Duration) time.Duration
+	callback func(now time.Time)
+	mutex    sync.Mutex
+	quit     chan struct{}
+	calls    *inflight
+}
+
+type inflight struct {
+	mutex   sync.Mutex
+	idle    *sync.Cond
+	callers map[uint64]int
+}
+
+func NewReal() *Real {
+	return &Real{}
+}
+
+func (clock *Real) Now() time.Time {
+	return time.Now()
+}
+
+func (clock *Real) Every(interval time.Duration, callback func(now time.Time)) Ticker {
+	return newRealTicker(interval, clock.Now, func(interval time.Duration) time.Duration {
+		return interval
+	}, callback)
+}
+
+func (clock *Real) AfterFunc(delay time.Duration, callback func(now time.Time)) Ticker {
+	return newRealTimer(delay, clock.Now, func(delay time.Duration) time.Duration {
+		return delay
+	}, callback)
+}
+
+func NewScaled(factor float64) *Scaled {
+	return &Scaled{
+		factor: factor,
+		origin: time.Now(),
+	}
+}
+
+func (clock *Scaled) Now() time.Time {
+	elapsed := time.Since(clock.origin)
+
+	return clock.origin.Add(time.Duration(float64(elapsed) * clock.factor))
+}
+
+func (clock *Scaled) Every(interval time.Duration, callback func(now time.Time)) Ticker {
+	return newRealTicker(interval, clock.Now, func(interval time.Duration) time.Duration {
+		return max(time.Duration(float64(interval)/clock.factor), time.Microsecond)
+	}, callback)
+}
+
+func (clock *Scaled) AfterFunc(delay time.Duration, callback func(now time.Time)) Ticker {
+	return newRealTimer(delay, clock.Now, func(delay time.Duration) time.Duration {
+		return time.Duration(float64(delay) / clock.factor)
+	}, callback)
+}
+
+func newRealTimer(delay time.Duration, now func() time.Time, scale func(delay time.Duration) time.Duration, callback func(now time.Time)) *realTimer {
+	return &realTimer{
+		timer: time.AfterFunc(scale(delay), func() {
+			callback(now())
+		}),
+		scale: scale,
+	}
+}
+
+func (timer *realTimer) Reset(delay time.Duration) {
+	timer.timer.Reset(timer.scale(delay))
+}
+
+func (timer *realTimer) Stop() {
+	timer.timer.Stop()
+}
+
+func newRealTicker(interval time.Duration, now func() time.Time, scale func(interval time.Duration) time.Duration, callback func(now time.Time)) *realTicker {
+	ticker := &realTicker{
+		now:      now,
+		scale:    scale,
+		callback: callback,
+		mutex:    sync.Mutex{},
+		quit:     nil,
+		calls:    newInflight(),
+	}
+
+	ticker.Reset(interval)
+
+	return ticker
+}
+
+func (ticker *realTicker) Reset(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker.mutex.Lock()
+	ticker.stop()
+	ticker.quit = make(chan struct{})
+	go ticker.loop(ticker.scale(interval), ticker.quit)
+	ticker.mutex.Unlock()
+
+	ticker.calls.wait()
+}
+
+func (ticker *realTicker) Stop() {
+	ticker.mutex.Lock()
+	ticker.stop()
+	ticker.mutex.Unlock()
+
+	ticker.calls.wait()
+}
+
+func (ticker *realTicker) stop() {
+	if ticker.quit == nil {
+		return
+	}
+
+	close(ticker.quit)
+	ticker.quit = nil
+}
+
+func (ticker *realTicker) loop(interval time.Duration, quit chan struct{}) {
+	timer := time.NewTicker(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if !ticker.tick(quit) {
+				return
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (ticker *realTicker) tick(quit chan struct{}) bool {
+	ticker.mutex.Lock()
+
+	select {
+	case <-quit:
+		ticker.mutex.Unlock()
+		return false
+	default:
+	}
+
+	caller := ticker.calls.enter()
+	ticker.mutex.Unlock()
+
+	defer ticker.calls.leave(caller)
+	ticker.callback(ticker.now())
+
+	return true
+}
+
+func newInflight() *inflight {
+	calls := &inflight{
+		mutex:   sync.Mutex{},
+		idle:    nil,
+		callers: make(map[uint64]int),
+	}
+
+	calls.idle = sync.NewCond(&calls.mutex)
+
+	return calls
+}
+
+func (calls *inflight) enter() uint64 {
+	caller := goroutine()
+
+	calls.mutex.Lock()
+	calls.callers[caller]++
+	calls.mutex.Unlock()
+
+	return caller
+}
+
+func (calls *inflight) leave(caller uint64) {
+	calls.mutex.Lock()
+	defer calls.mutex.Unlock()
+
+	if calls.callers[caller]--; calls.callers[caller] == 0 {
+		delete(calls.callers, caller)
+	}
+
+	calls.idle.Broadcast()
+}
+
+func (calls *inflight) wait() {
+	caller := goroutine()
+
+	calls.mutex.Lock()
+	defer calls.mutex.Unlock()
+
+	for len(calls.callers) > 0 && !(len(calls.callers) == 1 && calls.callers[caller] > 0) {
+		calls.idle.Wait()
+	}
+}
+
+func goroutine() uint64 {
+	buffer := make([]byte, 64)
+	buffer = bytes.TrimPrefix(buffer[:runtime.Stack(buffer, false)], []byte("goroutine "))
+	id, _ := strconv.ParseUint(string(buffer[:bytes.IndexByte(buffer, ' ')]), 10, 64)
+
+	return id
+}
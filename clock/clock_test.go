@@ -0,0 +1,234 @@
+package clock
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRealEvery(t *testing.T) {
+	ticks := make(chan time.Time, 16)
+	ticker := NewReal().Every(time.Millisecond, func(now time.Time) {
+		select {
+		case ticks <- now:
+		default:
+		}
+	})
+
+	defer ticker.Stop()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ticks:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a tick")
+		}
+	}
+}
+
+func TestRealAfterFunc(t *testing.T) {
+	fired := make(chan struct{})
+
+	NewReal().AfterFunc(time.Millisecond, func(now time.Time) {
+		close(fired)
+	})
+
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the timer")
+	}
+}
+
+func TestRealStopHaltsTicks(t *testing.T) {
+	var fired atomic.Int32
+
+	ticker := NewReal().Every(time.Millisecond, func(now time.Time) {
+		fired.Add(1)
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	ticker.Stop()
+	stopped := fired.Load()
+	time.Sleep(10 * time.Millisecond)
+
+	if fired.Load() != stopped {
+		t.Fatalf("expected no ticks after stop, got %d more", fired.Load()-stopped)
+	}
+}
+
+func TestRealResetRejectsNonPositiveInterval(t *testing.T) {
+	ticks := make(chan struct{}, 16)
+	ticker := NewReal().Every(time.Millisecond, func(now time.Time) {
+		select {
+		case ticks <- struct{}{}:
+		default:
+		}
+	})
+
+	defer ticker.Stop()
+
+	ticker.Reset(0)
+	ticker.Reset(-time.Second)
+
+	select {
+	case <-ticks:
+	case <-time.After(time.Second):
+		t.Fatal("expected the ticker to keep running")
+	}
+
+	NewReal().Every(0, func(now time.Time) {
+		t.Error("expected a zero interval ticker never to fire")
+	}).Stop()
+}
+
+func TestRealStopFromCallback(t *testing.T) {
+	var fired atomic.Int32
+	var ticker Ticker
+
+	created := make(chan struct{})
+	done := make(chan struct{})
+	ticker = NewReal().Every(time.Millisecond, func(now time.Time) {
+		<-created
+
+		if fired.Add(1) == 1 {
+			ticker.Stop()
+			close(done)
+		}
+	})
+
+	close(created)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("deadlocked")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if fired.Load() != 1 {
+		t.Fatalf("expected a single tick, got %d", fired.Load())
+	}
+}
+
+func TestRealResetFromCallback(t *testing.T) {
+	var fired atomic.Int32
+	var ticker Ticker
+
+	created := make(chan struct{})
+	ticks := make(chan struct{}, 16)
+	ticker = NewReal().Every(time.Millisecond, func(now time.Time) {
+		<-created
+
+		if fired.Add(1) == 1 {
+			ticker.Reset(2 * time.Millisecond)
+		}
+
+		select {
+		case ticks <- struct{}{}:
+		default:
+		}
+	})
+
+	close(created)
+	defer ticker.Stop()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ticks:
+		case <-time.After(time.Second):
+			t.Fatal("expected the ticker to keep running after a reset")
+		}
+	}
+}
+
+func TestRealStopWaitsForCallback(t *testing.T) {
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	var finished atomic.Bool
+
+	ticker := NewReal().Every(time.Millisecond, func(now time.Time) {
+		select {
+		case entered <- struct{}{}:
+			<-release
+			finished.Store(true)
+		default:
+		}
+	})
+
+	<-entered
+
+	stopped := make(chan struct{})
+
+	go func() {
+		ticker.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("expected stop to wait for the running callback")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-stopped
+
+	if !finished.Load() {
+		t.Fatal("expected the callback to finish before stop returned")
+	}
+}
+
+func TestScaledNow(t *testing.T) {
+	clock := NewScaled(100)
+	start := clock.Now()
+	time.Sleep(20 * time.Millisecond)
+
+	if elapsed := clock.Now().Sub(start); elapsed < time.Second {
+		t.Fatalf("expected at least a scaled second, got %v", elapsed)
+	}
+}
+
+func TestScaledEvery(t *testing.T) {
+	clock := NewScaled(1000)
+	ticks := make(chan time.Time, 16)
+	ticker := clock.Every(time.Second, func(now time.Time) {
+		select {
+		case ticks <- now:
+		default:
+		}
+	})
+
+	defer ticker.Stop()
+
+	var previous time.Time
+
+	for i := 0; i < 3; i++ {
+		select {
+		case now := <-ticks:
+			if !previous.IsZero() && now.Sub(previous) < 500*time.Millisecond {
+				t.Fatalf("expected scaled ticks about a second apart, got %v", now.Sub(previous))
+			}
+
+			previous = now
+		case <-time.After(time.Second):
+			t.Fatal("expected a scaled second to pass within a real second")
+		}
+	}
+}
+
+func TestScaledAfterFunc(t *testing.T) {
+	fired := make(chan struct{})
+
+	NewScaled(1000).AfterFunc(time.Second, func(now time.Time) {
+		close(fired)
+	})
+
+	select {
+	case <-fired:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected the scaled delay to elapse early")
+	}
+}
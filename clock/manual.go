@@ -0,0 +1,190 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+type Manual struct {
+	mutex    sync.Mutex
+	now      time.Time
+	tickers  []*manualTicker
+	sequence uint64
+}
+
+type manualTicker struct {
+	clock    *Manual
+	interval time.Duration
+	next     time.Time
+	order    uint64
+	once     bool
+	callback func(now time.Time)
+	calls    *inflight
+}
+
+func NewManual(start time.Time) *Manual {
+	return &Manual{
+		mutex:    sync.Mutex{},
+		now:      start,
+		tickers:  nil,
+		sequence: 0,
+	}
+}
+
+func (clock *Manual) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	return clock.now
+}
+
+func (clock *Manual) Every(interval time.Duration, callback func(now time.Time)) Ticker {
+	ticker := &manualTicker{
+		clock:    clock,
+		interval: interval,
+		next:     time.Time{},
+		order:    0,
+		once:     false,
+		callback: callback,
+		calls:    newInflight(),
+	}
+
+	ticker.Reset(interval)
+
+	return ticker
+}
+
+func (clock *Manual) AfterFunc(delay time.Duration, callback func(now time.Time)) Ticker {
+	ticker := &manualTicker{
+		clock:    clock,
+		interval: 0,
+		next:     time.Time{},
+		order:    0,
+		once:     true,
+		callback: callback,
+		calls:    newInflight(),
+	}
+
+	ticker.Reset(delay)
+
+	return ticker
+}
+
+func (clock *Manual) Advance(duration time.Duration) {
+	clock.mutex.Lock()
+	target := clock.now.Add(duration)
+	clock.mutex.Unlock()
+
+	for clock.fire(target) {
+	}
+
+	clock.mutex.Lock()
+
+	if target.After(clock.now) {
+		clock.now = target
+	}
+
+	clock.mutex.Unlock()
+}
+
+func (clock *Manual) Step() bool {
+	clock.mutex.Lock()
+
+	if len(clock.tickers) == 0 {
+		clock.mutex.Unlock()
+		return false
+	}
+
+	target := clock.earliest().next
+	clock.mutex.Unlock()
+
+	for clock.fire(target) {
+	}
+
+	return true
+}
+
+func (clock *Manual) earliest() *manualTicker {
+	return slices.MinFunc(clock.tickers, func(a *manualTicker, b *manualTicker) int {
+		if compared := a.next.Compare(b.next); compared != 0 {
+			return compared
+		}
+
+		if a.order < b.order {
+			return -1
+		}
+
+		return 1
+	})
+}
+
+func (clock *Manual) fire(target time.Time) bool {
+	clock.mutex.Lock()
+
+	if len(clock.tickers) == 0 {
+		clock.mutex.Unlock()
+		return false
+	}
+
+	ticker := clock.earliest()
+
+	if ticker.next.After(target) {
+		clock.mutex.Unlock()
+		return false
+	}
+
+	clock.now = ticker.next
+	ticker.next = ticker.next.Add(ticker.interval)
+
+	if ticker.once {
+		clock.remove(ticker)
+	}
+
+	now := clock.now
+	callback := ticker.callback
+	caller := ticker.calls.enter()
+	clock.mutex.Unlock()
+
+	defer ticker.calls.leave(caller)
+	callback(now)
+
+	return true
+}
+
+func (ticker *manualTicker) Reset(interval time.Duration) {
+	if interval <= 0 && !ticker.once {
+		return
+	}
+
+	interval = max(interval, 0)
+
+	clock := ticker.clock
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	clock.sequence++
+
+	ticker.interval = interval
+	ticker.next = clock.now.Add(interval)
+	ticker.order = clock.sequence
+
+	if !slices.Contains(clock.tickers, ticker) {
+		clock.tickers = append(clock.tickers, ticker)
+	}
+}
+
+func (ticker *manualTicker) Stop() {
+	clock := ticker.clock
+	clock.mutex.Lock()
+	clock.remove(ticker)
+	clock.mutex.Unlock()
+
+	ticker.calls.wait()
+}
+
+func (clock *Manual) remove(ticker *manualTicker) {
+	clock.tickers = slices.DeleteFunc(clock.tickers, func(candidate *manualTicker) bool {
+		return candidate == ticker
+	})
+}
@@ -0,0 +1,205 @@
+package clock
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestManualAdvance(t *testing.T) {
+	clock := NewManual(epoch)
+
+	var fired []string
+
+	clock.Every(10*time.Millisecond, func(now time.Time) {
+		fired = append(fired, "every "+now.Sub(epoch).String())
+	})
+
+	clock.AfterFunc(25*time.Millisecond, func(now time.Time) {
+		fired = append(fired, "after "+now.Sub(epoch).String())
+	})
+
+	clock.Advance(30 * time.Millisecond)
+
+	expected := []string{"every 10ms", "every 20ms", "after 25ms", "every 30ms"}
+
+	if !slices.Equal(fired, expected) {
+		t.Fatalf("expected %v, got %v", expected, fired)
+	}
+
+	if now := clock.Now(); !now.Equal(epoch.Add(30 * time.Millisecond)) {
+		t.Fatalf("expected the clock to reach the target, got %v", now)
+	}
+}
+
+func TestManualStep(t *testing.T) {
+	clock := NewManual(epoch)
+
+	if clock.Step() {
+		t.Fatal("expected no step without tickers")
+	}
+
+	var fired []time.Duration
+
+	ticker := clock.Every(time.Second, func(now time.Time) {
+		fired = append(fired, now.Sub(epoch))
+	})
+
+	clock.Step()
+	clock.Step()
+
+	if !slices.Equal(fired, []time.Duration{time.Second, 2 * time.Second}) {
+		t.Fatalf("unexpected ticks %v", fired)
+	}
+
+	ticker.Stop()
+
+	if clock.Step() {
+		t.Fatal("expected no step after stop")
+	}
+}
+
+func TestManualAfterFuncFiresOnce(t *testing.T) {
+	clock := NewManual(epoch)
+	fired := 0
+
+	clock.AfterFunc(0, func(now time.Time) {
+		fired++
+	})
+
+	clock.Advance(time.Hour)
+
+	if fired != 1 {
+		t.Fatalf("expected a single call, got %d", fired)
+	}
+}
+
+func TestManualResetRejectsNonPositiveInterval(t *testing.T) {
+	clock := NewManual(epoch)
+	fired := 0
+
+	ticker := clock.Every(time.Second, func(now time.Time) {
+		fired++
+	})
+
+	ticker.Reset(0)
+	ticker.Reset(-time.Second)
+	clock.Advance(3 * time.Second)
+
+	if fired != 3 {
+		t.Fatalf("expected the original interval to be kept, got %d ticks", fired)
+	}
+
+	clock.Every(0, func(now time.Time) {
+		t.Fatal("expected a zero interval ticker never to fire")
+	})
+
+	clock.Advance(time.Second)
+}
+
+func TestManualNegativeDelayKeepsTime(t *testing.T) {
+	clock := NewManual(epoch)
+
+	clock.AfterFunc(-time.Second, func(now time.Time) {
+		if now.Before(epoch) {
+			t.Fatalf("expected time not to go backwards, got %v", now)
+		}
+	})
+
+	clock.Step()
+}
+
+func TestManualStopFromCallback(t *testing.T) {
+	clock := NewManual(epoch)
+	fired := 0
+
+	var ticker Ticker
+
+	ticker = clock.Every(time.Second, func(now time.Time) {
+		fired++
+		ticker.Stop()
+	})
+
+	within(t, func() {
+		clock.Advance(5 * time.Second)
+	})
+
+	if fired != 1 {
+		t.Fatalf("expected the ticker to stop itself, got %d ticks", fired)
+	}
+}
+
+func TestManualResetFromCallback(t *testing.T) {
+	clock := NewManual(epoch)
+
+	var fired []time.Duration
+	var ticker Ticker
+
+	ticker = clock.Every(time.Second, func(now time.Time) {
+		fired = append(fired, now.Sub(epoch))
+		ticker.Reset(2 * time.Second)
+	})
+
+	within(t, func() {
+		clock.Advance(5 * time.Second)
+	})
+
+	if !slices.Equal(fired, []time.Duration{time.Second, 3 * time.Second, 5 * time.Second}) {
+		t.Fatalf("unexpected ticks %v", fired)
+	}
+}
+
+func TestManualStopWaitsForCallback(t *testing.T) {
+	clock := NewManual(epoch)
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	finished := false
+
+	ticker := clock.Every(time.Second, func(now time.Time) {
+		close(entered)
+		<-release
+		finished = true
+	})
+
+	go clock.Advance(time.Second)
+	<-entered
+
+	stopped := make(chan struct{})
+
+	go func() {
+		ticker.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("expected stop to wait for the running callback")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-stopped
+
+	if !finished {
+		t.Fatal("expected the callback to finish before stop returned")
+	}
+}
+
+func within(t *testing.T, run func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+
+	go func() {
+		run()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("deadlocked")
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Error struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+var (
+	ErrSyntax            = errors.New("invalid syntax")
+	ErrInvalidDocument   = errors.New("invalid document")
+	ErrUnknownKind       = errors.New("unknown resource kind")
+	ErrUnknownType       = errors.New("unknown resource type")
+	ErrUnknownField      = errors.New("unknown field")
+	ErrMissingField      = errors.New("missing field")
+	ErrInvalidValue      = errors.New("invalid value")
+	ErrDuplicateResource = errors.New("duplicate resource")
+	ErrDanglingReference = errors.New("reference to unknown resource")
+	ErrUnknownFunction   = errors.New("unknown function")
+	ErrInvalidExpression = errors.New("invalid expression")
+	ErrUnknownTemplate   = errors.New("unknown template")
+)
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", err.Line, err.Column, err.Err)
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
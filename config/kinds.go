@@ -0,0 +1,637 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+	"gopkg.in/yaml.v3"
+)
+
+type kind struct {
+	fields      []string
+	defaultType string
+	build       func(loader *Loader, definition *definition) storage.Resource
+}
+
+var common = []string{"description", "unit", "format", "range", "raw", "access"}
+
+var kinds = map[string]kind{
+	"constant": {
+		fields: []string{"type", "value"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32", "bool"}, buildConstant[int32], buildConstant[float32], buildConstant[bool])
+		},
+	},
+	"static": {
+		fields: []string{"type", "value"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32", "bool"}, buildStatic[int32], buildStatic[float32], buildStatic[bool])
+		},
+	},
+	"sine_wave": {
+		fields:      []string{"type", "frequency", "amplitude", "offset", "phase", "interval"},
+		defaultType: "float32",
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			if !checkType(definition, []string{"int32", "float32"}, "float32") {
+				return nil
+			}
+
+			if optional(definition, "type", "float32") == "int32" {
+				return buildSine[int32](definition)
+			}
+
+			return buildSine[float32](definition)
+		},
+	},
+	"square": {
+		fields: []string{"type", "frequency", "amplitude", "offset", "phase", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32", "bool"}, buildSquare[int32], buildSquare[float32], buildSquare[bool])
+		},
+	},
+	"triangle": {
+		fields: []string{"type", "frequency", "amplitude", "offset", "phase", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildTriangle[int32], buildTriangle[float32], nil)
+		},
+	},
+	"sawtooth": {
+		fields: []string{"type", "frequency", "amplitude", "offset", "phase", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildSawtooth[int32], buildSawtooth[float32], nil)
+		},
+	},
+	"pulse": {
+		fields: []string{"type", "frequency", "duty", "amplitude", "offset", "phase", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32", "bool"}, buildPulse[int32], buildPulse[float32], buildPulse[bool])
+		},
+	},
+	"chirp": {
+		fields: []string{"type", "start_frequency", "end_frequency", "sweep", "amplitude", "offset", "phase", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildChirp[int32], buildChirp[float32], nil)
+		},
+	},
+	"random": {
+		fields: []string{"type", "min", "max", "interval", "seed"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32", "bool"}, buildRandom[int32], buildRandom[float32], buildRandom[bool])
+		},
+	},
+	"increment": {
+		fields: []string{"type", "initial", "step", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildIncrement[int32], buildIncrement[float32], nil)
+		},
+	},
+	"linear_feedback": {
+		fields: []string{"type", "step", "interval", "setpoint"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildLinearFeedback[int32], buildLinearFeedback[float32], nil)
+		},
+	},
+	"pid": {
+		fields: []string{"type", "process_variable", "setpoint", "kp", "ki", "kd", "interval", "min", "max"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildPID[int32], buildPID[float32], nil)
+		},
+	},
+	"first_order": {
+		fields: []string{"type", "input", "gain", "time_constant", "dead_time", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildFirstOrder[int32], buildFirstOrder[float32], nil)
+		},
+	},
+	"second_order": {
+		fields: []string{"type", "input", "gain", "natural_frequency_hz", "damping", "dead_time", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildSecondOrder[int32], buildSecondOrder[float32], nil)
+		},
+	},
+	"white_noise": {
+		fields: []string{"type", "source", "deviation", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildWhiteNoise[int32], buildWhiteNoise[float32], nil)
+		},
+	},
+	"pink_noise": {
+		fields: []string{"type", "source", "amplitude", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildPinkNoise[int32], buildPinkNoise[float32], nil)
+		},
+	},
+	"brown_noise": {
+		fields: []string{"type", "source", "amplitude", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildBrownNoise[int32], buildBrownNoise[float32], nil)
+		},
+	},
+	"linear_drift": {
+		fields: []string{"type", "source", "rate", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildLinearDrift[int32], buildLinearDrift[float32], nil)
+		},
+	},
+	"random_walk": {
+		fields: []string{"type", "source", "deviation", "interval"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildRandomWalk[int32], buildRandomWalk[float32], nil)
+		},
+	},
+	"quantize": {
+		fields: []string{"type", "source", "bits", "min", "max"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildQuantizer[int32], buildQuantizer[float32], nil)
+		},
+	},
+	"clamp": {
+		fields: []string{"type", "source", "min", "max"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildClamp[int32], buildClamp[float32], nil)
+		},
+	},
+	"deadband": {
+		fields: []string{"type", "source", "band"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32"}, buildDeadband[int32], buildDeadband[float32], nil)
+		},
+	},
+	"computed": {
+		fields: []string{"type", "function", "dependencies", "expression"},
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			return typed(definition, []string{"int32", "float32", "bool"}, buildComputed[int32](loader), buildComputed[float32](loader), buildComputed[bool](loader))
+		},
+	},
+	"action": {
+		fields:      []string{"type", "function"},
+		defaultType: "bool",
+		build: func(loader *Loader, definition *definition) storage.Resource {
+			if !checkType(definition, []string{"bool"}, "bool") {
+				return nil
+			}
+
+			name := field[string](definition, "function")
+			callback, ok := loader.actions[name]
+
+			if !ok {
+				if node, exists := definition.fields["function"]; exists {
+					definition.fail(node, fmt.Errorf("%w: %s", ErrUnknownFunction, name))
+				}
+
+				return nil
+			}
+
+			return resource.NewAction(callback)
+		},
+	},
+}
+
+func (kind kind) allows(field string) bool {
+	return slices.Contains(kind.fields, field) || slices.Contains(common, field)
+}
+
+func buildMetadata(definition *definition) *storage.Metadata {
+	described := false
+
+	for _, name := range common {
+		if _, ok := definition.fields[name]; ok {
+			described = true
+		}
+	}
+
+	if !described {
+		return nil
+	}
+
+	metadata := &storage.Metadata{
+		Description: optional(definition, "description", ""),
+		Unit:        optional(definition, "unit", ""),
+		Format:      optional(definition, "format", ""),
+		Range:       buildRange(definition, "range"),
+		Raw:         buildRange(definition, "raw"),
+		Access:      storage.AccessDefault,
+	}
+
+	if node, ok := definition.fields["access"]; ok {
+		access, err := storage.ParseAccess(node.Value)
+
+		if err != nil {
+			definition.fail(node, fmt.Errorf("%w: %w", ErrInvalidValue, err))
+		}
+
+		metadata.Access = access
+	}
+
+	if metadata.Raw != nil && metadata.Range == nil {
+		definition.fail(definition.fields["raw"], fmt.Errorf("%w: raw requires range", ErrMissingField))
+	}
+
+	return metadata
+}
+
+func buildRange(definition *definition, name string) *storage.Range {
+	node, ok := definition.fields[name]
+
+	if !ok {
+		return nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		definition.fail(node, fmt.Errorf("%w: %s must be a mapping with min and max", ErrInvalidValue, name))
+		return nil
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i]; key.Value != "min" && key.Value != "max" {
+			definition.fail(key, fmt.Errorf("%w: %s for %s", ErrUnknownField, key.Value, name))
+		}
+	}
+
+	value := decode[storage.Range](definition, name, node)
+
+	if value.Min >= value.Max {
+		definition.fail(node, fmt.Errorf("%w: %s min must be lower than max", ErrInvalidValue, name))
+	}
+
+	return &value
+}
+
+func checkType(definition *definition, allowed []string, fallback string) bool {
+	name := optional(definition, "type", fallback)
+
+	if !slices.Contains(allowed, name) {
+		definition.fail(definition.fields["type"], fmt.Errorf("%w: %s for kind %s", ErrUnknownType, name, definition.kind))
+		return false
+	}
+
+	return true
+}
+
+func typed(
+	definition *definition,
+	allowed []string,
+	int32Builder func(definition *definition) storage.Resource,
+	float32Builder func(definition *definition) storage.Resource,
+	boolBuilder func(definition *definition) storage.Resource,
+) storage.Resource {
+	node, ok := definition.fields["type"]
+
+	if !ok {
+		definition.fail(definition.node, fmt.Errorf("%w: type", ErrMissingField))
+		return nil
+	}
+
+	if !slices.Contains(allowed, node.Value) {
+		definition.fail(node, fmt.Errorf("%w: %s for kind %s", ErrUnknownType, node.Value, definition.kind))
+		return nil
+	}
+
+	switch node.Value {
+	case "int32":
+		return int32Builder(definition)
+	case "float32":
+		return float32Builder(definition)
+	}
+
+	return boolBuilder(definition)
+}
+
+func field[T any](definition *definition, name string) T {
+	node, ok := definition.fields[name]
+
+	if !ok {
+		definition.fail(definition.node, fmt.Errorf("%w: %s", ErrMissingField, name))
+		return *new(T)
+	}
+
+	return decode[T](definition, name, node)
+}
+
+func optional[T any](definition *definition, name string, fallback T) T {
+	node, ok := definition.fields[name]
+
+	if !ok {
+		return fallback
+	}
+
+	return decode[T](definition, name, node)
+}
+
+func decode[T any](definition *definition, name string, node *yaml.Node) T {
+	var value T
+
+	if duration, ok := any(&value).(*time.Duration); ok {
+		parsed, err := time.ParseDuration(node.Value)
+
+		if err != nil || node.Kind != yaml.ScalarNode || parsed <= 0 {
+			definition.fail(node, fmt.Errorf("%w: %s must be a positive duration such as 100ms", ErrInvalidValue, name))
+			return value
+		}
+
+		*duration = parsed
+
+		return value
+	}
+
+	if _, ok := any(value).(int32); ok && node.ShortTag() != "!!int" {
+		definition.fail(node, fmt.Errorf("%w: %s must be of type %T", ErrInvalidValue, name, value))
+		return value
+	}
+
+	if err := node.Decode(&value); err != nil {
+		definition.fail(node, fmt.Errorf("%w: %s must be of type %T", ErrInvalidValue, name, value))
+	}
+
+	return value
+}
+
+func reference(definition *definition, name string) string {
+	value := field[string](definition, name)
+
+	if node, ok := definition.fields[name]; ok {
+		definition.references = append(definition.references, node)
+	}
+
+	return value
+}
+
+func references(definition *definition, name string) []string {
+	node, ok := definition.fields[name]
+
+	if !ok {
+		return nil
+	}
+
+	values := decode[[]string](definition, name, node)
+
+	if node.Kind == yaml.SequenceNode {
+		definition.references = append(definition.references, node.Content...)
+	}
+
+	return values
+}
+
+func buildConstant[T storage.Supported](definition *definition) storage.Resource {
+	return resource.NewConstant(field[T](definition, "value"))
+}
+
+func buildStatic[T storage.Supported](definition *definition) storage.Resource {
+	return resource.NewStatic(field[T](definition, "value"))
+}
+
+func buildSine[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewSine[T](
+		field[float64](definition, "frequency"),
+		field[float64](definition, "amplitude"),
+		optional(definition, "offset", float64(0)),
+		optional(definition, "phase", float64(0)),
+		field[time.Duration](definition, "interval"),
+	)
+}
+
+func buildSquare[T storage.Supported](definition *definition) storage.Resource {
+	return resource.NewSquare[T](
+		field[float64](definition, "frequency"),
+		optional(definition, "amplitude", float64(1)),
+		optional(definition, "offset", float64(0)),
+		optional(definition, "phase", float64(0)),
+		field[time.Duration](definition, "interval"),
+	)
+}
+
+func buildTriangle[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewTriangle[T](
+		field[float64](definition, "frequency"),
+		optional(definition, "amplitude", float64(1)),
+		optional(definition, "offset", float64(0)),
+		optional(definition, "phase", float64(0)),
+		field[time.Duration](definition, "interval"),
+	)
+}
+
+func buildSawtooth[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewSawtooth[T](
+		field[float64](definition, "frequency"),
+		optional(definition, "amplitude", float64(1)),
+		optional(definition, "offset", float64(0)),
+		optional(definition, "phase", float64(0)),
+		field[time.Duration](definition, "interval"),
+	)
+}
+
+func buildPulse[T storage.Supported](definition *definition) storage.Resource {
+	return resource.NewPulse[T](
+		field[float64](definition, "frequency"),
+		optional(definition, "duty", float64(0.5)),
+		optional(definition, "amplitude", float64(1)),
+		optional(definition, "offset", float64(0)),
+		optional(definition, "phase", float64(0)),
+		field[time.Duration](definition, "interval"),
+	)
+}
+
+func buildChirp[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewChirp[T](
+		field[float64](definition, "start_frequency"),
+		field[float64](definition, "end_frequency"),
+		field[time.Duration](definition, "sweep"),
+		optional(definition, "amplitude", float64(1)),
+		optional(definition, "offset", float64(0)),
+		optional(definition, "phase", float64(0)),
+		field[time.Duration](definition, "interval"),
+	)
+}
+
+func buildRandom[T storage.Supported](definition *definition) storage.Resource {
+	var zero T
+
+	min, max := zero, zero
+
+	if _, ok := any(zero).(bool); !ok {
+		min = field[T](definition, "min")
+		max = field[T](definition, "max")
+	}
+
+	interval := field[time.Duration](definition, "interval")
+
+	if _, ok := definition.fields["seed"]; ok {
+		return resource.NewSeededRandom(min, max, interval, field[uint64](definition, "seed"))
+	}
+
+	return resource.NewRandom(min, max, interval)
+}
+
+func buildPID[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewPID(
+		reference(definition, "process_variable"),
+		reference(definition, "setpoint"),
+		field[float64](definition, "kp"),
+		optional(definition, "ki", float64(0)),
+		optional(definition, "kd", float64(0)),
+		field[time.Duration](definition, "interval"),
+		field[T](definition, "min"),
+		field[T](definition, "max"),
+	)
+}
+
+func buildFirstOrder[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewFirstOrder[T](
+		reference(definition, "input"),
+		optional(definition, "gain", float64(1)),
+		field[time.Duration](definition, "time_constant"),
+		optional(definition, "dead_time", time.Duration(0)),
+		field[time.Duration](definition, "interval"),
+	)
+}
+
+func buildSecondOrder[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewSecondOrder[T](
+		reference(definition, "input"),
+		optional(definition, "gain", float64(1)),
+		field[float64](definition, "natural_frequency_hz"),
+		field[float64](definition, "damping"),
+		optional(definition, "dead_time", time.Duration(0)),
+		field[time.Duration](definition, "interval"),
+	)
+}
+
+func buildWhiteNoise[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewWhiteNoise[T](
+		reference(definition, "source"),
+		field[float64](definition, "deviation"),
+		optional(definition, "interval", time.Duration(0)),
+	)
+}
+
+func buildPinkNoise[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewPinkNoise[T](
+		reference(definition, "source"),
+		field[float64](definition, "amplitude"),
+		optional(definition, "interval", time.Duration(0)),
+	)
+}
+
+func buildBrownNoise[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewBrownNoise[T](
+		reference(definition, "source"),
+		field[float64](definition, "amplitude"),
+		optional(definition, "interval", time.Duration(0)),
+	)
+}
+
+func buildLinearDrift[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewLinearDrift[T](
+		reference(definition, "source"),
+		field[float64](definition, "rate"),
+		optional(definition, "interval", time.Duration(0)),
+	)
+}
+
+func buildRandomWalk[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewRandomWalk[T](
+		reference(definition, "source"),
+		field[float64](definition, "deviation"),
+		optional(definition, "interval", time.Duration(0)),
+	)
+}
+
+func buildQuantizer[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	source := reference(definition, "source")
+	bits := field[int](definition, "bits")
+	minimum := field[float64](definition, "min")
+	maximum := field[float64](definition, "max")
+
+	if node, ok := definition.fields["bits"]; ok && (bits < 1 || bits > 32) {
+		definition.fail(node, fmt.Errorf("%w: bits must be between 1 and 32", ErrInvalidValue))
+	}
+
+	if node, ok := definition.fields["min"]; ok && !(minimum < maximum) {
+		definition.fail(node, fmt.Errorf("%w: min must be lower than max", ErrInvalidValue))
+	}
+
+	return resource.NewQuantizer[T](source, bits, minimum, maximum)
+}
+
+func buildClamp[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewClamp[T](
+		reference(definition, "source"),
+		field[float64](definition, "min"),
+		field[float64](definition, "max"),
+	)
+}
+
+func buildDeadband[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewDeadband[T](
+		reference(definition, "source"),
+		field[float64](definition, "band"),
+	)
+}
+
+func buildIncrement[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewIncrement(
+		optional(definition, "initial", *new(T)),
+		field[T](definition, "step"),
+		field[time.Duration](definition, "interval"),
+	)
+}
+
+func buildLinearFeedback[T storage.SupportedNumeric](definition *definition) storage.Resource {
+	return resource.NewLinearFeedback(
+		field[T](definition, "step"),
+		field[time.Duration](definition, "interval"),
+		reference(definition, "setpoint"),
+	)
+}
+
+func buildComputed[T storage.Supported](loader *Loader) func(definition *definition) storage.Resource {
+	return func(definition *definition) storage.Resource {
+		if node, ok := definition.fields["expression"]; ok {
+			return buildExpression[T](definition, node)
+		}
+
+		name := field[string](definition, "function")
+		dependencies := references(definition, "dependencies")
+		registered, ok := loader.computed[name]
+
+		if !ok {
+			if node, exists := definition.fields["function"]; exists {
+				definition.fail(node, fmt.Errorf("%w: %s", ErrUnknownFunction, name))
+			}
+
+			return nil
+		}
+
+		callback, ok := registered.(func(name string, storage *storage.Storage) T)
+
+		if !ok {
+			definition.fail(definition.fields["function"], fmt.Errorf("%w: function %s does not return %T", ErrUnknownType, name, *new(T)))
+			return nil
+		}
+
+		return resource.NewComputed(callback, dependencies)
+	}
+}
+
+func buildExpression[T storage.Supported](definition *definition, node *yaml.Node) storage.Resource {
+	for _, conflicting := range []string{"function", "dependencies"} {
+		if other, ok := definition.fields[conflicting]; ok {
+			definition.fail(other, fmt.Errorf("%w: %s cannot be combined with expression", ErrInvalidValue, conflicting))
+		}
+	}
+
+	computed, err := resource.NewExpression[T](decode[string](definition, "expression", node))
+
+	if err != nil {
+		definition.fail(node, fmt.Errorf("%w: %w", ErrInvalidExpression, err))
+		return nil
+	}
+
+	definition.expression = computed.Expression()
+	definition.expressionNode = node
+
+	return computed
+}
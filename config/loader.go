@@ -0,0 +1,337 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/expression"
+	"github.com/studiolambda/immersim/storage"
+	"gopkg.in/yaml.v3"
+)
+
+type Loader struct {
+	computed  map[string]any
+	actions   map[string]func(storage *storage.Storage, events *event.Events) bool
+	templates map[string]*storage.Template
+}
+
+type definition struct {
+	name           string
+	kind           string
+	kindNode       *yaml.Node
+	node           *yaml.Node
+	fields         map[string]*yaml.Node
+	references     []*yaml.Node
+	expression     *expression.Expression
+	expressionNode *yaml.Node
+	metadata       *storage.Metadata
+	rewrite        func(reference string) string
+	errors         []error
+}
+
+func NewLoader() *Loader {
+	return &Loader{
+		computed:  make(map[string]any),
+		actions:   make(map[string]func(storage *storage.Storage, events *event.Events) bool),
+		templates: make(map[string]*storage.Template),
+	}
+}
+
+func RegisterComputed[T storage.Supported](loader *Loader, name string, callback func(name string, storage *storage.Storage) T) {
+	loader.computed[name] = callback
+}
+
+func (loader *Loader) RegisterAction(name string, callback func(storage *storage.Storage, events *event.Events) bool) {
+	loader.actions[name] = callback
+}
+
+func (loader *Loader) LoadFile(path string) (*storage.Storage, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := loader.Load(data)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return result, nil
+}
+
+func (loader *Loader) Load(data []byte) (*storage.Storage, error) {
+	var document yaml.Node
+
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSyntax, err)
+	}
+
+	if len(document.Content) == 0 {
+		return nil, &Error{Line: 1, Column: 1, Err: fmt.Errorf("%w: empty document", ErrInvalidDocument)}
+	}
+
+	root := document.Content[0]
+
+	if root.Kind != yaml.MappingNode {
+		return nil, positioned(root, fmt.Errorf("%w: expected a mapping", ErrInvalidDocument))
+	}
+
+	var resources, templates, instances *yaml.Node
+	var errs []error
+
+	propagation := storage.PropagateAsync
+
+	for i := 0; i < len(root.Content); i += 2 {
+		switch root.Content[i].Value {
+		case "resources":
+			resources = root.Content[i+1]
+		case "templates":
+			templates = root.Content[i+1]
+		case "instances":
+			instances = root.Content[i+1]
+		case "propagation":
+			switch node := root.Content[i+1]; node.Value {
+			case "async":
+				propagation = storage.PropagateAsync
+			case "topological":
+				propagation = storage.PropagateTopological
+			default:
+				errs = append(errs, positioned(node, fmt.Errorf("%w: propagation must be async or topological", ErrInvalidValue)))
+			}
+		default:
+			errs = append(errs, positioned(root.Content[i], fmt.Errorf("%w: %s", ErrUnknownField, root.Content[i].Value)))
+		}
+	}
+
+	if resources == nil {
+		return nil, errors.Join(append(errs, positioned(root, fmt.Errorf("%w: resources", ErrMissingField)))...)
+	}
+
+	if resources.Kind != yaml.MappingNode {
+		return nil, errors.Join(append(errs, positioned(resources, fmt.Errorf("%w: resources must be a mapping", ErrInvalidDocument)))...)
+	}
+
+	definitions := make([]*definition, 0, len(resources.Content)/2)
+	names := make(map[string]bool)
+
+	for i := 0; i < len(resources.Content); i += 2 {
+		key, value := resources.Content[i], resources.Content[i+1]
+
+		if names[key.Value] {
+			errs = append(errs, positioned(key, fmt.Errorf("%w: %s", ErrDuplicateResource, key.Value)))
+			continue
+		}
+
+		if err := storage.CheckName(key.Value); err != nil {
+			errs = append(errs, positioned(key, err))
+		}
+
+		names[key.Value] = true
+		definitions = append(definitions, newDefinition(key.Value, value))
+	}
+
+	parsed, templateErrs := parseTemplates(templates)
+	errs = append(errs, templateErrs...)
+	expanded, mounted, instanceErrs := loader.expand(instances, parsed, names)
+	errs = append(errs, instanceErrs...)
+	definitions = append(definitions, expanded...)
+
+	memory := make(map[string]storage.Resource, len(definitions))
+	types := make(map[string]expression.Type, len(definitions))
+
+	for _, instance := range mounted {
+		instance.types(types)
+	}
+
+	for _, definition := range definitions {
+		if resource := loader.build(definition); resource != nil {
+			if rewritable, ok := resource.(storage.Rewritable); ok && definition.rewrite != nil {
+				rewritable.Rewrite(definition.rewrite)
+			}
+
+			memory[definition.name] = resource
+		}
+
+		for _, reference := range definition.references {
+			if !names[definition.resolve(reference.Value)] {
+				definition.fail(reference, fmt.Errorf("%w: %s", ErrDanglingReference, reference.Value))
+			}
+		}
+
+		types[definition.name] = definition.valueType()
+	}
+
+	for _, definition := range definitions {
+		if definition.expression != nil {
+			checkExpression(definition, names, types)
+		}
+
+		errs = append(errs, definition.errors...)
+	}
+
+	if len(errs) > 0 {
+		slices.SortStableFunc(errs, func(a error, b error) int {
+			var first, second *Error
+
+			errors.As(a, &first)
+			errors.As(b, &second)
+
+			return cmp.Or(cmp.Compare(first.Line, second.Line), cmp.Compare(first.Column, second.Column))
+		})
+
+		return nil, errors.Join(errs...)
+	}
+
+	result := storage.NewStorage(memory)
+	result.SetPropagation(propagation)
+
+	for _, definition := range definitions {
+		if definition.metadata != nil {
+			result.Annotate(definition.name, *definition.metadata)
+		}
+	}
+
+	for _, instance := range mounted {
+		if err := result.Mount(context.Background(), instance.prefix, instance.storage); err != nil {
+			return nil, positioned(instance.node, err)
+		}
+	}
+
+	return result, nil
+}
+
+func (loader *Loader) build(definition *definition) storage.Resource {
+	if definition.node.Kind != yaml.MappingNode {
+		definition.fail(definition.node, fmt.Errorf("%w: resource %s must be a mapping", ErrInvalidDocument, definition.name))
+		return nil
+	}
+
+	if definition.kindNode == nil {
+		definition.fail(definition.node, fmt.Errorf("%w: kind", ErrMissingField))
+		return nil
+	}
+
+	kind, ok := kinds[definition.kind]
+
+	if !ok {
+		definition.fail(definition.kindNode, fmt.Errorf("%w: %s", ErrUnknownKind, definition.kind))
+		return nil
+	}
+
+	for field, node := range definition.fields {
+		if !kind.allows(field) {
+			definition.fail(node, fmt.Errorf("%w: %s for kind %s", ErrUnknownField, field, definition.kind))
+		}
+	}
+
+	definition.metadata = buildMetadata(definition)
+
+	return kind.build(loader, definition)
+}
+
+func newDefinition(name string, node *yaml.Node) *definition {
+	definition := &definition{
+		name:           name,
+		kind:           "",
+		kindNode:       nil,
+		node:           node,
+		fields:         make(map[string]*yaml.Node),
+		references:     nil,
+		expression:     nil,
+		expressionNode: nil,
+		metadata:       nil,
+		rewrite:        nil,
+		errors:         nil,
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return definition
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if key.Value == "kind" {
+			definition.kind = value.Value
+			definition.kindNode = value
+			continue
+		}
+
+		if _, ok := definition.fields[key.Value]; ok {
+			definition.fail(key, fmt.Errorf("%w: duplicate field %s", ErrInvalidValue, key.Value))
+		}
+
+		definition.fields[key.Value] = value
+	}
+
+	return definition
+}
+
+func (definition *definition) valueType() expression.Type {
+	name := kinds[definition.kind].defaultType
+
+	if node, ok := definition.fields["type"]; ok {
+		name = node.Value
+	}
+
+	for _, kind := range []expression.Type{expression.Int, expression.Float, expression.Bool} {
+		if kind.String() == name {
+			return kind
+		}
+	}
+
+	return expression.Invalid
+}
+
+func (definition *definition) resolve(reference string) string {
+	if definition.rewrite != nil {
+		reference = definition.rewrite(reference)
+	}
+
+	return storage.Resolve(definition.name, reference)
+}
+
+func checkExpression(definition *definition, names map[string]bool, types map[string]expression.Type) {
+	dangling := false
+
+	for _, reference := range definition.expression.References() {
+		if !names[definition.resolve(reference)] {
+			definition.fail(definition.expressionNode, fmt.Errorf("%w: %s", ErrDanglingReference, reference))
+			dangling = true
+		}
+	}
+
+	if dangling {
+		return
+	}
+
+	result, err := definition.expression.Check(func(name string) (expression.Type, bool) {
+		kind, ok := types[definition.resolve(name)]
+
+		return kind, ok
+	})
+
+	if err != nil {
+		definition.fail(definition.expressionNode, fmt.Errorf("%w: %w", ErrInvalidExpression, err))
+		return
+	}
+
+	if target := definition.valueType(); !expression.Assignable(result, target) {
+		definition.fail(definition.expressionNode, fmt.Errorf("%w: expression yields %s, resource is %s", ErrInvalidExpression, result, target))
+	}
+}
+
+func (definition *definition) fail(node *yaml.Node, err error) {
+	definition.errors = append(definition.errors, positioned(node, fmt.Errorf("resource %s: %w", definition.name, err)))
+}
+
+func positioned(node *yaml.Node, err error) error {
+	return &Error{Line: node.Line, Column: node.Column, Err: err}
+}
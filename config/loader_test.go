@@ -0,0 +1,496 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    error
+		line   int
+		column int
+	}{
+		{
+			name:   "syntax",
+			source: "resources: [",
+			err:    ErrSyntax,
+		},
+		{
+			name:   "empty",
+			source: "",
+			err:    ErrInvalidDocument,
+			line:   1,
+			column: 1,
+		},
+		{
+			name:   "missing resources",
+			source: "propagation: async\n",
+			err:    ErrMissingField,
+			line:   1,
+			column: 1,
+		},
+		{
+			name:   "unknown top level field",
+			source: "resources: {}\nplugins: []\n",
+			err:    ErrUnknownField,
+			line:   2,
+			column: 1,
+		},
+		{
+			name:   "invalid propagation",
+			source: "resources: {}\npropagation: eager\n",
+			err:    ErrInvalidValue,
+			line:   2,
+			column: 14,
+		},
+		{
+			name: "unknown kind",
+			source: `resources:
+  level:
+    kind: tank
+`,
+			err:    ErrUnknownKind,
+			line:   3,
+			column: 11,
+		},
+		{
+			name: "missing kind",
+			source: `resources:
+  level:
+    value: 1
+`,
+			err:    ErrMissingField,
+			line:   3,
+			column: 5,
+		},
+		{
+			name: "unknown field",
+			source: `resources:
+  level:
+    kind: static
+    type: float32
+    value: 1
+    colour: red
+`,
+			err:    ErrUnknownField,
+			line:   6,
+			column: 13,
+		},
+		{
+			name: "unknown type",
+			source: `resources:
+  level:
+    kind: static
+    type: string
+    value: 1
+`,
+			err:    ErrUnknownType,
+			line:   4,
+			column: 11,
+		},
+		{
+			name: "invalid name",
+			source: `resources:
+  tank//level:
+    kind: static
+    type: int32
+    value: 1
+`,
+			err:    storage.ErrInvalidName,
+			line:   2,
+			column: 3,
+		},
+		{
+			name: "dangling field reference",
+			source: `resources:
+  level:
+    kind: first_order
+    type: float32
+    input: valve
+    gain: 1
+    time_constant: 1s
+    interval: 100ms
+`,
+			err:    ErrDanglingReference,
+			line:   5,
+			column: 12,
+		},
+		{
+			name: "dangling expression reference",
+			source: `resources:
+  alarm:
+    kind: computed
+    type: bool
+    expression: level > 3
+`,
+			err:    ErrDanglingReference,
+			line:   5,
+			column: 17,
+		},
+		{
+			name: "mistyped expression",
+			source: `resources:
+  level:
+    kind: static
+    type: float32
+    value: 1
+  alarm:
+    kind: computed
+    type: bool
+    expression: level + 1
+`,
+			err:    ErrInvalidExpression,
+			line:   9,
+			column: 17,
+		},
+		{
+			name: "duplicate resource",
+			source: `resources:
+  level:
+    kind: static
+    type: int32
+    value: 1
+  level:
+    kind: static
+    type: int32
+    value: 2
+`,
+			err:    ErrDuplicateResource,
+			line:   6,
+			column: 3,
+		},
+		{
+			name: "duplicate field",
+			source: `resources:
+  level:
+    kind: static
+    type: int32
+    value: 1
+    value: 2
+`,
+			err:    ErrInvalidValue,
+			line:   6,
+			column: 5,
+		},
+		{
+			name: "quantizer bits out of range",
+			source: `resources:
+  level:
+    kind: static
+    type: float32
+    value: 1
+  quantized:
+    kind: quantize
+    type: float32
+    source: level
+    bits: 33
+    min: 0
+    max: 10
+`,
+			err:    ErrInvalidValue,
+			line:   10,
+			column: 11,
+		},
+		{
+			name: "quantizer empty range",
+			source: `resources:
+  level:
+    kind: static
+    type: float32
+    value: 1
+  quantized:
+    kind: quantize
+    type: float32
+    source: level
+    bits: 8
+    min: 10
+    max: 10
+`,
+			err:    ErrInvalidValue,
+			line:   11,
+			column: 10,
+		},
+		{
+			name: "unknown template",
+			source: `resources: {}
+instances:
+  tank:
+    template: vessel
+`,
+			err:    ErrUnknownTemplate,
+			line:   4,
+			column: 15,
+		},
+		{
+			name: "missing template parameter",
+			source: `resources: {}
+templates:
+  vessel:
+    parameters:
+      capacity: null
+    resources:
+      level:
+        kind: static
+        type: float32
+        value: ${capacity}
+instances:
+  tank:
+    template: vessel
+`,
+			err:    storage.ErrMissingParameter,
+			line:   12,
+			column: 3,
+		},
+		{
+			name: "unknown template parameter",
+			source: `resources: {}
+templates:
+  vessel:
+    parameters:
+      capacity: 10
+    resources:
+      level:
+        kind: static
+        type: float32
+        value: ${capacity}
+instances:
+  tank:
+    template: vessel
+    parameters:
+      volume: 5
+`,
+			err:    storage.ErrUnknownParameter,
+			line:   15,
+			column: 7,
+		},
+		{
+			name: "instance collides with resource",
+			source: `resources:
+  tank/level:
+    kind: static
+    type: float32
+    value: 1
+templates:
+  vessel:
+    resources:
+      level:
+        kind: static
+        type: float32
+        value: 2
+instances:
+  tank:
+    template: vessel
+`,
+			err:    ErrDuplicateResource,
+			line:   14,
+			column: 3,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := NewLoader().Load([]byte(test.source))
+
+		if !errors.Is(err, test.err) {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+
+		if test.line == 0 {
+			continue
+		}
+
+		var positioned *Error
+
+		if !errors.As(err, &positioned) || positioned.Line != test.line || positioned.Column != test.column {
+			t.Fatalf("%s: expected line %d, column %d, got %v", test.name, test.line, test.column, err)
+		}
+	}
+}
+
+func TestLoadReportsEveryErrorInOrder(t *testing.T) {
+	_, err := NewLoader().Load([]byte(`resources:
+  alarm:
+    kind: computed
+    type: bool
+    expression: missing > 1
+  level:
+    kind: tank
+`))
+
+	joined, ok := err.(interface{ Unwrap() []error })
+
+	if !ok {
+		t.Fatalf("expected joined errors, got %v", err)
+	}
+
+	var lines []int
+
+	for _, err := range joined.Unwrap() {
+		var positioned *Error
+
+		if errors.As(err, &positioned) {
+			lines = append(lines, positioned.Line)
+		}
+	}
+
+	if !slices.Equal(lines, []int{5, 7}) {
+		t.Fatalf("expected errors on lines 5 and 7, got %v", lines)
+	}
+}
+
+func TestLoadResources(t *testing.T) {
+	loaded, err := NewLoader().Load([]byte(`propagation: topological
+resources:
+  setpoint:
+    kind: static
+    type: float32
+    value: 4
+    unit: m
+    range: {min: 0, max: 10}
+  alarm:
+    kind: computed
+    type: bool
+    expression: setpoint > 3
+`))
+
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	start(t, loaded)
+
+	if value, _ := loaded.Read("alarm"); value != true {
+		t.Fatalf("expected the alarm to be raised, got %v", value)
+	}
+
+	if metadata, _ := loaded.Metadata("setpoint"); metadata.Unit != "m" || metadata.Range == nil || metadata.Range.Max != 10 {
+		t.Fatalf("unexpected metadata %+v", metadata)
+	}
+}
+
+func TestLoadTemplateInstances(t *testing.T) {
+	loaded, err := NewLoader().Load([]byte(`resources:
+  setpoint:
+    kind: static
+    type: float32
+    value: 5
+templates:
+  vessel:
+    parameters:
+      capacity: 10
+      unit: m
+    resources:
+      level:
+        kind: static
+        type: float32
+        value: ${capacity}
+        unit: ${unit}
+      full:
+        kind: computed
+        type: bool
+        expression: level >= setpoint
+instances:
+  small:
+    template: vessel
+    parameters:
+      capacity: 2
+  large:
+    template: vessel
+    parameters:
+      unit: cm
+`))
+
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	names := loaded.Names()
+	slices.Sort(names)
+
+	if expected := []string{"large/full", "large/level", "setpoint", "small/full", "small/level"}; !slices.Equal(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+
+	start(t, loaded)
+
+	for name, expected := range map[string]any{"small/level": float32(2), "large/level": float32(10), "small/full": false, "large/full": true} {
+		if value, _ := loaded.Read(name); value != expected {
+			t.Fatalf("%s: expected %v, got %v", name, expected, value)
+		}
+	}
+
+	if metadata, _ := loaded.Metadata("large/level"); metadata.Unit != "cm" {
+		t.Fatalf("expected the unit override, got %q", metadata.Unit)
+	}
+}
+
+func start(t *testing.T, loaded *storage.Storage) {
+	t.Helper()
+
+	if err := loaded.Start(context.Background(), event.NewEvents(time.Second), clock.NewReal(), 1); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	t.Cleanup(func() {
+		loaded.Stop(context.Background())
+	})
+}
+
+func TestLoadRegisteredTemplate(t *testing.T) {
+	loader := NewLoader()
+	loader.RegisterTemplate(storage.NewTemplate("pump", storage.Parameters{"speed": float64(1)}, func(parameters storage.Parameters) (*storage.Storage, error) {
+		return storage.NewStorage(map[string]storage.Resource{
+			"speed": resource.NewStatic(float32(storage.Parameter[float64](parameters, "speed"))),
+		}), nil
+	}))
+
+	loaded, err := loader.Load([]byte(`resources:
+  limit:
+    kind: static
+    type: float32
+    value: 2
+  overspeed:
+    kind: computed
+    type: bool
+    expression: "` + "`pump/speed`" + ` > limit"
+instances:
+  pump:
+    template: pump
+    parameters:
+      speed: 3
+`))
+
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	start(t, loaded)
+
+	if value, _ := loaded.Read("pump/speed"); value != float32(3) {
+		t.Fatalf("expected the override, got %v", value)
+	}
+
+	if value, _ := loaded.Read("overspeed"); value != true {
+		t.Fatalf("expected the mounted resource to be typed, got %v", value)
+	}
+
+	if _, err := loader.Load([]byte(`resources: {}
+instances:
+  pump:
+    template: pump
+    parameters:
+      speed: fast
+`)); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("expected %v, got %v", ErrInvalidValue, err)
+	}
+}
@@ -0,0 +1,381 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/studiolambda/immersim/expression"
+	"github.com/studiolambda/immersim/storage"
+	"gopkg.in/yaml.v3"
+)
+
+type template struct {
+	name       string
+	parameters map[string]*yaml.Node
+	resources  *yaml.Node
+	names      []string
+}
+
+type instance struct {
+	prefix  string
+	node    *yaml.Node
+	storage *storage.Storage
+}
+
+var placeholder = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+func (loader *Loader) RegisterTemplate(template *storage.Template) {
+	loader.templates[template.Name()] = template
+}
+
+func parseTemplates(node *yaml.Node) (map[string]*template, []error) {
+	templates := make(map[string]*template)
+
+	if node == nil {
+		return templates, nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return templates, []error{positioned(node, fmt.Errorf("%w: templates must be a mapping", ErrInvalidDocument))}
+	}
+
+	var errs []error
+
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if value.Kind != yaml.MappingNode {
+			errs = append(errs, positioned(value, fmt.Errorf("%w: template %s must be a mapping", ErrInvalidDocument, key.Value)))
+			continue
+		}
+
+		parsed := &template{
+			name:       key.Value,
+			parameters: make(map[string]*yaml.Node),
+			resources:  nil,
+			names:      nil,
+		}
+
+		for j := 0; j < len(value.Content); j += 2 {
+			field, content := value.Content[j], value.Content[j+1]
+
+			switch field.Value {
+			case "parameters":
+				if content.Kind != yaml.MappingNode {
+					errs = append(errs, positioned(content, fmt.Errorf("%w: parameters must be a mapping", ErrInvalidValue)))
+					continue
+				}
+
+				for k := 0; k < len(content.Content); k += 2 {
+					parsed.parameters[content.Content[k].Value] = content.Content[k+1]
+				}
+			case "resources":
+				if content.Kind != yaml.MappingNode {
+					errs = append(errs, positioned(content, fmt.Errorf("%w: resources must be a mapping", ErrInvalidDocument)))
+					continue
+				}
+
+				parsed.resources = content
+
+				for k := 0; k < len(content.Content); k += 2 {
+					parsed.names = append(parsed.names, content.Content[k].Value)
+				}
+			default:
+				errs = append(errs, positioned(field, fmt.Errorf("%w: %s for template %s", ErrUnknownField, field.Value, key.Value)))
+			}
+		}
+
+		if parsed.resources == nil {
+			errs = append(errs, positioned(value, fmt.Errorf("%w: resources for template %s", ErrMissingField, key.Value)))
+			continue
+		}
+
+		slices.Sort(parsed.names)
+		templates[key.Value] = parsed
+	}
+
+	return templates, errs
+}
+
+func (loader *Loader) expand(node *yaml.Node, templates map[string]*template, names map[string]bool) ([]*definition, []*instance, []error) {
+	if node == nil {
+		return nil, nil, nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil, nil, []error{positioned(node, fmt.Errorf("%w: instances must be a mapping", ErrInvalidDocument))}
+	}
+
+	var definitions []*definition
+	var instances []*instance
+	var errs []error
+
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		name, nameNode, overrides, instanceErrs := parseInstance(key, value)
+		errs = append(errs, instanceErrs...)
+
+		if nameNode == nil {
+			continue
+		}
+
+		if err := storage.CheckName(key.Value); err != nil {
+			errs = append(errs, positioned(key, err))
+			continue
+		}
+
+		if parsed, ok := templates[name]; ok {
+			expanded, expandErrs := parsed.instantiate(key, overrides)
+			errs = append(errs, expandErrs...)
+
+			for _, definition := range expanded {
+				if names[definition.name] {
+					errs = append(errs, positioned(key, fmt.Errorf("%w: %s", ErrDuplicateResource, definition.name)))
+					continue
+				}
+
+				names[definition.name] = true
+				definitions = append(definitions, definition)
+			}
+
+			continue
+		}
+
+		registered, ok := loader.templates[name]
+
+		if !ok {
+			errs = append(errs, positioned(nameNode, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)))
+			continue
+		}
+
+		mounted, mountErrs := loader.instantiate(registered, key, overrides)
+		errs = append(errs, mountErrs...)
+
+		if mounted == nil {
+			continue
+		}
+
+		for _, resource := range mounted.Names() {
+			if names[storage.Join(key.Value, resource)] {
+				errs = append(errs, positioned(key, fmt.Errorf("%w: %s", ErrDuplicateResource, storage.Join(key.Value, resource))))
+			}
+
+			names[storage.Join(key.Value, resource)] = true
+		}
+
+		instances = append(instances, &instance{prefix: key.Value, node: key, storage: mounted})
+	}
+
+	return definitions, instances, errs
+}
+
+func parseInstance(key *yaml.Node, value *yaml.Node) (string, *yaml.Node, *yaml.Node, []error) {
+	if value.Kind != yaml.MappingNode {
+		return "", nil, nil, []error{positioned(value, fmt.Errorf("%w: instance %s must be a mapping", ErrInvalidDocument, key.Value))}
+	}
+
+	var name, overrides *yaml.Node
+	var errs []error
+
+	for i := 0; i < len(value.Content); i += 2 {
+		switch field := value.Content[i]; field.Value {
+		case "template":
+			name = value.Content[i+1]
+		case "parameters":
+			overrides = value.Content[i+1]
+
+			if overrides.Kind != yaml.MappingNode {
+				errs = append(errs, positioned(overrides, fmt.Errorf("%w: parameters must be a mapping", ErrInvalidValue)))
+				overrides = nil
+			}
+		default:
+			errs = append(errs, positioned(field, fmt.Errorf("%w: %s for instance %s", ErrUnknownField, field.Value, key.Value)))
+		}
+	}
+
+	if name == nil {
+		return "", nil, nil, append(errs, positioned(value, fmt.Errorf("%w: template for instance %s", ErrMissingField, key.Value)))
+	}
+
+	if overrides == nil {
+		overrides = &yaml.Node{Kind: yaml.MappingNode}
+	}
+
+	return name.Value, name, overrides, errs
+}
+
+func (template *template) instantiate(prefix *yaml.Node, overrides *yaml.Node) ([]*definition, []error) {
+	parameters := make(map[string]*yaml.Node, len(template.parameters))
+	var errs []error
+
+	for name, node := range template.parameters {
+		parameters[name] = node
+	}
+
+	for i := 0; i < len(overrides.Content); i += 2 {
+		key := overrides.Content[i]
+
+		if _, ok := parameters[key.Value]; !ok {
+			errs = append(errs, positioned(key, fmt.Errorf("%w: %s in template %s", storage.ErrUnknownParameter, key.Value, template.name)))
+			continue
+		}
+
+		parameters[key.Value] = overrides.Content[i+1]
+	}
+
+	for _, name := range sortedNames(parameters) {
+		if parameters[name].Tag == "!!null" {
+			errs = append(errs, positioned(prefix, fmt.Errorf("%w: %s in template %s", storage.ErrMissingParameter, name, template.name)))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	rewrite := func(reference string) string {
+		if _, found := slices.BinarySearch(template.names, strings.TrimPrefix(reference, storage.Separator)); found {
+			return storage.Join(prefix.Value, reference)
+		}
+
+		return reference
+	}
+
+	definitions := make([]*definition, 0, len(template.resources.Content)/2)
+
+	for i := 0; i < len(template.resources.Content); i += 2 {
+		key, value := template.resources.Content[i], template.resources.Content[i+1]
+		node, substitutionErrs := substitute(value, parameters, template.name)
+
+		definition := newDefinition(storage.Join(prefix.Value, key.Value), node)
+		definition.rewrite = rewrite
+		definition.errors = append(definition.errors, substitutionErrs...)
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, nil
+}
+
+func substitute(node *yaml.Node, parameters map[string]*yaml.Node, template string) (*yaml.Node, []error) {
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "${") {
+		return interpolate(node, parameters, template)
+	}
+
+	copied := *node
+	copied.Content = make([]*yaml.Node, 0, len(node.Content))
+
+	var errs []error
+
+	for _, child := range node.Content {
+		substituted, childErrs := substitute(child, parameters, template)
+		copied.Content = append(copied.Content, substituted)
+		errs = append(errs, childErrs...)
+	}
+
+	return &copied, errs
+}
+
+func interpolate(node *yaml.Node, parameters map[string]*yaml.Node, template string) (*yaml.Node, []error) {
+	var errs []error
+
+	if match := placeholder.FindStringSubmatch(node.Value); match != nil && match[0] == node.Value {
+		if parameter, ok := parameters[match[1]]; ok {
+			copied := *parameter
+
+			return &copied, nil
+		}
+	}
+
+	value := placeholder.ReplaceAllStringFunc(node.Value, func(match string) string {
+		name := placeholder.FindStringSubmatch(match)[1]
+		parameter, ok := parameters[name]
+
+		if !ok {
+			errs = append(errs, positioned(node, fmt.Errorf("%w: %s in template %s", storage.ErrUnknownParameter, name, template)))
+			return match
+		}
+
+		if parameter.Kind != yaml.ScalarNode {
+			errs = append(errs, positioned(node, fmt.Errorf("%w: parameter %s must be a scalar to be interpolated", ErrInvalidValue, name)))
+			return match
+		}
+
+		return parameter.Value
+	})
+
+	copied := *node
+	copied.Value = value
+	copied.Tag = ""
+	copied.Style = 0
+
+	return &copied, errs
+}
+
+func (loader *Loader) instantiate(template *storage.Template, prefix *yaml.Node, overrides *yaml.Node) (*storage.Storage, []error) {
+	defaults := template.Parameters()
+	parameters := make(storage.Parameters, len(overrides.Content)/2)
+	var errs []error
+
+	for i := 0; i < len(overrides.Content); i += 2 {
+		key, value := overrides.Content[i], overrides.Content[i+1]
+		fallback, ok := defaults[key.Value]
+
+		if !ok {
+			errs = append(errs, positioned(key, fmt.Errorf("%w: %s in template %s", storage.ErrUnknownParameter, key.Value, template.Name())))
+			continue
+		}
+
+		target := reflect.New(reflect.TypeOf(&fallback).Elem())
+
+		if fallback != nil {
+			target = reflect.New(reflect.TypeOf(fallback))
+		}
+
+		if err := value.Decode(target.Interface()); err != nil {
+			errs = append(errs, positioned(value, fmt.Errorf("%w: %w", ErrInvalidValue, err)))
+			continue
+		}
+
+		parameters[key.Value] = target.Elem().Interface()
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	instance, err := template.Instantiate(parameters)
+
+	if err != nil {
+		return nil, []error{positioned(prefix, err)}
+	}
+
+	return instance, nil
+}
+
+func (instance *instance) types(types map[string]expression.Type) {
+	for _, name := range instance.storage.Names() {
+		value, err := instance.storage.Read(name)
+
+		if err != nil {
+			types[storage.Join(instance.prefix, name)] = expression.Invalid
+			continue
+		}
+
+		types[storage.Join(instance.prefix, name)], _ = expression.TypeOf(value)
+	}
+}
+
+func sortedNames(nodes map[string]*yaml.Node) []string {
+	names := make([]string, 0, len(nodes))
+
+	for name := range nodes {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,187 @@
+package event
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Kind int
+
+type Envelope[T any] struct {
+	Kind      Kind      `json:"kind"`
+	Event     Event     `json:"event"`
+	Resource  string    `json:"resource"`
+	Action    string    `json:"action,omitempty"`
+	Payload   T         `json:"payload"`
+	Sequence  uint64    `json:"sequence"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Subscription[T any] struct {
+	C          <-chan Envelope[T]
+	events     *Events
+	event      Event
+	pattern    bool
+	raw        chan any
+	output     chan Envelope[T]
+	mismatched atomic.Uint64
+	quit       chan struct{}
+	once       sync.Once
+	wg         sync.WaitGroup
+}
+
+const (
+	KindChanged Kind = iota
+	KindAction
+	KindLifecycle
+)
+
+func (kind Kind) String() string {
+	switch kind {
+	case KindChanged:
+		return "changed"
+	case KindAction:
+		return "action"
+	case KindLifecycle:
+		return "lifecycle"
+	}
+
+	return "unknown"
+}
+
+func (kind Kind) MarshalText() ([]byte, error) {
+	return []byte(kind.String()), nil
+}
+
+func envelope(event Event, payload any, sequence uint64, timestamp time.Time) Envelope[any] {
+	envelope := Envelope[any]{
+		Kind:      KindChanged,
+		Event:     event,
+		Resource:  string(event),
+		Action:    "",
+		Payload:   payload,
+		Sequence:  sequence,
+		Timestamp: timestamp,
+	}
+
+	if strings.HasPrefix(string(event), "@") {
+		envelope.Kind = KindLifecycle
+		envelope.Resource = ""
+
+		if lifecycle, ok := payload.(LifecyclePayload); ok {
+			envelope.Resource = lifecycle.Resource
+		}
+
+		return envelope
+	}
+
+	if resource, action, found := strings.Cut(string(event), ":"); found {
+		envelope.Kind = KindAction
+		envelope.Resource = resource
+		envelope.Action = action
+	}
+
+	return envelope
+}
+
+func SubscribeTyped[T any](events *Events, event Event, policy Policy, capacity int) *Subscription[T] {
+	subscription := newSubscription[T](events, event, false)
+	events.subscribe(newSubscriber(event, subscription.raw, policy, capacity, events.timeout), true)
+
+	return subscription
+}
+
+func SubscribePatternTyped[T any](events *Events, pattern string, policy Policy, capacity int) (*Subscription[T], error) {
+	subscription := newSubscription[T](events, Event(pattern), true)
+
+	if err := events.SubscribePatternWith(pattern, subscription.raw, policy, capacity); err != nil {
+		subscription.Close()
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func newSubscription[T any](events *Events, event Event, pattern bool) *Subscription[T] {
+	output := make(chan Envelope[T])
+
+	subscription := &Subscription[T]{
+		C:          output,
+		events:     events,
+		event:      event,
+		pattern:    pattern,
+		raw:        make(chan any),
+		output:     output,
+		mismatched: atomic.Uint64{},
+		quit:       make(chan struct{}),
+		once:       sync.Once{},
+		wg:         sync.WaitGroup{},
+	}
+
+	subscription.wg.Add(1)
+	go subscription.loop()
+
+	return subscription
+}
+
+func (subscription *Subscription[T]) loop() {
+	defer subscription.wg.Done()
+
+	for {
+		select {
+		case raw := <-subscription.raw:
+			received, ok := raw.(Envelope[any])
+
+			if !ok {
+				continue
+			}
+
+			payload, ok := received.Payload.(T)
+
+			if !ok {
+				subscription.mismatched.Add(1)
+				continue
+			}
+
+			select {
+			case subscription.output <- Envelope[T]{
+				Kind:      received.Kind,
+				Event:     received.Event,
+				Resource:  received.Resource,
+				Action:    received.Action,
+				Payload:   payload,
+				Sequence:  received.Sequence,
+				Timestamp: received.Timestamp,
+			}:
+			case <-subscription.quit:
+				return
+			}
+		case <-subscription.quit:
+			return
+		}
+	}
+}
+
+func (subscription *Subscription[T]) Mismatched() uint64 {
+	return subscription.mismatched.Load()
+}
+
+func (subscription *Subscription[T]) Dropped() uint64 {
+	return subscription.events.Dropped(subscription.event, subscription.raw)
+}
+
+func (subscription *Subscription[T]) Close() {
+	subscription.once.Do(func() {
+		if subscription.pattern {
+			subscription.events.UnsubscribePattern(string(subscription.event), subscription.raw)
+		} else {
+			subscription.events.Unsubscribe(subscription.event, subscription.raw)
+		}
+
+		close(subscription.quit)
+		subscription.wg.Wait()
+		close(subscription.output)
+	})
+}
@@ -1,14 +1,32 @@
 package event
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+
+	"github.com/studiolambda/immersim/quality"
+)
 
 type Event string
 
 type ChangedPayload struct {
-	Resource string
-	Value    any
+	Resource        string          `json:"resource"`
+	Value           any             `json:"value"`
+	Quality         quality.Quality `json:"quality"`
+	SourceTimestamp time.Time       `json:"source_timestamp"`
+	ServerTimestamp time.Time       `json:"server_timestamp"`
+}
+
+type LifecyclePayload struct {
+	Resource string `json:"resource"`
 }
 
+const (
+	Added    Event = "@added"
+	Removed  Event = "@removed"
+	Replaced Event = "@replaced"
+)
+
 func Changed(resource string) Event {
 	return Event(resource)
 }
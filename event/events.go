@@ -1,50 +1,210 @@
 package event
 
 import (
+	"cmp"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/studiolambda/immersim/clock"
 )
 
+type Interceptor func(event Event, payload any, deliver func(payload any))
+
 type Events struct {
-	mutex     sync.RWMutex
-	timeout   time.Duration
-	listeners map[Event][]chan<- any
+	mutex       sync.RWMutex
+	timeout     time.Duration
+	subscribers map[Event][]*subscriber
+	patterns    *trie
+	interceptor Interceptor
+	clock       clock.Clock
+	sequence    atomic.Uint64
 }
 
 func NewEvents(timeout time.Duration) *Events {
 	return &Events{
-		mutex:     sync.RWMutex{},
-		listeners: make(map[Event][]chan<- any),
+		mutex:       sync.RWMutex{},
+		timeout:     timeout,
+		subscribers: make(map[Event][]*subscriber),
+		patterns:    newTrie(),
+		interceptor: nil,
+		clock:       clock.NewReal(),
+		sequence:    atomic.Uint64{},
 	}
 }
 
 func (events *Events) Subscribe(event Event, listener chan<- any) {
+	events.SubscribeWith(event, listener, Block, DefaultCapacity)
+}
+
+func (events *Events) SubscribeWith(event Event, listener chan<- any, policy Policy, capacity int) {
+	events.subscribe(newSubscriber(event, listener, policy, capacity, events.timeout), false)
+}
+
+func (events *Events) subscribe(subscriber *subscriber, enveloped bool) {
+	subscriber.enveloped = enveloped
+
 	events.mutex.Lock()
 	defer events.mutex.Unlock()
 
-	events.listeners[event] = append(events.listeners[event], listener)
+	events.subscribers[subscriber.event] = append(events.subscribers[subscriber.event], subscriber)
 }
 
 func (events *Events) Unsubscribe(event Event, listener chan<- any) {
+	var removed []*subscriber
+
 	events.mutex.Lock()
-	defer events.mutex.Unlock()
 
-	events.listeners[event] = slices.DeleteFunc(events.listeners[event], func(lis chan<- any) bool {
-		return listener == lis
+	events.subscribers[event] = slices.DeleteFunc(events.subscribers[event], func(subscriber *subscriber) bool {
+		if subscriber.listener == listener {
+			removed = append(removed, subscriber)
+			return true
+		}
+
+		return false
 	})
+
+	if len(events.subscribers[event]) == 0 {
+		delete(events.subscribers, event)
+	}
+
+	events.mutex.Unlock()
+
+	for _, subscriber := range removed {
+		subscriber.stop()
+	}
+}
+
+func (events *Events) SubscribePattern(pattern string, listener chan<- any) error {
+	return events.SubscribePatternWith(pattern, listener, Block, DefaultCapacity)
+}
+
+func (events *Events) SubscribePatternWith(pattern string, listener chan<- any, policy Policy, capacity int) error {
+	tokens, err := parsePattern(pattern)
+
+	if err != nil {
+		return err
+	}
+
+	subscriber := newSubscriber(Event(pattern), listener, policy, capacity, events.timeout)
+	subscriber.pattern = true
+	subscriber.enveloped = true
+
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+
+	events.patterns.insert(tokens, subscriber)
+
+	return nil
+}
+
+func (events *Events) UnsubscribePattern(pattern string, listener chan<- any) {
+	tokens, err := parsePattern(pattern)
+
+	if err != nil {
+		return
+	}
+
+	events.mutex.Lock()
+	removed := events.patterns.remove(tokens, listener)
+	events.mutex.Unlock()
+
+	for _, subscriber := range removed {
+		subscriber.stop()
+	}
+}
+
+func (events *Events) Intercept(interceptor Interceptor) {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+
+	events.interceptor = interceptor
+}
+
+func (events *Events) SetClock(clock clock.Clock) {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+
+	events.clock = clock
 }
 
 func (events *Events) Emit(event Event, payload any) {
+	events.mutex.RLock()
+	interceptor := events.interceptor
+	emitted := envelope(event, payload, events.sequence.Add(1), events.clock.Now())
+	events.mutex.RUnlock()
+
+	if interceptor != nil {
+		interceptor(event, payload, func(payload any) {
+			delivered := emitted
+			delivered.Payload = payload
+
+			events.deliver(delivered)
+		})
+
+		return
+	}
+
+	events.deliver(emitted)
+}
+
+func (events *Events) Dropped(event Event, listener chan<- any) uint64 {
 	events.mutex.RLock()
 	defer events.mutex.RUnlock()
 
-	for _, listener := range events.listeners[event] {
-		select {
-		case listener <- payload:
-			continue
-		case <-time.After(events.timeout):
+	var dropped uint64
+
+	for _, subscriber := range events.subscribers[event] {
+		if subscriber.listener == listener {
+			dropped += subscriber.dropped.Load()
+		}
+	}
+
+	for _, subscriber := range events.patterns.all(nil) {
+		if subscriber.event == event && subscriber.listener == listener {
+			dropped += subscriber.dropped.Load()
+		}
+	}
+
+	return dropped
+}
+
+func (events *Events) Stats() []Stat {
+	events.mutex.RLock()
+	defer events.mutex.RUnlock()
+
+	var stats []Stat
+
+	for _, subscribers := range events.subscribers {
+		for _, subscriber := range subscribers {
+			stats = append(stats, subscriber.stat())
+		}
+	}
+
+	for _, subscriber := range events.patterns.all(nil) {
+		stats = append(stats, subscriber.stat())
+	}
+
+	slices.SortStableFunc(stats, func(a Stat, b Stat) int {
+		return cmp.Compare(a.Event, b.Event)
+	})
+
+	return stats
+}
+
+func (events *Events) deliver(envelope Envelope[any]) {
+	events.mutex.RLock()
+	subscribers := slices.Clone(events.subscribers[envelope.Event])
+	subscribers = events.patterns.match(tokenize(string(envelope.Event)), make(map[*subscriber]bool), subscribers)
+	events.mutex.RUnlock()
+
+	for _, subscriber := range subscribers {
+		if subscriber.enveloped {
+			subscriber.push(envelope)
 			continue
 		}
+
+		subscriber.push(envelope.Payload)
 	}
 }
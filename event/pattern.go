@@ -0,0 +1,212 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type trie struct {
+	children    map[string]*trie
+	single      *trie
+	action      *trie
+	multi       *trie
+	subscribers []*subscriber
+}
+
+const (
+	singleWildcard = "*"
+	multiWildcard  = "**"
+	actionWildcard = ":*"
+)
+
+var ErrInvalidPattern = errors.New("invalid event pattern")
+
+func newTrie() *trie {
+	return &trie{
+		children:    make(map[string]*trie),
+		single:      nil,
+		action:      nil,
+		multi:       nil,
+		subscribers: nil,
+	}
+}
+
+func tokenize(name string) []string {
+	resource, action, found := strings.Cut(name, ":")
+	tokens := strings.Split(resource, "/")
+
+	if found {
+		tokens = append(tokens, ":"+action)
+	}
+
+	return tokens
+}
+
+func parsePattern(pattern string) ([]string, error) {
+	tokens := tokenize(pattern)
+
+	for _, token := range tokens {
+		switch token {
+		case singleWildcard, multiWildcard, actionWildcard:
+			continue
+		}
+
+		if strings.Contains(token, "*") {
+			return nil, fmt.Errorf("%w: %s: wildcards must span a whole segment", ErrInvalidPattern, pattern)
+		}
+	}
+
+	return tokens, nil
+}
+
+func (node *trie) insert(tokens []string, subscriber *subscriber) {
+	current := node
+
+	for _, token := range tokens {
+		current = current.child(token)
+	}
+
+	current.subscribers = append(current.subscribers, subscriber)
+}
+
+func (node *trie) remove(tokens []string, listener chan<- any) []*subscriber {
+	if len(tokens) > 0 {
+		child := node.find(tokens[0])
+
+		if child == nil {
+			return nil
+		}
+
+		removed := child.remove(tokens[1:], listener)
+
+		if child.empty() {
+			node.prune(tokens[0])
+		}
+
+		return removed
+	}
+
+	var removed []*subscriber
+	kept := node.subscribers[:0]
+
+	for _, subscriber := range node.subscribers {
+		if subscriber.listener == listener {
+			removed = append(removed, subscriber)
+			continue
+		}
+
+		kept = append(kept, subscriber)
+	}
+
+	clear(node.subscribers[len(kept):])
+	node.subscribers = kept
+
+	return removed
+}
+
+func (node *trie) empty() bool {
+	return len(node.subscribers) == 0 && len(node.children) == 0 && node.single == nil && node.action == nil && node.multi == nil
+}
+
+func (node *trie) prune(token string) {
+	switch token {
+	case singleWildcard:
+		node.single = nil
+	case multiWildcard:
+		node.multi = nil
+	case actionWildcard:
+		node.action = nil
+	default:
+		delete(node.children, token)
+	}
+}
+
+func (node *trie) child(token string) *trie {
+	if existing := node.find(token); existing != nil {
+		return existing
+	}
+
+	created := newTrie()
+
+	switch token {
+	case singleWildcard:
+		node.single = created
+	case multiWildcard:
+		node.multi = created
+	case actionWildcard:
+		node.action = created
+	default:
+		node.children[token] = created
+	}
+
+	return created
+}
+
+func (node *trie) find(token string) *trie {
+	switch token {
+	case singleWildcard:
+		return node.single
+	case multiWildcard:
+		return node.multi
+	case actionWildcard:
+		return node.action
+	}
+
+	return node.children[token]
+}
+
+func (node *trie) match(tokens []string, matched map[*subscriber]bool, result []*subscriber) []*subscriber {
+	if node.multi != nil {
+		for i := 0; i <= len(tokens); i++ {
+			result = node.multi.match(tokens[i:], matched, result)
+		}
+	}
+
+	if len(tokens) == 0 {
+		for _, subscriber := range node.subscribers {
+			if !matched[subscriber] {
+				matched[subscriber] = true
+				result = append(result, subscriber)
+			}
+		}
+
+		return result
+	}
+
+	token, rest := tokens[0], tokens[1:]
+
+	if child, ok := node.children[token]; ok {
+		result = child.match(rest, matched, result)
+	}
+
+	if strings.HasPrefix(token, ":") {
+		if node.action != nil {
+			result = node.action.match(rest, matched, result)
+		}
+
+		return result
+	}
+
+	if node.single != nil {
+		result = node.single.match(rest, matched, result)
+	}
+
+	return result
+}
+
+func (node *trie) all(result []*subscriber) []*subscriber {
+	result = append(result, node.subscribers...)
+
+	for _, child := range node.children {
+		result = child.all(result)
+	}
+
+	for _, child := range []*trie{node.single, node.action, node.multi} {
+		if child != nil {
+			result = child.all(result)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,168 @@
+package event
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		event   Event
+		matches bool
+	}{
+		{pattern: "plant/tank", event: Changed("plant/tank"), matches: true},
+		{pattern: "plant/tank", event: Changed("plant/pump"), matches: false},
+		{pattern: "plant/*", event: Changed("plant/tank"), matches: true},
+		{pattern: "plant/*", event: Changed("plant/tank/level"), matches: false},
+		{pattern: "plant/*", event: Action("plant/tank", "fill"), matches: false},
+		{pattern: "*/level", event: Changed("tank/level"), matches: true},
+		{pattern: "plant/**", event: Changed("plant/tank/level"), matches: true},
+		{pattern: "plant/**", event: Changed("plant"), matches: true},
+		{pattern: "plant/**", event: Action("plant/tank", "fill"), matches: true},
+		{pattern: "**/level", event: Changed("plant/tank/level"), matches: true},
+		{pattern: "**/level", event: Changed("plant/tank/volume"), matches: false},
+		{pattern: "plant/tank:*", event: Action("plant/tank", "fill"), matches: true},
+		{pattern: "plant/tank:*", event: Changed("plant/tank"), matches: false},
+		{pattern: "plant/*:fill", event: Action("plant/tank", "fill"), matches: true},
+		{pattern: "plant/*:fill", event: Action("plant/tank", "drain"), matches: false},
+		{pattern: "**:*", event: Action("plant/tank", "fill"), matches: true},
+		{pattern: "**:*", event: Changed("plant/tank"), matches: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+"@"+string(test.event), func(t *testing.T) {
+			tokens, err := parsePattern(test.pattern)
+
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			root := newTrie()
+			target := &subscriber{event: Event(test.pattern)}
+			root.insert(tokens, target)
+
+			matched := root.match(tokenize(string(test.event)), make(map[*subscriber]bool), nil)
+
+			if got := len(matched) == 1; got != test.matches {
+				t.Fatalf("expected match %t, got %t", test.matches, got)
+			}
+		})
+	}
+}
+
+func TestPatternMatchesOnce(t *testing.T) {
+	root := newTrie()
+	target := &subscriber{event: "**/**"}
+
+	tokens, err := parsePattern("**/**")
+
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	root.insert(tokens, target)
+
+	if matched := root.match(tokenize("a/b/c"), make(map[*subscriber]bool), nil); len(matched) != 1 {
+		t.Fatalf("expected a single match, got %d", len(matched))
+	}
+}
+
+func TestPatternRemove(t *testing.T) {
+	root := newTrie()
+	first := make(chan any)
+	second := make(chan any)
+	tokens, _ := parsePattern("plant/*")
+
+	root.insert(tokens, &subscriber{event: "plant/*", listener: first})
+	root.insert(tokens, &subscriber{event: "plant/*", listener: second})
+
+	if removed := root.remove(tokens, first); len(removed) != 1 {
+		t.Fatalf("expected one removed subscriber, got %d", len(removed))
+	}
+
+	matched := root.match(tokenize("plant/tank"), make(map[*subscriber]bool), nil)
+
+	if len(matched) != 1 || matched[0].listener != (chan<- any)(second) {
+		t.Fatalf("expected only the second listener to remain, got %d", len(matched))
+	}
+}
+
+func TestPatternRemovePrunesEmptyNodes(t *testing.T) {
+	root := newTrie()
+	listener := make(chan any)
+	other := make(chan any)
+	patterns := []string{"plant/*/level", "plant/**", "plant/tank:*", "plant/tank/level"}
+
+	for _, pattern := range patterns {
+		tokens, _ := parsePattern(pattern)
+		root.insert(tokens, &subscriber{event: Event(pattern), listener: listener})
+	}
+
+	kept, _ := parsePattern("plant/pump")
+	root.insert(kept, &subscriber{event: "plant/pump", listener: other})
+
+	for _, pattern := range patterns {
+		tokens, _ := parsePattern(pattern)
+
+		if removed := root.remove(tokens, listener); len(removed) != 1 {
+			t.Fatalf("%s: expected one removed subscriber, got %d", pattern, len(removed))
+		}
+	}
+
+	plant := root.children["plant"]
+
+	if plant == nil || len(plant.children) != 1 || plant.children["pump"] == nil || plant.single != nil || plant.multi != nil {
+		t.Fatal("expected only the branch still in use to remain")
+	}
+
+	root.remove(kept, other)
+
+	if !root.empty() {
+		t.Fatalf("expected an empty trie, got %d children", len(root.children))
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	for _, pattern := range []string{"plant/ta*", "plant/**x", "plant/tank:f*"} {
+		if _, err := parsePattern(pattern); !errors.Is(err, ErrInvalidPattern) {
+			t.Fatalf("%s: expected %v, got %v", pattern, ErrInvalidPattern, err)
+		}
+	}
+}
+
+func TestSubscribePattern(t *testing.T) {
+	events := NewEvents(time.Second)
+	listener := make(chan any, 4)
+
+	if err := events.SubscribePattern("plant/**", listener); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	defer events.UnsubscribePattern("plant/**", listener)
+
+	events.Emit(Changed("other/tank"), int32(1))
+	events.Emit(Action("plant/tank", "fill"), int32(2))
+
+	select {
+	case received := <-listener:
+		envelope, ok := received.(Envelope[any])
+
+		if !ok {
+			t.Fatalf("expected an envelope, got %T", received)
+		}
+
+		if envelope.Kind != KindAction || envelope.Resource != "plant/tank" || envelope.Action != "fill" || envelope.Payload != int32(2) {
+			t.Fatalf("unexpected envelope %+v", envelope)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the pattern delivery")
+	}
+
+	select {
+	case received := <-listener:
+		t.Fatalf("unexpected delivery %v", received)
+	default:
+	}
+}
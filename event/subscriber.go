@@ -0,0 +1,168 @@
+package event
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Policy int
+
+type Stat struct {
+	Event    Event  `json:"event"`
+	Policy   Policy `json:"policy"`
+	Capacity int    `json:"capacity"`
+	Pattern  bool   `json:"pattern"`
+	Pending  int    `json:"pending"`
+	Dropped  uint64 `json:"dropped"`
+}
+
+type subscriber struct {
+	event     Event
+	listener  chan<- any
+	pattern   bool
+	enveloped bool
+	policy    Policy
+	timeout   time.Duration
+	queue     chan any
+	dropped   atomic.Uint64
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+const (
+	Block Policy = iota
+	DropNewest
+	DropOldest
+	Coalesce
+)
+
+const DefaultCapacity = 64
+
+func newSubscriber(event Event, listener chan<- any, policy Policy, capacity int, timeout time.Duration) *subscriber {
+	if policy == Coalesce || capacity < 1 {
+		capacity = 1
+	}
+
+	subscriber := &subscriber{
+		event:     event,
+		listener:  listener,
+		pattern:   false,
+		enveloped: false,
+		policy:    policy,
+		timeout:   timeout,
+		queue:     make(chan any, capacity),
+		dropped:   atomic.Uint64{},
+		quit:      make(chan struct{}),
+		wg:        sync.WaitGroup{},
+	}
+
+	subscriber.wg.Add(1)
+	go subscriber.loop()
+
+	return subscriber
+}
+
+func (policy Policy) String() string {
+	switch policy {
+	case Block:
+		return "block"
+	case DropNewest:
+		return "drop_newest"
+	case DropOldest:
+		return "drop_oldest"
+	case Coalesce:
+		return "coalesce"
+	}
+
+	return "unknown"
+}
+
+func (policy Policy) MarshalText() ([]byte, error) {
+	return []byte(policy.String()), nil
+}
+
+func (subscriber *subscriber) loop() {
+	defer subscriber.wg.Done()
+
+	for {
+		select {
+		case payload := <-subscriber.queue:
+			select {
+			case subscriber.listener <- payload:
+			case <-subscriber.quit:
+				return
+			}
+		case <-subscriber.quit:
+			return
+		}
+	}
+}
+
+func (subscriber *subscriber) push(payload any) {
+	switch subscriber.policy {
+	case Block:
+		subscriber.block(payload)
+	case DropNewest:
+		select {
+		case subscriber.queue <- payload:
+		default:
+			subscriber.dropped.Add(1)
+		}
+	case DropOldest, Coalesce:
+		for {
+			select {
+			case subscriber.queue <- payload:
+				return
+			default:
+			}
+
+			select {
+			case <-subscriber.queue:
+				subscriber.dropped.Add(1)
+			default:
+			}
+		}
+	}
+}
+
+func (subscriber *subscriber) block(payload any) {
+	select {
+	case subscriber.queue <- payload:
+		return
+	case <-subscriber.quit:
+		return
+	default:
+	}
+
+	if subscriber.timeout <= 0 {
+		subscriber.dropped.Add(1)
+		return
+	}
+
+	timer := time.NewTimer(subscriber.timeout)
+	defer timer.Stop()
+
+	select {
+	case subscriber.queue <- payload:
+	case <-subscriber.quit:
+	case <-timer.C:
+		subscriber.dropped.Add(1)
+	}
+}
+
+func (subscriber *subscriber) stop() {
+	close(subscriber.quit)
+	subscriber.wg.Wait()
+}
+
+func (subscriber *subscriber) stat() Stat {
+	return Stat{
+		Event:    subscriber.event,
+		Policy:   subscriber.policy,
+		Capacity: cap(subscriber.queue),
+		Pattern:  subscriber.pattern,
+		Pending:  len(subscriber.queue),
+		Dropped:  subscriber.dropped.Load(),
+	}
+}
@@ -0,0 +1,155 @@
+package event
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func hold(t *testing.T, subscriber *subscriber, payload any) {
+	t.Helper()
+
+	subscriber.push(payload)
+	deadline := time.Now().Add(time.Second)
+
+	for len(subscriber.queue) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the subscriber never picked up the first payload")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func drain(t *testing.T, listener chan any, count int) []any {
+	t.Helper()
+
+	var received []any
+
+	for range count {
+		select {
+		case payload := <-listener:
+			received = append(received, payload)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after receiving %v", received)
+		}
+	}
+
+	select {
+	case payload := <-listener:
+		t.Fatalf("unexpected delivery %v after %v", payload, received)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	return received
+}
+
+func TestSubscriberPolicies(t *testing.T) {
+	tests := []struct {
+		policy    Policy
+		capacity  int
+		timeout   time.Duration
+		pushed    []any
+		delivered []any
+		dropped   uint64
+	}{
+		{policy: Block, capacity: 1, timeout: 0, pushed: []any{1, 2, 3}, delivered: []any{1, 2}, dropped: 1},
+		{policy: Block, capacity: 1, timeout: -time.Second, pushed: []any{1, 2, 3}, delivered: []any{1, 2}, dropped: 1},
+		{policy: Block, capacity: 1, timeout: 5 * time.Millisecond, pushed: []any{1, 2, 3, 4}, delivered: []any{1, 2}, dropped: 2},
+		{policy: DropNewest, capacity: 2, timeout: time.Second, pushed: []any{1, 2, 3, 4, 5}, delivered: []any{1, 2, 3}, dropped: 2},
+		{policy: DropOldest, capacity: 2, timeout: time.Second, pushed: []any{1, 2, 3, 4, 5}, delivered: []any{1, 4, 5}, dropped: 2},
+		{policy: Coalesce, capacity: 8, timeout: time.Second, pushed: []any{1, 2, 3, 4}, delivered: []any{1, 4}, dropped: 2},
+	}
+
+	for _, test := range tests {
+		listener := make(chan any)
+		subscriber := newSubscriber(Changed("level"), listener, test.policy, test.capacity, test.timeout)
+
+		hold(t, subscriber, test.pushed[0])
+
+		for _, payload := range test.pushed[1:] {
+			subscriber.push(payload)
+		}
+
+		if dropped := subscriber.dropped.Load(); dropped != test.dropped {
+			t.Fatalf("%s: expected %d dropped, got %d", test.policy, test.dropped, dropped)
+		}
+
+		if delivered := drain(t, listener, len(test.delivered)); !slices.Equal(delivered, test.delivered) {
+			t.Fatalf("%s: expected %v, got %v", test.policy, test.delivered, delivered)
+		}
+
+		subscriber.stop()
+	}
+}
+
+func TestBlockWithoutTimeoutDoesNotWait(t *testing.T) {
+	listener := make(chan any)
+	subscriber := newSubscriber(Changed("level"), listener, Block, 1, 0)
+	defer subscriber.stop()
+
+	hold(t, subscriber, 1)
+	subscriber.push(2)
+
+	pushed := make(chan struct{})
+
+	go func() {
+		subscriber.push(3)
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+	case <-time.After(time.Second):
+		t.Fatal("expected a zero timeout to drop instead of blocking")
+	}
+}
+
+func TestBlockWaitsForRoom(t *testing.T) {
+	listener := make(chan any)
+	subscriber := newSubscriber(Changed("level"), listener, Block, 1, time.Second)
+	defer subscriber.stop()
+
+	hold(t, subscriber, 1)
+	subscriber.push(2)
+
+	go subscriber.push(3)
+
+	if delivered := drain(t, listener, 3); !slices.Equal(delivered, []any{1, 2, 3}) {
+		t.Fatalf("expected every payload once there was room, got %v", delivered)
+	}
+
+	if dropped := subscriber.dropped.Load(); dropped != 0 {
+		t.Fatalf("expected nothing dropped, got %d", dropped)
+	}
+}
+
+func TestDroppedCountsPerListener(t *testing.T) {
+	events := NewEvents(time.Second)
+	slow := make(chan any)
+	fast := make(chan any, 8)
+
+	events.SubscribeWith(Changed("level"), slow, DropNewest, 1)
+	events.SubscribeWith(Changed("level"), fast, DropNewest, 8)
+
+	defer events.Unsubscribe(Changed("level"), slow)
+	defer events.Unsubscribe(Changed("level"), fast)
+
+	for i := range 5 {
+		events.Emit(Changed("level"), i)
+	}
+
+	drain(t, fast, 5)
+
+	if dropped := events.Dropped(Changed("level"), fast); dropped != 0 {
+		t.Fatalf("expected the fast listener to drop nothing, got %d", dropped)
+	}
+
+	if dropped := events.Dropped(Changed("level"), slow); dropped < 3 {
+		t.Fatalf("expected the slow listener to drop at least 3, got %d", dropped)
+	}
+
+	if stats := events.Stats(); len(stats) != 2 {
+		t.Fatalf("expected a stat per subscriber, got %v", stats)
+	}
+}
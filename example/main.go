@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"time"
 
 	"github.com/studiolambda/immersim"
+	"github.com/studiolambda/immersim/clock"
 	"github.com/studiolambda/immersim/event"
 	"github.com/studiolambda/immersim/resource"
 	"github.com/studiolambda/immersim/storage"
@@ -33,10 +37,16 @@ func main() {
 		"inc":      resource.NewIncrement[int32](0, 1, 100*time.Millisecond),
 	})
 
-	app := immersim.NewApplication(storage, events)
+	app := immersim.NewApplication(storage, events, clock.NewReal())
 
-	app.Start()
-	defer app.Stop()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := app.Start(ctx); err != nil {
+		panic(err)
+	}
+
+	defer app.Stop(context.Background())
 
 	go func() {
 		time.Sleep(5 * time.Second)
@@ -48,6 +58,12 @@ func main() {
 	}()
 
 	for {
+		select {
+		case <-app.Done():
+			return
+		default:
+		}
+
 		tmp, _ := storage.Read("tmp")
 		setpoint, _ := storage.Read("setpoint")
 		above, _ := storage.Read("above")
@@ -0,0 +1,36 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Error struct {
+	Position int
+	Err      error
+}
+
+var (
+	ErrSyntax           = errors.New("syntax error")
+	ErrType             = errors.New("type error")
+	ErrUnknownReference = errors.New("unknown reference")
+	ErrUnknownFunction  = errors.New("unknown function")
+	ErrArguments        = errors.New("wrong number of arguments")
+	ErrDivisionByZero   = errors.New("division by zero")
+)
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("position %d: %s", err.Position, err.Err)
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+func fail(offset int, err error, format string, arguments ...any) error {
+	if format == "" {
+		return &Error{Position: offset + 1, Err: err}
+	}
+
+	return &Error{Position: offset + 1, Err: fmt.Errorf("%w: %s", err, fmt.Sprintf(format, arguments...))}
+}
@@ -0,0 +1,215 @@
+package expression
+
+import (
+	"fmt"
+)
+
+type function struct {
+	minimum  int
+	maximum  int
+	check    func(call *call, types []Type) (Type, error)
+	evaluate func(call *call, resolve func(name string) (any, error)) (any, error)
+}
+
+var functions = map[string]function{
+	"min": {
+		minimum:  2,
+		maximum:  -1,
+		check:    checkNumeric,
+		evaluate: extremum(true),
+	},
+	"max": {
+		minimum:  2,
+		maximum:  -1,
+		check:    checkNumeric,
+		evaluate: extremum(false),
+	},
+	"abs": {
+		minimum:  1,
+		maximum:  1,
+		check:    checkNumeric,
+		evaluate: evaluateAbs,
+	},
+	"clamp": {
+		minimum:  3,
+		maximum:  3,
+		check:    checkNumeric,
+		evaluate: evaluateClamp,
+	},
+	"if": {
+		minimum:  3,
+		maximum:  3,
+		check:    checkIf,
+		evaluate: evaluateIf,
+	},
+}
+
+func (function function) arity() string {
+	switch {
+	case function.maximum < 0:
+		return fmt.Sprintf("at least %d arguments", function.minimum)
+	case function.minimum == function.maximum && function.minimum == 1:
+		return "1 argument"
+	case function.minimum == function.maximum:
+		return fmt.Sprintf("%d arguments", function.minimum)
+	}
+
+	return fmt.Sprintf("between %d and %d arguments", function.minimum, function.maximum)
+}
+
+func checkNumeric(call *call, types []Type) (Type, error) {
+	result := Int
+
+	for i, kind := range types {
+		if !kind.numeric() {
+			return Invalid, fail(call.offset, ErrType, "argument %d of %s must be numeric, got %s", i+1, call.name, kind)
+		}
+
+		result = promote(result, kind)
+	}
+
+	return result, nil
+}
+
+func checkIf(call *call, types []Type) (Type, error) {
+	if types[0] != Bool {
+		return Invalid, fail(call.offset, ErrType, "condition of if must be bool, got %s", types[0])
+	}
+
+	switch {
+	case types[1] == types[2]:
+		return types[1], nil
+	case types[1].numeric() && types[2].numeric():
+		return Float, nil
+	}
+
+	return Invalid, fail(call.offset, ErrType, "branches of if have incompatible types %s and %s", types[1], types[2])
+}
+
+func evaluateArguments(call *call, resolve func(name string) (any, error)) ([]any, bool, error) {
+	values := make([]any, len(call.arguments))
+	integers := true
+
+	for i, argument := range call.arguments {
+		value, err := argument.evaluate(resolve)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch value.(type) {
+		case int32:
+		case float32:
+			integers = false
+		default:
+			return nil, false, fail(call.offset, ErrType, "argument %d of %s must be numeric, got %T", i+1, call.name, value)
+		}
+
+		values[i] = value
+	}
+
+	return values, integers, nil
+}
+
+func extremum(minimum bool) func(call *call, resolve func(name string) (any, error)) (any, error) {
+	return func(call *call, resolve func(name string) (any, error)) (any, error) {
+		values, integers, err := evaluateArguments(call, resolve)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if integers {
+			best := values[0].(int32)
+
+			for _, value := range values[1:] {
+				if better(minimum, value.(int32), best) {
+					best = value.(int32)
+				}
+			}
+
+			return best, nil
+		}
+
+		best, _ := toFloat(values[0])
+
+		for _, value := range values[1:] {
+			if candidate, _ := toFloat(value); better(minimum, candidate, best) {
+				best = candidate
+			}
+		}
+
+		return best, nil
+	}
+}
+
+func better[T int32 | float32](minimum bool, candidate T, best T) bool {
+	if minimum {
+		return candidate < best
+	}
+
+	return candidate > best
+}
+
+func evaluateAbs(call *call, resolve func(name string) (any, error)) (any, error) {
+	values, _, err := evaluateArguments(call, resolve)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := values[0].(type) {
+	case int32:
+		if value < 0 {
+			return -value, nil
+		}
+
+		return value, nil
+	case float32:
+		if value < 0 {
+			return -value, nil
+		}
+
+		return value, nil
+	}
+
+	return nil, fail(call.offset, ErrType, "argument of abs must be numeric")
+}
+
+func evaluateClamp(call *call, resolve func(name string) (any, error)) (any, error) {
+	values, integers, err := evaluateArguments(call, resolve)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if integers {
+		return min(max(values[0].(int32), values[1].(int32)), values[2].(int32)), nil
+	}
+
+	value, _ := toFloat(values[0])
+	low, _ := toFloat(values[1])
+	high, _ := toFloat(values[2])
+
+	return min(max(value, low), high), nil
+}
+
+func evaluateIf(call *call, resolve func(name string) (any, error)) (any, error) {
+	condition, err := call.arguments[0].evaluate(resolve)
+
+	if err != nil {
+		return nil, err
+	}
+
+	chosen, ok := condition.(bool)
+
+	if !ok {
+		return nil, fail(call.offset, ErrType, "condition of if must be bool, got %T", condition)
+	}
+
+	if chosen {
+		return call.arguments[1].evaluate(resolve)
+	}
+
+	return call.arguments[2].evaluate(resolve)
+}
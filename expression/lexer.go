@@ -0,0 +1,104 @@
+package expression
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenNumber
+	tokenIdentifier
+	tokenReference
+	tokenOperator
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "+", "-", "*", "/", "%", "<", ">", "!", "(", ")", ","}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+
+	for offset := 0; offset < len(source); {
+		character := rune(source[offset])
+
+		switch {
+		case unicode.IsSpace(character):
+			offset++
+		case unicode.IsDigit(character) || character == '.' && offset+1 < len(source) && unicode.IsDigit(rune(source[offset+1])):
+			start := offset
+
+			for offset < len(source) && (unicode.IsDigit(rune(source[offset])) || source[offset] == '.') {
+				offset++
+			}
+
+			if offset < len(source) && (source[offset] == 'e' || source[offset] == 'E') {
+				offset++
+
+				if offset < len(source) && (source[offset] == '+' || source[offset] == '-') {
+					offset++
+				}
+
+				for offset < len(source) && unicode.IsDigit(rune(source[offset])) {
+					offset++
+				}
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:offset], offset: start})
+		case isIdentifierStart(character):
+			start := offset
+
+			for offset < len(source) && isIdentifierPart(rune(source[offset])) {
+				offset++
+			}
+
+			tokens = append(tokens, token{kind: tokenIdentifier, text: source[start:offset], offset: start})
+		case character == '`':
+			end := strings.IndexByte(source[offset+1:], '`')
+
+			if end < 0 {
+				return nil, fail(offset, ErrSyntax, "unterminated quoted reference")
+			}
+
+			if end == 0 {
+				return nil, fail(offset, ErrSyntax, "empty quoted reference")
+			}
+
+			tokens = append(tokens, token{kind: tokenReference, text: source[offset+1 : offset+1+end], offset: offset})
+			offset += end + 2
+		default:
+			matched := false
+
+			for _, operator := range operators {
+				if strings.HasPrefix(source[offset:], operator) {
+					tokens = append(tokens, token{kind: tokenOperator, text: operator, offset: offset})
+					offset += len(operator)
+					matched = true
+
+					break
+				}
+			}
+
+			if !matched {
+				return nil, fail(offset, ErrSyntax, "unexpected character %q", character)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, offset: len(source)}), nil
+}
+
+func isIdentifierStart(character rune) bool {
+	return character == '_' || unicode.IsLetter(character)
+}
+
+func isIdentifierPart(character rune) bool {
+	return isIdentifierStart(character) || unicode.IsDigit(character) || character == '.'
+}
@@ -0,0 +1,306 @@
+package expression
+
+import (
+	"math"
+)
+
+type node interface {
+	check(resolve func(name string) (Type, bool)) (Type, error)
+	evaluate(resolve func(name string) (any, error)) (any, error)
+}
+
+type literal struct {
+	offset int
+	value  any
+}
+
+type reference struct {
+	offset int
+	name   string
+}
+
+type unary struct {
+	offset   int
+	operator string
+	operand  node
+}
+
+type binary struct {
+	offset   int
+	operator string
+	left     node
+	right    node
+}
+
+type call struct {
+	offset    int
+	name      string
+	arguments []node
+}
+
+func (literal *literal) check(resolve func(name string) (Type, bool)) (Type, error) {
+	kind, _ := TypeOf(literal.value)
+
+	return kind, nil
+}
+
+func (literal *literal) evaluate(resolve func(name string) (any, error)) (any, error) {
+	return literal.value, nil
+}
+
+func (reference *reference) check(resolve func(name string) (Type, bool)) (Type, error) {
+	kind, ok := resolve(reference.name)
+
+	if !ok {
+		return Invalid, fail(reference.offset, ErrUnknownReference, "%s", reference.name)
+	}
+
+	if kind == Invalid {
+		return Invalid, fail(reference.offset, ErrType, "%s has no supported type", reference.name)
+	}
+
+	return kind, nil
+}
+
+func (reference *reference) evaluate(resolve func(name string) (any, error)) (any, error) {
+	value, err := resolve(reference.name)
+
+	if err != nil {
+		return nil, &Error{Position: reference.offset + 1, Err: err}
+	}
+
+	if _, ok := TypeOf(value); !ok {
+		return nil, fail(reference.offset, ErrType, "%s has unsupported type %T", reference.name, value)
+	}
+
+	return value, nil
+}
+
+func (unary *unary) check(resolve func(name string) (Type, bool)) (Type, error) {
+	operand, err := unary.operand.check(resolve)
+
+	if err != nil {
+		return Invalid, err
+	}
+
+	if unary.operator == "!" {
+		if operand != Bool {
+			return Invalid, fail(unary.offset, ErrType, "operator ! expects bool, got %s", operand)
+		}
+
+		return Bool, nil
+	}
+
+	if !operand.numeric() {
+		return Invalid, fail(unary.offset, ErrType, "operator - expects a number, got %s", operand)
+	}
+
+	return operand, nil
+}
+
+func (unary *unary) evaluate(resolve func(name string) (any, error)) (any, error) {
+	operand, err := unary.operand.evaluate(resolve)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := operand.(type) {
+	case bool:
+		if unary.operator == "!" {
+			return !value, nil
+		}
+	case int32:
+		if unary.operator == "-" {
+			return -value, nil
+		}
+	case float32:
+		if unary.operator == "-" {
+			return -value, nil
+		}
+	}
+
+	return nil, fail(unary.offset, ErrType, "operator %s cannot be applied to %T", unary.operator, operand)
+}
+
+func (binary *binary) check(resolve func(name string) (Type, bool)) (Type, error) {
+	left, err := binary.left.check(resolve)
+
+	if err != nil {
+		return Invalid, err
+	}
+
+	right, err := binary.right.check(resolve)
+
+	if err != nil {
+		return Invalid, err
+	}
+
+	switch binary.operator {
+	case "&&", "||":
+		if left != Bool || right != Bool {
+			return Invalid, fail(binary.offset, ErrType, "operator %s expects bool operands, got %s and %s", binary.operator, left, right)
+		}
+
+		return Bool, nil
+	case "==", "!=":
+		if left.numeric() && right.numeric() || left == Bool && right == Bool {
+			return Bool, nil
+		}
+
+		return Invalid, fail(binary.offset, ErrType, "cannot compare %s with %s", left, right)
+	case "<", "<=", ">", ">=":
+		if !left.numeric() || !right.numeric() {
+			return Invalid, fail(binary.offset, ErrType, "operator %s expects numeric operands, got %s and %s", binary.operator, left, right)
+		}
+
+		return Bool, nil
+	}
+
+	if !left.numeric() || !right.numeric() {
+		return Invalid, fail(binary.offset, ErrType, "operator %s expects numeric operands, got %s and %s", binary.operator, left, right)
+	}
+
+	return promote(left, right), nil
+}
+
+func (binary *binary) evaluate(resolve func(name string) (any, error)) (any, error) {
+	left, err := binary.left.evaluate(resolve)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if condition, ok := left.(bool); ok && (binary.operator == "&&" && !condition || binary.operator == "||" && condition) {
+		return condition, nil
+	}
+
+	right, err := binary.right.evaluate(resolve)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if leftBool, ok := left.(bool); ok {
+		rightBool, ok := right.(bool)
+
+		if !ok {
+			return nil, fail(binary.offset, ErrType, "operator %s cannot mix bool and %T", binary.operator, right)
+		}
+
+		switch binary.operator {
+		case "&&", "||":
+			return rightBool, nil
+		case "==":
+			return leftBool == rightBool, nil
+		case "!=":
+			return leftBool != rightBool, nil
+		}
+
+		return nil, fail(binary.offset, ErrType, "operator %s cannot be applied to bool", binary.operator)
+	}
+
+	leftInt, leftIsInt := left.(int32)
+	rightInt, rightIsInt := right.(int32)
+
+	if leftIsInt && rightIsInt {
+		return binary.integer(leftInt, rightInt)
+	}
+
+	leftFloat, leftOk := toFloat(left)
+	rightFloat, rightOk := toFloat(right)
+
+	if !leftOk || !rightOk {
+		return nil, fail(binary.offset, ErrType, "operator %s cannot be applied to %T and %T", binary.operator, left, right)
+	}
+
+	return binary.float(leftFloat, rightFloat)
+}
+
+func (binary *binary) integer(left int32, right int32) (any, error) {
+	switch binary.operator {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/", "%":
+		if right == 0 {
+			return nil, fail(binary.offset, ErrDivisionByZero, "")
+		}
+
+		if binary.operator == "/" {
+			return left / right, nil
+		}
+
+		return left % right, nil
+	}
+
+	return compare(binary.operator, left, right), nil
+}
+
+func (binary *binary) float(left float32, right float32) (any, error) {
+	switch binary.operator {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		return left / right, nil
+	case "%":
+		return float32(math.Mod(float64(left), float64(right))), nil
+	}
+
+	return compare(binary.operator, left, right), nil
+}
+
+func compare[T int32 | float32](operator string, left T, right T) bool {
+	switch operator {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	case ">":
+		return left > right
+	}
+
+	return left >= right
+}
+
+func toFloat(value any) (float32, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float32(v), true
+	case float32:
+		return v, true
+	}
+
+	return 0, false
+}
+
+func (call *call) check(resolve func(name string) (Type, bool)) (Type, error) {
+	types := make([]Type, len(call.arguments))
+
+	for i, argument := range call.arguments {
+		kind, err := argument.check(resolve)
+
+		if err != nil {
+			return Invalid, err
+		}
+
+		types[i] = kind
+	}
+
+	return functions[call.name].check(call, types)
+}
+
+func (call *call) evaluate(resolve func(name string) (any, error)) (any, error) {
+	return functions[call.name].evaluate(call, resolve)
+}
@@ -0,0 +1,272 @@
+package expression
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Expression struct {
+	source     string
+	root       node
+	references []string
+}
+
+type parser struct {
+	tokens     []token
+	current    int
+	references []string
+}
+
+var precedences = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3,
+	"!=": 3,
+	"<":  4,
+	"<=": 4,
+	">":  4,
+	">=": 4,
+	"+":  5,
+	"-":  5,
+	"*":  6,
+	"/":  6,
+	"%":  6,
+}
+
+func Parse(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &parser{
+		tokens:     tokens,
+		current:    0,
+		references: nil,
+	}
+
+	root, err := parser.parseBinary(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if next := parser.peek(); next.kind != tokenEnd {
+		return nil, fail(next.offset, ErrSyntax, "unexpected %q", next.text)
+	}
+
+	slices.Sort(parser.references)
+
+	return &Expression{
+		source:     source,
+		root:       root,
+		references: slices.Compact(parser.references),
+	}, nil
+}
+
+func MustParse(source string) *Expression {
+	expression, err := Parse(source)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return expression
+}
+
+func (expression *Expression) String() string {
+	return expression.source
+}
+
+func (expression *Expression) References() []string {
+	return slices.Clone(expression.references)
+}
+
+func (expression *Expression) Check(resolve func(name string) (Type, bool)) (Type, error) {
+	return expression.root.check(resolve)
+}
+
+func (expression *Expression) Evaluate(resolve func(name string) (any, error)) (any, error) {
+	return expression.root.evaluate(resolve)
+}
+
+func (parser *parser) peek() token {
+	return parser.tokens[parser.current]
+}
+
+func (parser *parser) next() token {
+	token := parser.tokens[parser.current]
+
+	if token.kind != tokenEnd {
+		parser.current++
+	}
+
+	return token
+}
+
+func (parser *parser) expect(text string) (token, error) {
+	token := parser.next()
+
+	if token.kind != tokenOperator || token.text != text {
+		if token.kind == tokenEnd {
+			return token, fail(token.offset, ErrSyntax, "expected %q but reached end of expression", text)
+		}
+
+		return token, fail(token.offset, ErrSyntax, "expected %q but found %q", text, token.text)
+	}
+
+	return token, nil
+}
+
+func (parser *parser) parseBinary(minimum int) (node, error) {
+	left, err := parser.parseUnary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		operator := parser.peek()
+		precedence, ok := precedences[operator.text]
+
+		if operator.kind != tokenOperator || !ok || precedence < minimum {
+			return left, nil
+		}
+
+		parser.next()
+
+		right, err := parser.parseBinary(precedence + 1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binary{offset: operator.offset, operator: operator.text, left: left, right: right}
+	}
+}
+
+func (parser *parser) parseUnary() (node, error) {
+	if token := parser.peek(); token.kind == tokenOperator && (token.text == "-" || token.text == "!") {
+		parser.next()
+
+		operand, err := parser.parseUnary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &unary{offset: token.offset, operator: token.text, operand: operand}, nil
+	}
+
+	return parser.parsePrimary()
+}
+
+func (parser *parser) parsePrimary() (node, error) {
+	token := parser.next()
+
+	switch token.kind {
+	case tokenNumber:
+		return parseNumber(token)
+	case tokenReference:
+		parser.references = append(parser.references, token.text)
+
+		return &reference{offset: token.offset, name: token.text}, nil
+	case tokenIdentifier:
+		switch token.text {
+		case "true":
+			return &literal{offset: token.offset, value: true}, nil
+		case "false":
+			return &literal{offset: token.offset, value: false}, nil
+		}
+
+		if next := parser.peek(); next.kind == tokenOperator && next.text == "(" {
+			return parser.parseCall(token)
+		}
+
+		parser.references = append(parser.references, token.text)
+
+		return &reference{offset: token.offset, name: token.text}, nil
+	case tokenOperator:
+		if token.text == "(" {
+			inner, err := parser.parseBinary(1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := parser.expect(")"); err != nil {
+				return nil, err
+			}
+
+			return inner, nil
+		}
+
+		return nil, fail(token.offset, ErrSyntax, "unexpected %q", token.text)
+	}
+
+	return nil, fail(token.offset, ErrSyntax, "unexpected end of expression")
+}
+
+func (parser *parser) parseCall(name token) (node, error) {
+	parser.next()
+
+	call := &call{offset: name.offset, name: name.text, arguments: nil}
+
+	if next := parser.peek(); next.kind == tokenOperator && next.text == ")" {
+		parser.next()
+	} else {
+		for {
+			argument, err := parser.parseBinary(1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			call.arguments = append(call.arguments, argument)
+
+			separator := parser.next()
+
+			if separator.kind == tokenOperator && separator.text == ")" {
+				break
+			}
+
+			if separator.kind != tokenOperator || separator.text != "," {
+				return nil, fail(separator.offset, ErrSyntax, "expected \",\" or \")\" in call to %s", name.text)
+			}
+		}
+	}
+
+	function, ok := functions[call.name]
+
+	if !ok {
+		return nil, fail(name.offset, ErrUnknownFunction, "%s", name.text)
+	}
+
+	if len(call.arguments) < function.minimum || function.maximum >= 0 && len(call.arguments) > function.maximum {
+		return nil, fail(name.offset, ErrArguments, "%s expects %s", name.text, function.arity())
+	}
+
+	return call, nil
+}
+
+func parseNumber(token token) (node, error) {
+	if !strings.ContainsAny(token.text, ".eE") {
+		value, err := strconv.ParseInt(token.text, 10, 32)
+
+		if err != nil {
+			return nil, fail(token.offset, ErrSyntax, "invalid integer %s", token.text)
+		}
+
+		return &literal{offset: token.offset, value: int32(value)}, nil
+	}
+
+	value, err := strconv.ParseFloat(token.text, 32)
+
+	if err != nil {
+		return nil, fail(token.offset, ErrSyntax, "invalid number %s", token.text)
+	}
+
+	return &literal{offset: token.offset, value: float32(value)}, nil
+}
@@ -0,0 +1,179 @@
+package expression
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func resolver(values map[string]any) (func(name string) (Type, bool), func(name string) (any, error)) {
+	check := func(name string) (Type, bool) {
+		value, ok := values[name]
+
+		if !ok {
+			return Invalid, false
+		}
+
+		kind, _ := TypeOf(value)
+
+		return kind, true
+	}
+
+	evaluate := func(name string) (any, error) {
+		value, ok := values[name]
+
+		if !ok {
+			return nil, ErrUnknownReference
+		}
+
+		return value, nil
+	}
+
+	return check, evaluate
+}
+
+func TestEvaluate(t *testing.T) {
+	values := map[string]any{
+		"tank.level": float32(2.5),
+		"pump/speed": int32(4),
+		"running":    true,
+	}
+
+	tests := []struct {
+		source string
+		kind   Type
+		value  any
+	}{
+		{source: "1 + 2 * 3", kind: Int, value: int32(7)},
+		{source: "(1 + 2) * 3", kind: Int, value: int32(9)},
+		{source: "7 / 2", kind: Int, value: int32(3)},
+		{source: "7 % 4", kind: Int, value: int32(3)},
+		{source: "-3 + 1.5", kind: Float, value: float32(-1.5)},
+		{source: "tank.level * 2", kind: Float, value: float32(5)},
+		{source: "`pump/speed` + 1", kind: Int, value: int32(5)},
+		{source: "running && tank.level > 2", kind: Bool, value: true},
+		{source: "!running || false", kind: Bool, value: false},
+		{source: "min(3, 1, 2)", kind: Int, value: int32(1)},
+		{source: "max(16777216, 16777217)", kind: Int, value: int32(16777217)},
+		{source: "min(16777217, 16777216)", kind: Int, value: int32(16777216)},
+		{source: "max(1, 2.5)", kind: Float, value: float32(2.5)},
+		{source: "abs(-4)", kind: Int, value: int32(4)},
+		{source: "clamp(tank.level, 0, 1)", kind: Float, value: float32(1)},
+		{source: "if(running, 1, 2)", kind: Int, value: int32(1)},
+	}
+
+	check, evaluate := resolver(values)
+
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			parsed, err := Parse(test.source)
+
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			kind, err := parsed.Check(check)
+
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+
+			if kind != test.kind {
+				t.Fatalf("expected type %s, got %s", test.kind, kind)
+			}
+
+			value, err := parsed.Evaluate(evaluate)
+
+			if err != nil {
+				t.Fatalf("evaluate: %v", err)
+			}
+
+			if value != test.value {
+				t.Fatalf("expected %v (%T), got %v (%T)", test.value, test.value, value, value)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	parsed := MustParse("b + `a/x` + b * min(c, 1)")
+
+	if references := parsed.References(); !slices.Equal(references, []string{"a/x", "b", "c"}) {
+		t.Fatalf("unexpected references %v", references)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		source   string
+		err      error
+		position int
+	}{
+		{source: "1 +", err: ErrSyntax, position: 4},
+		{source: "(1 + 2", err: ErrSyntax, position: 7},
+		{source: "1 2", err: ErrSyntax, position: 3},
+		{source: "1 # 2", err: ErrSyntax, position: 3},
+		{source: "`unterminated", err: ErrSyntax, position: 1},
+		{source: "``", err: ErrSyntax, position: 1},
+		{source: "1 + abs(1, 2)", err: ErrArguments, position: 5},
+		{source: "min(1)", err: ErrArguments, position: 1},
+		{source: "sqrt(4)", err: ErrUnknownFunction, position: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			_, err := Parse(test.source)
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+
+			var positioned *Error
+
+			if !errors.As(err, &positioned) || positioned.Position != test.position {
+				t.Fatalf("expected position %d, got %v", test.position, err)
+			}
+		})
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		err    error
+	}{
+		{source: "missing + 1", err: ErrUnknownReference},
+		{source: "running + 1", err: ErrType},
+		{source: "1 && running", err: ErrType},
+		{source: "if(1, 2, 3)", err: ErrType},
+	}
+
+	check, _ := resolver(map[string]any{"running": true})
+
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			parsed, err := Parse(test.source)
+
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			if _, err := parsed.Check(check); !errors.Is(err, test.err) {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestDivisionByZero(t *testing.T) {
+	check, evaluate := resolver(map[string]any{"divisor": int32(0)})
+	parsed := MustParse("10 / divisor")
+
+	if _, err := parsed.Check(check); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if _, err := parsed.Evaluate(evaluate); !errors.Is(err, ErrDivisionByZero) {
+		t.Fatalf("expected %v, got %v", ErrDivisionByZero, err)
+	}
+}
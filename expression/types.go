@@ -0,0 +1,74 @@
+package expression
+
+type Type int
+
+const (
+	Invalid Type = iota
+	Int
+	Float
+	Bool
+)
+
+func (kind Type) String() string {
+	switch kind {
+	case Int:
+		return "int32"
+	case Float:
+		return "float32"
+	case Bool:
+		return "bool"
+	}
+
+	return "invalid"
+}
+
+func (kind Type) numeric() bool {
+	return kind == Int || kind == Float
+}
+
+func TypeOf(value any) (Type, bool) {
+	switch value.(type) {
+	case int32:
+		return Int, true
+	case float32:
+		return Float, true
+	case bool:
+		return Bool, true
+	}
+
+	return Invalid, false
+}
+
+func Assignable(from Type, to Type) bool {
+	return from == to || from == Int && to == Float
+}
+
+func Convert(value any, to Type) (any, bool) {
+	switch v := value.(type) {
+	case int32:
+		switch to {
+		case Int:
+			return v, true
+		case Float:
+			return float32(v), true
+		}
+	case float32:
+		if to == Float {
+			return v, true
+		}
+	case bool:
+		if to == Bool {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func promote(left Type, right Type) Type {
+	if left == Int && right == Int {
+		return Int
+	}
+
+	return Float
+}
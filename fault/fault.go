@@ -0,0 +1,198 @@
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/studiolambda/immersim/quality"
+)
+
+type Kind string
+
+type Fault struct {
+	Resource    string          `json:"resource"`
+	Kind        Kind            `json:"kind"`
+	Value       any             `json:"value,omitempty"`
+	Quality     quality.Quality `json:"quality,omitempty"`
+	Magnitude   float64         `json:"magnitude,omitempty"`
+	Probability float64         `json:"probability,omitempty"`
+	Latency     time.Duration   `json:"latency,omitempty"`
+	Start       time.Duration   `json:"start,omitempty"`
+	Duration    time.Duration   `json:"duration,omitempty"`
+}
+
+type ErrorPayload struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	Error    string `json:"error"`
+}
+
+const (
+	Stuck     Kind = "stuck"
+	Frozen    Kind = "frozen"
+	Spike     Kind = "spike"
+	Dropout   Kind = "dropout"
+	WrongType Kind = "wrong_type"
+	Latency   Kind = "latency"
+)
+
+const (
+	ActionPrefix       = "fault."
+	ClearAction        = ActionPrefix + "clear"
+	ErrorAction        = ActionPrefix + "error"
+	defaultProbability = 0.1
+)
+
+var (
+	ErrDropout         = errors.New("resource dropped out")
+	ErrUnknownKind     = errors.New("unknown fault kind")
+	ErrUnknownResource = errors.New("unknown resource")
+	ErrInvalidPayload  = errors.New("invalid fault payload")
+)
+
+var kinds = []Kind{Stuck, Frozen, Spike, Dropout, WrongType, Latency}
+
+var qualities = map[Kind]quality.Quality{
+	Stuck:     quality.BadSensorFailure,
+	Frozen:    quality.UncertainLastUsableValue,
+	Spike:     quality.UncertainSensorNotAccurate,
+	Dropout:   quality.BadNotConnected,
+	WrongType: quality.BadConfigurationError,
+	Latency:   quality.Uncertain,
+}
+
+func decode(resource string, kind Kind, payload any) (Fault, error) {
+	fault := Fault{Resource: resource, Kind: kind}
+
+	switch value := payload.(type) {
+	case nil:
+	case Fault:
+		fault = value
+		fault.Resource = resource
+		fault.Kind = kind
+	case map[string]any:
+		var err error
+
+		fault.Value = value["value"]
+
+		if name, ok := value["quality"].(string); ok {
+			if fault.Quality, err = quality.Parse(name); err != nil {
+				return fault, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+			}
+		}
+
+		if fault.Magnitude, err = number(value, "magnitude"); err != nil {
+			return fault, err
+		}
+
+		if fault.Probability, err = number(value, "probability"); err != nil {
+			return fault, err
+		}
+
+		if fault.Latency, err = duration(value, "latency"); err != nil {
+			return fault, err
+		}
+
+		if fault.Start, err = duration(value, "start"); err != nil {
+			return fault, err
+		}
+
+		if fault.Duration, err = duration(value, "duration"); err != nil {
+			return fault, err
+		}
+	default:
+		fault.Value = value
+	}
+
+	return fault, nil
+}
+
+func number(payload map[string]any, name string) (float64, error) {
+	switch value := payload[name].(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return value, nil
+	}
+
+	return 0, fmt.Errorf("%w: %s must be a number", ErrInvalidPayload, name)
+}
+
+func duration(payload map[string]any, name string) (time.Duration, error) {
+	switch value := payload[name].(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return time.Duration(value * float64(time.Second)), nil
+	case string:
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s must be a duration such as 500ms", ErrInvalidPayload, name)
+}
+
+func coerce(value any, like any) any {
+	var number float64
+
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case float32:
+		number = float64(v)
+	case int:
+		number = float64(v)
+	case int32:
+		number = float64(v)
+	case bool:
+		if v {
+			number = 1
+		}
+	default:
+		return like
+	}
+
+	switch like.(type) {
+	case int32:
+		return int32(math.Round(number))
+	case float32:
+		return float32(number)
+	case bool:
+		return number != 0
+	}
+
+	return value
+}
+
+func wrongType(value any) any {
+	switch v := value.(type) {
+	case int32:
+		return float32(v)
+	case float32:
+		return int32(v)
+	case bool:
+		if v {
+			return int32(1)
+		}
+
+		return int32(0)
+	}
+
+	return value
+}
+
+func spike(value any, offset float64) any {
+	switch v := value.(type) {
+	case int32:
+		return v + int32(math.Round(offset))
+	case float32:
+		return v + float32(offset)
+	case bool:
+		return !v
+	}
+
+	return value
+}
@@ -0,0 +1,380 @@
+package fault
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type Injector struct {
+	storage   *storage.Storage
+	events    *event.Events
+	entries   map[string]map[Kind]*entry
+	generator *rand.Rand
+	mutex     sync.Mutex
+}
+
+type entry struct {
+	fault  Fault
+	active bool
+	frozen any
+	timers []clock.Ticker
+}
+
+type injected event.ChangedPayload
+
+func NewInjector(storage *storage.Storage, events *event.Events) *Injector {
+	return &Injector{
+		storage:   storage,
+		events:    events,
+		entries:   make(map[string]map[Kind]*entry),
+		generator: nil,
+		mutex:     sync.Mutex{},
+	}
+}
+
+func (injector *Injector) Start() {
+	injector.mutex.Lock()
+	injector.generator = rand.New(injector.storage.Source("fault"))
+	injector.mutex.Unlock()
+
+	injector.storage.Intercept(injector)
+	injector.events.Intercept(injector.intercept)
+}
+
+func (injector *Injector) Stop() {
+	injector.storage.Intercept(nil)
+	injector.events.Intercept(nil)
+
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	for _, entries := range injector.entries {
+		for _, entry := range entries {
+			entry.stop()
+		}
+	}
+
+	clear(injector.entries)
+}
+
+func (injector *Injector) Inject(fault Fault) error {
+	if !slices.Contains(kinds, fault.Kind) {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, fault.Kind)
+	}
+
+	if _, ok := injector.storage.Lookup(fault.Resource); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownResource, fault.Resource)
+	}
+
+	if fault.Kind == Spike && fault.Probability == 0 {
+		fault.Probability = defaultProbability
+	}
+
+	if fault.Quality == quality.Good {
+		fault.Quality = qualities[fault.Kind]
+	}
+
+	current := &entry{
+		fault:  fault,
+		active: false,
+		frozen: nil,
+		timers: nil,
+	}
+
+	injector.mutex.Lock()
+
+	if injector.entries[fault.Resource] == nil {
+		injector.entries[fault.Resource] = make(map[Kind]*entry)
+	}
+
+	if previous, ok := injector.entries[fault.Resource][fault.Kind]; ok {
+		previous.stop()
+	}
+
+	injector.entries[fault.Resource][fault.Kind] = current
+
+	if fault.Start > 0 {
+		current.timers = append(current.timers, injector.storage.Clock().AfterFunc(fault.Start, func(now time.Time) {
+			injector.activate(current)
+		}))
+	}
+
+	injector.mutex.Unlock()
+
+	if fault.Start <= 0 {
+		injector.activate(current)
+	}
+
+	return nil
+}
+
+func (injector *Injector) Clear(resource string, kinds ...Kind) {
+	injector.mutex.Lock()
+
+	removed := false
+
+	for kind, entry := range injector.entries[resource] {
+		if len(kinds) == 0 || slices.Contains(kinds, kind) {
+			entry.stop()
+			delete(injector.entries[resource], kind)
+			removed = true
+		}
+	}
+
+	if len(injector.entries[resource]) == 0 {
+		delete(injector.entries, resource)
+	}
+
+	injector.mutex.Unlock()
+
+	if removed {
+		injector.notify(resource)
+	}
+}
+
+func (injector *Injector) Active() []Fault {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	faults := make([]Fault, 0)
+
+	for _, entries := range injector.entries {
+		for _, entry := range entries {
+			if entry.active {
+				faults = append(faults, entry.fault)
+			}
+		}
+	}
+
+	slices.SortFunc(faults, func(a Fault, b Fault) int {
+		if compared := strings.Compare(a.Resource, b.Resource); compared != 0 {
+			return compared
+		}
+
+		return strings.Compare(string(a.Kind), string(b.Kind))
+	})
+
+	return faults
+}
+
+func (injector *Injector) Intercept(resource string, sample storage.Sample, err error) (storage.Sample, error) {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	entries := injector.entries[resource]
+
+	if active(entries, Dropout) {
+		return sample, ErrDropout
+	}
+
+	if err != nil {
+		return sample, err
+	}
+
+	sample.Value = injector.apply(entries, sample.Value)
+	sample.Quality = qualify(entries, sample.Quality)
+
+	return sample, nil
+}
+
+func (injector *Injector) activate(current *entry) {
+	frozen, _ := injector.storage.Read(current.fault.Resource)
+
+	injector.mutex.Lock()
+
+	if injector.entries[current.fault.Resource][current.fault.Kind] != current {
+		injector.mutex.Unlock()
+		return
+	}
+
+	current.active = true
+	current.frozen = frozen
+
+	if current.fault.Duration > 0 {
+		current.timers = append(current.timers, injector.storage.Clock().AfterFunc(current.fault.Duration, func(now time.Time) {
+			injector.expire(current)
+		}))
+	}
+
+	injector.mutex.Unlock()
+
+	injector.notify(current.fault.Resource)
+}
+
+func (injector *Injector) expire(current *entry) {
+	injector.mutex.Lock()
+
+	if injector.entries[current.fault.Resource][current.fault.Kind] != current {
+		injector.mutex.Unlock()
+		return
+	}
+
+	injector.mutex.Unlock()
+
+	injector.Clear(current.fault.Resource, current.fault.Kind)
+}
+
+func (injector *Injector) notify(resource string) {
+	sample, err := injector.storage.ReadSample(resource)
+
+	if err != nil {
+		now := injector.storage.Clock().Now()
+
+		injector.mutex.Lock()
+		sample = storage.Sample{
+			Value:           nil,
+			Quality:         qualify(injector.entries[resource], quality.BadNotConnected),
+			SourceTimestamp: now,
+			ServerTimestamp: now,
+		}
+		injector.mutex.Unlock()
+	}
+
+	injector.events.Emit(event.Changed(resource), injected{
+		Resource:        resource,
+		Value:           sample.Value,
+		Quality:         sample.Quality,
+		SourceTimestamp: sample.SourceTimestamp,
+		ServerTimestamp: sample.ServerTimestamp,
+	})
+}
+
+func (injector *Injector) intercept(name event.Event, payload any, deliver func(payload any)) {
+	if notification, ok := payload.(injected); ok {
+		deliver(event.ChangedPayload(notification))
+		return
+	}
+
+	if separator := strings.LastIndex(string(name), ":"); separator >= 0 {
+		resource, action := string(name[:separator]), string(name[separator+1:])
+
+		if !strings.HasPrefix(action, ActionPrefix) || action == ErrorAction {
+			deliver(payload)
+			return
+		}
+
+		err := injector.handle(resource, action, payload)
+		deliver(payload)
+
+		if err != nil {
+			injector.events.Emit(event.Action(resource, ErrorAction), ErrorPayload{
+				Resource: resource,
+				Action:   action,
+				Error:    err.Error(),
+			})
+		}
+
+		return
+	}
+
+	changed, ok := payload.(event.ChangedPayload)
+
+	if !ok {
+		deliver(payload)
+		return
+	}
+
+	injector.mutex.Lock()
+
+	entries := injector.entries[changed.Resource]
+
+	if active(entries, Dropout) || active(entries, Stuck) || active(entries, Frozen) {
+		injector.mutex.Unlock()
+		return
+	}
+
+	changed.Value = injector.apply(entries, changed.Value)
+	changed.Quality = qualify(entries, changed.Quality)
+	latency, delayed := entries[Latency]
+	delayed = delayed && latency.active
+
+	injector.mutex.Unlock()
+
+	if delayed {
+		injector.storage.Clock().AfterFunc(latency.fault.Latency, func(now time.Time) {
+			deliver(changed)
+		})
+
+		return
+	}
+
+	deliver(changed)
+}
+
+func (injector *Injector) handle(resource string, action string, payload any) error {
+	if action == ClearAction {
+		if kind, ok := payload.(string); ok {
+			injector.Clear(resource, Kind(kind))
+			return nil
+		}
+
+		injector.Clear(resource)
+
+		return nil
+	}
+
+	fault, err := decode(resource, Kind(strings.TrimPrefix(action, ActionPrefix)), payload)
+
+	if err != nil {
+		return err
+	}
+
+	return injector.Inject(fault)
+}
+
+func (injector *Injector) apply(entries map[Kind]*entry, value any) any {
+	if active(entries, Stuck) {
+		value = coerce(entries[Stuck].fault.Value, value)
+	}
+
+	if active(entries, Frozen) && entries[Frozen].frozen != nil {
+		value = entries[Frozen].frozen
+	}
+
+	if active(entries, Spike) && injector.generator.Float64() < entries[Spike].fault.Probability {
+		offset := entries[Spike].fault.Magnitude
+
+		if injector.generator.IntN(2) == 0 {
+			offset = -offset
+		}
+
+		value = spike(value, offset)
+	}
+
+	if active(entries, WrongType) {
+		value = wrongType(value)
+	}
+
+	return value
+}
+
+func qualify(entries map[Kind]*entry, fallback quality.Quality) quality.Quality {
+	for _, kind := range kinds {
+		if active(entries, kind) && entries[kind].fault.Quality != quality.Good {
+			return entries[kind].fault.Quality
+		}
+	}
+
+	return fallback
+}
+
+func active(entries map[Kind]*entry, kind Kind) bool {
+	entry, ok := entries[kind]
+
+	return ok && entry.active
+}
+
+func (entry *entry) stop() {
+	for _, timer := range entry.timers {
+		timer.Stop()
+	}
+}
@@ -0,0 +1,261 @@
+package fault_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/fault"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func newInjector(t *testing.T) (*storage.Storage, *event.Events, *clock.Manual, *fault.Injector) {
+	t.Helper()
+
+	store := storage.NewStorage(map[string]storage.Resource{
+		"level": resource.NewStatic[float32](10),
+		"count": resource.NewStatic[int32](3),
+	})
+	events := event.NewEvents(time.Second)
+	manual := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err := store.Start(context.Background(), events, manual, 1); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	injector := fault.NewInjector(store, events)
+	injector.Start()
+
+	t.Cleanup(func() {
+		injector.Stop()
+		store.Stop(context.Background())
+	})
+
+	return store, events, manual, injector
+}
+
+func sample(t *testing.T, store *storage.Storage, name string) storage.Sample {
+	t.Helper()
+
+	sample, err := store.ReadSample(name)
+
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+
+	return sample
+}
+
+func receive(t *testing.T, listener chan any) any {
+	t.Helper()
+
+	select {
+	case payload := <-listener:
+		return payload
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+
+	return nil
+}
+
+func TestFaultKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		fault    fault.Fault
+		write    any
+		expected any
+		quality  quality.Quality
+	}{
+		{
+			name:     "stuck",
+			fault:    fault.Fault{Resource: "level", Kind: fault.Stuck, Value: 99.0},
+			write:    float32(20),
+			expected: float32(99),
+			quality:  quality.BadSensorFailure,
+		},
+		{
+			name:     "stuck with explicit quality",
+			fault:    fault.Fault{Resource: "count", Kind: fault.Stuck, Value: 7.0, Quality: quality.UncertainInitialValue},
+			write:    int32(4),
+			expected: int32(7),
+			quality:  quality.UncertainInitialValue,
+		},
+		{
+			name:     "frozen",
+			fault:    fault.Fault{Resource: "level", Kind: fault.Frozen},
+			write:    float32(20),
+			expected: float32(10),
+			quality:  quality.UncertainLastUsableValue,
+		},
+		{
+			name:     "wrong type",
+			fault:    fault.Fault{Resource: "level", Kind: fault.WrongType},
+			write:    float32(20),
+			expected: int32(20),
+			quality:  quality.BadConfigurationError,
+		},
+		{
+			name:     "latency",
+			fault:    fault.Fault{Resource: "count", Kind: fault.Latency, Latency: time.Second},
+			write:    int32(4),
+			expected: int32(4),
+			quality:  quality.Uncertain,
+		},
+	}
+
+	for _, test := range tests {
+		store, _, _, injector := newInjector(t)
+
+		if err := injector.Inject(test.fault); err != nil {
+			t.Fatalf("%s: inject: %v", test.name, err)
+		}
+
+		if err := store.Write(test.fault.Resource, test.write); err != nil {
+			t.Fatalf("%s: write: %v", test.name, err)
+		}
+
+		if sample := sample(t, store, test.fault.Resource); sample.Value != test.expected || sample.Quality != test.quality {
+			t.Fatalf("%s: expected %v with %v, got %v with %v", test.name, test.expected, test.quality, sample.Value, sample.Quality)
+		}
+	}
+}
+
+func TestSpike(t *testing.T) {
+	store, _, _, injector := newInjector(t)
+
+	if err := injector.Inject(fault.Fault{Resource: "level", Kind: fault.Spike, Magnitude: 5, Probability: 1}); err != nil {
+		t.Fatalf("inject: %v", err)
+	}
+
+	sample := sample(t, store, "level")
+
+	if sample.Value != float32(5) && sample.Value != float32(15) {
+		t.Fatalf("expected a spike of 5 around 10, got %v", sample.Value)
+	}
+
+	if sample.Quality != quality.UncertainSensorNotAccurate {
+		t.Fatalf("expected %v, got %v", quality.UncertainSensorNotAccurate, sample.Quality)
+	}
+}
+
+func TestDropout(t *testing.T) {
+	store, _, _, injector := newInjector(t)
+
+	if err := injector.Inject(fault.Fault{Resource: "level", Kind: fault.Dropout}); err != nil {
+		t.Fatalf("inject: %v", err)
+	}
+
+	if _, err := store.ReadSample("level"); !errors.Is(err, fault.ErrDropout) {
+		t.Fatalf("expected %v, got %v", fault.ErrDropout, err)
+	}
+
+	injector.Clear("level")
+
+	if sample := sample(t, store, "level"); sample.Value != float32(10) || sample.Quality != quality.Good {
+		t.Fatalf("expected the cleared resource to read 10 with good quality, got %v with %v", sample.Value, sample.Quality)
+	}
+}
+
+func TestLatencyDelaysChanges(t *testing.T) {
+	store, events, manual, injector := newInjector(t)
+
+	if err := injector.Inject(fault.Fault{Resource: "count", Kind: fault.Latency, Latency: 2 * time.Second}); err != nil {
+		t.Fatalf("inject: %v", err)
+	}
+
+	listener := make(chan any)
+	events.Subscribe(event.Changed("count"), listener)
+	defer events.Unsubscribe(event.Changed("count"), listener)
+
+	store.Write("count", int32(4))
+
+	select {
+	case payload := <-listener:
+		t.Fatalf("expected the change to be delayed, got %v", payload)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	manual.Advance(2 * time.Second)
+
+	if changed := receive(t, listener).(event.ChangedPayload); changed.Value != int32(4) || changed.Quality != quality.Uncertain {
+		t.Fatalf("expected the delayed change to carry 4 with %v, got %v with %v", quality.Uncertain, changed.Value, changed.Quality)
+	}
+}
+
+func TestScheduledFault(t *testing.T) {
+	store, _, manual, injector := newInjector(t)
+
+	if err := injector.Inject(fault.Fault{Resource: "level", Kind: fault.Stuck, Value: 99.0, Start: 2 * time.Second, Duration: 3 * time.Second}); err != nil {
+		t.Fatalf("inject: %v", err)
+	}
+
+	if active := injector.Active(); len(active) != 0 || sample(t, store, "level").Value != float32(10) {
+		t.Fatalf("expected the fault to wait for its start, got %v", active)
+	}
+
+	manual.Advance(2 * time.Second)
+
+	if active := injector.Active(); len(active) != 1 || sample(t, store, "level").Value != float32(99) {
+		t.Fatalf("expected the fault to be active after its start, got %v", active)
+	}
+
+	manual.Advance(3 * time.Second)
+
+	if active := injector.Active(); len(active) != 0 || sample(t, store, "level").Value != float32(10) {
+		t.Fatalf("expected the fault to expire after its duration, got %v", active)
+	}
+}
+
+func TestInjectRejectsInvalidFaults(t *testing.T) {
+	_, _, _, injector := newInjector(t)
+
+	tests := []struct {
+		fault fault.Fault
+		err   error
+	}{
+		{fault: fault.Fault{Resource: "level", Kind: "melted"}, err: fault.ErrUnknownKind},
+		{fault: fault.Fault{Resource: "missing", Kind: fault.Stuck}, err: fault.ErrUnknownResource},
+	}
+
+	for _, test := range tests {
+		if err := injector.Inject(test.fault); !errors.Is(err, test.err) {
+			t.Fatalf("expected %v, got %v", test.err, err)
+		}
+	}
+}
+
+func TestActionsReportErrors(t *testing.T) {
+	store, events, _, _ := newInjector(t)
+
+	listener := make(chan any)
+	events.Subscribe(event.Action("level", fault.ErrorAction), listener)
+	defer events.Unsubscribe(event.Action("level", fault.ErrorAction), listener)
+
+	tests := []struct {
+		action  string
+		payload any
+	}{
+		{action: fault.ActionPrefix + "stuck", payload: map[string]any{"magnitude": "large"}},
+		{action: fault.ActionPrefix + "melted", payload: nil},
+	}
+
+	for _, test := range tests {
+		events.Emit(event.Action("level", test.action), test.payload)
+
+		if failed := receive(t, listener).(fault.ErrorPayload); failed.Resource != "level" || failed.Action != test.action || failed.Error == "" {
+			t.Fatalf("%s: expected an error report, got %+v", test.action, failed)
+		}
+	}
+
+	events.Emit(event.Action("level", fault.ActionPrefix+"stuck"), map[string]any{"value": 50.0})
+
+	if sample := sample(t, store, "level"); sample.Value != float32(50) {
+		t.Fatalf("expected a valid action to inject the fault, got %v", sample.Value)
+	}
+}
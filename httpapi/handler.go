@@ -0,0 +1,311 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/studiolambda/immersim"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/fault"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+const (
+	maxBodySize       = 1 << 20
+	keepAliveInterval = 15 * time.Second
+	streamBufferSize  = 64
+)
+
+type Handler struct {
+	application *immersim.Application
+	mux         *http.ServeMux
+}
+
+type resourcePayload struct {
+	Resource        string           `json:"resource"`
+	Value           any              `json:"value"`
+	Quality         *quality.Quality `json:"quality,omitempty"`
+	SourceTimestamp *time.Time       `json:"source_timestamp,omitempty"`
+	ServerTimestamp *time.Time       `json:"server_timestamp,omitempty"`
+	Error           string           `json:"error,omitempty"`
+}
+
+type errorPayload struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+var (
+	ErrResourceNotFound = errors.New("resource not found")
+)
+
+func NewHandler(application *immersim.Application) *Handler {
+	handler := &Handler{
+		application: application,
+		mux:         http.NewServeMux(),
+	}
+
+	handler.mux.HandleFunc("GET /resources", handler.list)
+	handler.mux.HandleFunc("GET /resources/{name...}", handler.read)
+	handler.mux.HandleFunc("PUT /resources/{name...}", handler.write)
+	handler.mux.HandleFunc("POST /resources/{path...}", handler.action)
+	handler.mux.HandleFunc("GET /browse", handler.browse)
+	handler.mux.HandleFunc("GET /browse/{folder...}", handler.browse)
+	handler.mux.HandleFunc("GET /events", handler.events)
+
+	return handler
+}
+
+func (handler *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	handler.mux.ServeHTTP(writer, request)
+}
+
+func (handler *Handler) list(writer http.ResponseWriter, request *http.Request) {
+	names := handler.application.Resources()
+	resources := make([]resourcePayload, 0, len(names))
+
+	for _, name := range names {
+		sample, err := handler.application.ReadSample(name)
+
+		if err != nil {
+			resources = append(resources, resourcePayload{Resource: name, Error: code(err)})
+			continue
+		}
+
+		resources = append(resources, sampled(name, sample))
+	}
+
+	respond(writer, http.StatusOK, resources)
+}
+
+func (handler *Handler) browse(writer http.ResponseWriter, request *http.Request) {
+	entries := handler.application.Browse(request.PathValue("folder"))
+
+	if len(entries) == 0 && request.PathValue("folder") != "" {
+		fail(writer, fmt.Errorf("%w: %s", ErrResourceNotFound, request.PathValue("folder")))
+		return
+	}
+
+	respond(writer, http.StatusOK, entries)
+}
+
+func (handler *Handler) read(writer http.ResponseWriter, request *http.Request) {
+	name := request.PathValue("name")
+
+	if !handler.application.Has(name) {
+		fail(writer, fmt.Errorf("%w: %s", ErrResourceNotFound, name))
+		return
+	}
+
+	sample, err := handler.application.ReadSample(name)
+
+	if err != nil {
+		fail(writer, err)
+		return
+	}
+
+	respond(writer, http.StatusOK, sampled(name, sample))
+}
+
+func sampled(name string, sample storage.Sample) resourcePayload {
+	return resourcePayload{
+		Resource:        name,
+		Value:           sample.Value,
+		Quality:         &sample.Quality,
+		SourceTimestamp: &sample.SourceTimestamp,
+		ServerTimestamp: &sample.ServerTimestamp,
+		Error:           "",
+	}
+}
+
+func (handler *Handler) write(writer http.ResponseWriter, request *http.Request) {
+	name := request.PathValue("name")
+
+	if !handler.application.Has(name) {
+		fail(writer, fmt.Errorf("%w: %s", ErrResourceNotFound, name))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxBodySize))
+
+	if err != nil {
+		fail(writer, fmt.Errorf("%w: %w", immersim.ErrInvalidJSON, err))
+		return
+	}
+
+	if err := handler.application.WriteJSON(name, body); err != nil {
+		fail(writer, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) action(writer http.ResponseWriter, request *http.Request) {
+	name, action, ok := actionPath(request.PathValue("path"))
+
+	if !ok {
+		http.NotFound(writer, request)
+		return
+	}
+
+	if !handler.application.Has(name) {
+		fail(writer, fmt.Errorf("%w: %s", ErrResourceNotFound, name))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxBodySize))
+
+	if err != nil {
+		fail(writer, fmt.Errorf("%w: %w", immersim.ErrInvalidJSON, err))
+		return
+	}
+
+	var payload any
+
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			fail(writer, fmt.Errorf("%w: %w", immersim.ErrInvalidJSON, err))
+			return
+		}
+	}
+
+	handler.application.Action(name, action, payload)
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+func actionPath(path string) (string, string, bool) {
+	index := strings.LastIndex(path, "/actions/")
+
+	if index <= 0 {
+		return "", "", false
+	}
+
+	name, action := path[:index], path[index+len("/actions/"):]
+
+	return name, action, action != "" && !strings.Contains(action, "/")
+}
+
+func (handler *Handler) events(writer http.ResponseWriter, request *http.Request) {
+	flusher, ok := writer.(http.Flusher)
+
+	if !ok {
+		http.Error(writer, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	names := request.URL.Query()["resource"]
+
+	if len(names) == 0 {
+		names = handler.application.Resources()
+	}
+
+	for _, name := range names {
+		if !handler.application.Has(name) {
+			fail(writer, fmt.Errorf("%w: %s", ErrResourceNotFound, name))
+			return
+		}
+	}
+
+	listener := make(chan any, streamBufferSize)
+
+	for _, name := range names {
+		handler.application.SubscribeChangesWith(name, listener, event.DropOldest, streamBufferSize)
+		defer handler.application.UnsubscribeChanges(name, listener)
+	}
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case payload := <-listener:
+			changed, ok := payload.(event.ChangedPayload)
+
+			if !ok {
+				continue
+			}
+
+			data, err := json.Marshal(changed)
+
+			if err != nil {
+				continue
+			}
+
+			if _, err := fmt.Fprintf(writer, "event: changed\ndata: %s\n\n", data); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+
+			flusher.Flush()
+		case <-request.Context().Done():
+			return
+		}
+	}
+}
+
+func code(err error) string {
+	switch {
+	case errors.Is(err, ErrResourceNotFound):
+		return "not_found"
+	case errors.Is(err, storage.ErrResourceNotReadable):
+		return "not_readable"
+	case errors.Is(err, storage.ErrResourceNotWritable):
+		return "not_writable"
+	case errors.Is(err, resource.ErrMissmatchedTypes):
+		return "type_mismatch"
+	case errors.Is(err, storage.ErrOutOfRange):
+		return "out_of_range"
+	case errors.Is(err, immersim.ErrInvalidJSON):
+		return "invalid_json"
+	case errors.Is(err, fault.ErrDropout):
+		return "unavailable"
+	}
+
+	return "internal"
+}
+
+func status(err error) int {
+	switch code(err) {
+	case "not_found":
+		return http.StatusNotFound
+	case "not_readable", "not_writable":
+		return http.StatusMethodNotAllowed
+	case "type_mismatch", "out_of_range":
+		return http.StatusUnprocessableEntity
+	case "unavailable":
+		return http.StatusServiceUnavailable
+	case "invalid_json":
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
+
+func respond(writer http.ResponseWriter, status int, payload any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(payload)
+}
+
+func fail(writer http.ResponseWriter, err error) {
+	respond(writer, status(err), errorPayload{Error: code(err), Message: err.Error()})
+}
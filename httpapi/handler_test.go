@@ -0,0 +1,221 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim"
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/fault"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func newTestHandler(t *testing.T) (*immersim.Application, *Handler) {
+	t.Helper()
+
+	store := storage.NewStorage(map[string]storage.Resource{
+		"tank/level":   resource.NewStatic[float32](1.5),
+		"tank/count":   resource.NewStatic[int32](3),
+		"tank/running": resource.NewStatic[bool](false),
+		"tank/limit":   resource.NewConstant[int32](10),
+	})
+	store.Annotate("tank/level", storage.Metadata{Range: &storage.Range{Min: 0, Max: 100}})
+
+	application := immersim.NewApplication(store, event.NewEvents(time.Second), clock.NewReal())
+
+	if err := application.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	t.Cleanup(func() {
+		application.Stop(context.Background())
+	})
+
+	return application, NewHandler(application)
+}
+
+func serve(handler *Handler, method string, target string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+	return recorder
+}
+
+func errorCode(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var payload errorPayload
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode %q: %v", recorder.Body.String(), err)
+	}
+
+	return payload.Error
+}
+
+func TestReadResource(t *testing.T) {
+	_, handler := newTestHandler(t)
+	recorder := serve(handler, http.MethodGet, "/resources/tank/level", "")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	var payload resourcePayload
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if payload.Resource != "tank/level" || payload.Value != 1.5 || payload.Quality == nil {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
+
+func TestWriteResource(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+	}{
+		{name: "tank/level", body: "2.5", status: http.StatusNoContent},
+		{name: "tank/count", body: "4", status: http.StatusNoContent},
+		{name: "tank/running", body: "true", status: http.StatusNoContent},
+		{name: "tank/level", body: `"full"`, status: http.StatusUnprocessableEntity, code: "type_mismatch"},
+		{name: "tank/count", body: "4.5", status: http.StatusUnprocessableEntity, code: "type_mismatch"},
+		{name: "tank/running", body: "1", status: http.StatusUnprocessableEntity, code: "type_mismatch"},
+		{name: "tank/level", body: "150", status: http.StatusUnprocessableEntity, code: "out_of_range"},
+		{name: "tank/level", body: "{", status: http.StatusBadRequest, code: "invalid_json"},
+		{name: "tank/limit", body: "20", status: http.StatusMethodNotAllowed, code: "not_writable"},
+		{name: "tank/missing", body: "1", status: http.StatusNotFound, code: "not_found"},
+	}
+
+	for _, test := range tests {
+		_, handler := newTestHandler(t)
+		recorder := serve(handler, http.MethodPut, "/resources/"+test.name, test.body)
+
+		if recorder.Code != test.status {
+			t.Fatalf("PUT %s %s: expected %d, got %d: %s", test.name, test.body, test.status, recorder.Code, recorder.Body)
+		}
+
+		if test.code != "" {
+			if code := errorCode(t, recorder); code != test.code {
+				t.Fatalf("PUT %s %s: expected %q, got %q", test.name, test.body, test.code, code)
+			}
+		}
+	}
+}
+
+func TestWriteIsVisible(t *testing.T) {
+	application, handler := newTestHandler(t)
+	serve(handler, http.MethodPut, "/resources/tank/count", "7")
+
+	if value, _ := application.Read("tank/count"); value != int32(7) {
+		t.Fatalf("expected 7, got %v", value)
+	}
+}
+
+func TestReadDropout(t *testing.T) {
+	application, handler := newTestHandler(t)
+
+	if err := application.Inject(fault.Fault{Resource: "tank/level", Kind: fault.Dropout}); err != nil {
+		t.Fatalf("inject: %v", err)
+	}
+
+	recorder := serve(handler, http.MethodGet, "/resources/tank/level", "")
+
+	if recorder.Code != http.StatusServiceUnavailable || errorCode(t, recorder) != "unavailable" {
+		t.Fatalf("expected %d, got %d: %s", http.StatusServiceUnavailable, recorder.Code, recorder.Body)
+	}
+}
+
+func TestAction(t *testing.T) {
+	application, handler := newTestHandler(t)
+	actions := make(chan any, 1)
+	application.SubscribeAction("tank/level", "fill", actions)
+
+	defer application.UnsubscribeAction("tank/level", "fill", actions)
+
+	if recorder := serve(handler, http.MethodPost, "/resources/tank/level/actions/fill", `{"rate":2}`); recorder.Code != http.StatusAccepted {
+		t.Fatalf("expected %d, got %d", http.StatusAccepted, recorder.Code)
+	}
+
+	select {
+	case payload := <-actions:
+		if payload.(map[string]any)["rate"] != float64(2) {
+			t.Fatalf("unexpected payload %v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the action")
+	}
+
+	if recorder := serve(handler, http.MethodPost, "/resources/tank/level/actions/fill", "{"); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	application, handler := newTestHandler(t)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?resource=tank/count", nil)
+	response, err := http.DefaultClient.Do(request)
+
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	defer response.Body.Close()
+
+	if kind := response.Header.Get("Content-Type"); kind != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", kind)
+	}
+
+	application.Write("tank/level", float32(5))
+	application.Write("tank/count", int32(9))
+
+	scanner := bufio.NewScanner(response.Body)
+	lines := []string{}
+
+	for len(lines) < 2 && scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) < 2 || lines[0] != "event: changed" || !strings.HasPrefix(lines[1], "data: ") {
+		t.Fatalf("unexpected stream %q: %v", lines, scanner.Err())
+	}
+
+	var payload event.ChangedPayload
+
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if payload.Resource != "tank/count" || payload.Value != float64(9) {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
+
+func TestEventsUnknownResource(t *testing.T) {
+	_, handler := newTestHandler(t)
+	recorder := serve(handler, http.MethodGet, "/events?resource=tank/missing", "")
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d", http.StatusNotFound, recorder.Code)
+	}
+}
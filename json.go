@@ -0,0 +1,77 @@
+package immersim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+var (
+	ErrInvalidJSON = errors.New("invalid json value")
+)
+
+func (application *Application) WriteJSON(name string, data []byte) error {
+	current, err := application.Read(name)
+
+	if err != nil {
+		var value any
+
+		if err := json.Unmarshal(data, &value); err != nil {
+			return errors.Join(
+				fmt.Errorf("%w: %s", storage.ErrWrite, name),
+				fmt.Errorf("%w: %w", ErrInvalidJSON, err),
+			)
+		}
+
+		return application.Write(name, value)
+	}
+
+	value, err := decodeLike(data, current)
+
+	if err != nil {
+		return errors.Join(
+			fmt.Errorf("%w: %s", storage.ErrWrite, name),
+			err,
+		)
+	}
+
+	return application.Write(name, value)
+}
+
+func decodeLike(data []byte, like any) (any, error) {
+	var err error
+
+	switch like.(type) {
+	case int32:
+		var value int32
+		if err = json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	case float32:
+		var value float32
+		if err = json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	case bool:
+		var value bool
+		if err = json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	default:
+		var value any
+		if err = json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	var syntax *json.SyntaxError
+
+	if errors.As(err, &syntax) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+
+	return nil, fmt.Errorf("%w: expected %T: %w", resource.ErrMissmatchedTypes, like, err)
+}
@@ -0,0 +1,315 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+const (
+	ReadCoils              byte = 0x01
+	ReadDiscreteInputs     byte = 0x02
+	ReadHoldingRegisters   byte = 0x03
+	ReadInputRegisters     byte = 0x04
+	WriteSingleCoil        byte = 0x05
+	WriteSingleRegister    byte = 0x06
+	WriteMultipleCoils     byte = 0x0F
+	WriteMultipleRegisters byte = 0x10
+)
+
+const (
+	IllegalFunction     byte = 0x01
+	IllegalDataAddress  byte = 0x02
+	IllegalDataValue    byte = 0x03
+	ServerDeviceFailure byte = 0x04
+)
+
+const (
+	maxReadBits       = 2000
+	maxReadRegisters  = 125
+	maxWriteBits      = 1968
+	maxWriteRegisters = 123
+)
+
+func exception(function byte, code byte) []byte {
+	return []byte{function | 0x80, code}
+}
+
+func exceptionFor(err error) byte {
+	switch {
+	case errors.Is(err, storage.ErrResourceNotFound),
+		errors.Is(err, storage.ErrResourceNotWritable),
+		errors.Is(err, storage.ErrResourceNotReadable):
+		return IllegalDataAddress
+	case errors.Is(err, resource.ErrMissmatchedTypes),
+		errors.Is(err, ErrUnsupportedType):
+		return IllegalDataValue
+	}
+
+	return ServerDeviceFailure
+}
+
+func (server *Server) handle(request []byte) []byte {
+	function := request[0]
+	data := request[1:]
+
+	switch function {
+	case ReadCoils:
+		return server.readBits(function, data, server.mapping.Coils)
+	case ReadDiscreteInputs:
+		return server.readBits(function, data, server.mapping.DiscreteInputs)
+	case ReadHoldingRegisters:
+		return server.readRegisters(function, data, server.mapping.HoldingRegisters)
+	case ReadInputRegisters:
+		return server.readRegisters(function, data, server.mapping.InputRegisters)
+	case WriteSingleCoil:
+		return server.writeSingleCoil(function, data)
+	case WriteSingleRegister:
+		return server.writeSingleRegister(function, data)
+	case WriteMultipleCoils:
+		return server.writeMultipleCoils(function, data)
+	case WriteMultipleRegisters:
+		return server.writeMultipleRegisters(function, data)
+	}
+
+	return exception(function, IllegalFunction)
+}
+
+func (server *Server) readBits(function byte, data []byte, bits map[uint16]string) []byte {
+	if len(data) != 4 {
+		return exception(function, IllegalDataValue)
+	}
+
+	address := binary.BigEndian.Uint16(data[0:2])
+	quantity := binary.BigEndian.Uint16(data[2:4])
+
+	if quantity < 1 || quantity > maxReadBits || int(address)+int(quantity) > 0x10000 {
+		return exception(function, IllegalDataValue)
+	}
+
+	response := make([]byte, 2+(quantity+7)/8)
+	response[0] = function
+	response[1] = byte((quantity + 7) / 8)
+
+	for i := uint16(0); i < quantity; i++ {
+		name, ok := bits[address+i]
+
+		if !ok {
+			return exception(function, IllegalDataAddress)
+		}
+
+		value, err := server.storage.Read(name)
+
+		if err != nil {
+			return exception(function, exceptionFor(err))
+		}
+
+		val, ok := value.(bool)
+
+		if !ok {
+			return exception(function, IllegalDataValue)
+		}
+
+		if val {
+			response[2+i/8] |= 1 << (i % 8)
+		}
+	}
+
+	return response
+}
+
+func (server *Server) readRegisters(function byte, data []byte, registers map[uint16]string) []byte {
+	if len(data) != 4 {
+		return exception(function, IllegalDataValue)
+	}
+
+	address := binary.BigEndian.Uint16(data[0:2])
+	quantity := binary.BigEndian.Uint16(data[2:4])
+
+	if quantity < 1 || quantity > maxReadRegisters || int(address)+int(quantity) > 0x10000 {
+		return exception(function, IllegalDataValue)
+	}
+
+	response := make([]byte, 2+2*quantity)
+	response[0] = function
+	response[1] = byte(2 * quantity)
+
+	for i := uint16(0); i < quantity; i++ {
+		name, start, ok := server.lookupRegister(registers, address+i)
+
+		if !ok {
+			return exception(function, IllegalDataAddress)
+		}
+
+		value, err := server.storage.Read(name)
+
+		if err != nil {
+			return exception(function, exceptionFor(err))
+		}
+
+		words, err := encodeWords(server.raw(name, value), server.mapping.WordOrder)
+
+		if err != nil {
+			return exception(function, exceptionFor(err))
+		}
+
+		binary.BigEndian.PutUint16(response[2+2*i:], words[address+i-start])
+	}
+
+	return response
+}
+
+func (server *Server) writeSingleCoil(function byte, data []byte) []byte {
+	if len(data) != 4 {
+		return exception(function, IllegalDataValue)
+	}
+
+	address := binary.BigEndian.Uint16(data[0:2])
+	raw := binary.BigEndian.Uint16(data[2:4])
+
+	if raw != 0xFF00 && raw != 0x0000 {
+		return exception(function, IllegalDataValue)
+	}
+
+	name, ok := server.mapping.Coils[address]
+
+	if !ok {
+		return exception(function, IllegalDataAddress)
+	}
+
+	if err := server.storage.Write(name, raw == 0xFF00); err != nil {
+		return exception(function, exceptionFor(err))
+	}
+
+	return append([]byte{function}, data...)
+}
+
+func (server *Server) writeMultipleCoils(function byte, data []byte) []byte {
+	if len(data) < 5 {
+		return exception(function, IllegalDataValue)
+	}
+
+	address := binary.BigEndian.Uint16(data[0:2])
+	quantity := binary.BigEndian.Uint16(data[2:4])
+	count := data[4]
+
+	if quantity < 1 || quantity > maxWriteBits || int(count) != int(quantity+7)/8 || len(data) != 5+int(count) {
+		return exception(function, IllegalDataValue)
+	}
+
+	if int(address)+int(quantity) > 0x10000 {
+		return exception(function, IllegalDataAddress)
+	}
+
+	for i := uint16(0); i < quantity; i++ {
+		if _, ok := server.mapping.Coils[address+i]; !ok {
+			return exception(function, IllegalDataAddress)
+		}
+	}
+
+	for i := uint16(0); i < quantity; i++ {
+		value := data[5+i/8]&(1<<(i%8)) != 0
+
+		if err := server.storage.Write(server.mapping.Coils[address+i], value); err != nil {
+			return exception(function, exceptionFor(err))
+		}
+	}
+
+	return append([]byte{function}, data[0:4]...)
+}
+
+func (server *Server) writeSingleRegister(function byte, data []byte) []byte {
+	if len(data) != 4 {
+		return exception(function, IllegalDataValue)
+	}
+
+	address := binary.BigEndian.Uint16(data[0:2])
+	word := binary.BigEndian.Uint16(data[2:4])
+
+	if code, ok := server.writeWords(address, []uint16{word}); !ok {
+		return exception(function, code)
+	}
+
+	return append([]byte{function}, data...)
+}
+
+func (server *Server) writeMultipleRegisters(function byte, data []byte) []byte {
+	if len(data) < 5 {
+		return exception(function, IllegalDataValue)
+	}
+
+	address := binary.BigEndian.Uint16(data[0:2])
+	quantity := binary.BigEndian.Uint16(data[2:4])
+	count := data[4]
+
+	if quantity < 1 || quantity > maxWriteRegisters || int(count) != 2*int(quantity) || len(data) != 5+int(count) {
+		return exception(function, IllegalDataValue)
+	}
+
+	if int(address)+int(quantity) > 0x10000 {
+		return exception(function, IllegalDataAddress)
+	}
+
+	words := make([]uint16, quantity)
+
+	for i := range words {
+		words[i] = binary.BigEndian.Uint16(data[5+2*i:])
+	}
+
+	if code, ok := server.writeWords(address, words); !ok {
+		return exception(function, code)
+	}
+
+	return append([]byte{function}, data[0:4]...)
+}
+
+func (server *Server) writeWords(address uint16, words []uint16) (byte, bool) {
+	for i := range words {
+		if _, _, ok := server.lookupRegister(server.mapping.HoldingRegisters, address+uint16(i)); !ok {
+			return IllegalDataAddress, false
+		}
+	}
+
+	for i := 0; i < len(words); {
+		name, start, _ := server.lookupRegister(server.mapping.HoldingRegisters, address+uint16(i))
+		like, err := server.like(name)
+
+		if err != nil {
+			return exceptionFor(err), false
+		}
+
+		offset := int(address) + i - int(start)
+		merged := make([]uint16, server.width(name))
+
+		if offset > 0 || len(words)-i < len(merged) {
+			current, err := server.storage.Read(name)
+
+			if err != nil {
+				return exceptionFor(err), false
+			}
+
+			if merged, err = encodeWords(server.raw(name, current), server.mapping.WordOrder); err != nil {
+				return exceptionFor(err), false
+			}
+		}
+
+		for ; offset < len(merged) && i < len(words); offset++ {
+			merged[offset] = words[i]
+			i++
+		}
+
+		value, err := decodeWords(merged, like, server.mapping.WordOrder)
+
+		if err != nil {
+			return exceptionFor(err), false
+		}
+
+		if err := server.storage.Write(name, server.engineering(name, value)); err != nil {
+			return exceptionFor(err), false
+		}
+	}
+
+	return 0, true
+}
@@ -0,0 +1,271 @@
+package modbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	store := storage.NewStorage(map[string]storage.Resource{
+		"level":    resource.NewStatic[float32](1.5),
+		"count":    resource.NewStatic[int32](-2),
+		"enabled":  resource.NewStatic[bool](true),
+		"setpoint": resource.NewStatic[float32](0),
+		"pressure": resource.NewStatic[float32](50),
+		"running":  resource.NewStatic[bool](false),
+		"alarm":    resource.NewStatic[bool](true),
+	})
+	store.Annotate("setpoint", storage.Metadata{Access: storage.AccessWrite})
+	store.Annotate("pressure", storage.Metadata{Range: &storage.Range{Min: 0, Max: 100}, Raw: &storage.Range{Min: 0, Max: 1000}})
+
+	if err := store.Start(context.Background(), event.NewEvents(time.Second), clock.NewReal(), 1); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	t.Cleanup(func() {
+		store.Stop(context.Background())
+	})
+
+	return NewServer(store, Mapping{
+		HoldingRegisters: map[uint16]string{0: "level", 2: "count", 4: "enabled", 5: "setpoint", 7: "pressure"},
+		InputRegisters:   map[uint16]string{0: "level"},
+		Coils:            map[uint16]string{0: "running", 1: "alarm"},
+		DiscreteInputs:   map[uint16]string{0: "alarm"},
+		WordOrder:        HighWordFirst,
+	})
+}
+
+func request(function byte, fields ...uint16) []byte {
+	pdu := []byte{function}
+
+	for _, field := range fields {
+		pdu = binary.BigEndian.AppendUint16(pdu, field)
+	}
+
+	return pdu
+}
+
+func registers(response []byte) []uint16 {
+	words := make([]uint16, response[1]/2)
+
+	for i := range words {
+		words[i] = binary.BigEndian.Uint16(response[2+2*i:])
+	}
+
+	return words
+}
+
+func TestReadHoldingRegisters(t *testing.T) {
+	server := newTestServer(t)
+	bits := math.Float32bits(1.5)
+	response := server.handle(request(ReadHoldingRegisters, 0, 5))
+
+	expected := []uint16{uint16(bits >> 16), uint16(bits), 0xFFFF, 0xFFFE, 1}
+
+	if words := registers(response); !slices.Equal(words, expected) {
+		t.Fatalf("expected %04x, got %04x", expected, words)
+	}
+}
+
+func TestReadRegisterInsideValue(t *testing.T) {
+	server := newTestServer(t)
+
+	if words := registers(server.handle(request(ReadInputRegisters, 1, 1))); words[0] != uint16(math.Float32bits(1.5)) {
+		t.Fatalf("expected the low word, got %04x", words[0])
+	}
+}
+
+func TestBoolRegisterOccupiesOneWord(t *testing.T) {
+	server := newTestServer(t)
+	delete(server.mapping.HoldingRegisters, 5)
+
+	if words := registers(server.handle(request(ReadHoldingRegisters, 4, 1))); !slices.Equal(words, []uint16{1}) {
+		t.Fatalf("expected a single word, got %04x", words)
+	}
+
+	if response := server.handle(request(ReadHoldingRegisters, 5, 1)); !bytes.Equal(response, []byte{ReadHoldingRegisters | 0x80, IllegalDataAddress}) {
+		t.Fatalf("expected the address after a bool to be unmapped, got %x", response)
+	}
+}
+
+func TestWriteMultipleRegisters(t *testing.T) {
+	server := newTestServer(t)
+	bits := math.Float32bits(-4.25)
+	pdu := request(WriteMultipleRegisters, 0, 5)
+	pdu = append(pdu, 10)
+	pdu = binary.BigEndian.AppendUint16(pdu, uint16(bits>>16))
+	pdu = binary.BigEndian.AppendUint16(pdu, uint16(bits))
+	pdu = binary.BigEndian.AppendUint16(pdu, 0)
+	pdu = binary.BigEndian.AppendUint16(pdu, 7)
+	pdu = binary.BigEndian.AppendUint16(pdu, 0)
+
+	if response := server.handle(pdu); !bytes.Equal(response, request(WriteMultipleRegisters, 0, 5)) {
+		t.Fatalf("unexpected response %x", response)
+	}
+
+	for name, expected := range map[string]any{"level": float32(-4.25), "count": int32(7), "enabled": false} {
+		if value, _ := server.storage.Read(name); value != expected {
+			t.Fatalf("%s: expected %v, got %v", name, expected, value)
+		}
+	}
+}
+
+func TestWriteSingleRegisterMergesWords(t *testing.T) {
+	server := newTestServer(t)
+
+	if response := server.handle(request(WriteSingleRegister, 3, 5)); !bytes.Equal(response, request(WriteSingleRegister, 3, 5)) {
+		t.Fatalf("unexpected response %x", response)
+	}
+
+	if value, _ := server.storage.Read("count"); value != int32(-65531) {
+		t.Fatalf("expected the high word to be kept, got %v", value)
+	}
+}
+
+func TestWriteOnlyRegister(t *testing.T) {
+	server := newTestServer(t)
+	bits := math.Float32bits(12.5)
+	pdu := append(request(WriteMultipleRegisters, 5, 2), 4)
+	pdu = binary.BigEndian.AppendUint16(pdu, uint16(bits>>16))
+	pdu = binary.BigEndian.AppendUint16(pdu, uint16(bits))
+
+	if response := server.handle(pdu); !bytes.Equal(response, request(WriteMultipleRegisters, 5, 2)) {
+		t.Fatalf("expected a full write to succeed, got %x", response)
+	}
+
+	if value, _ := server.storage.Lookup("setpoint"); mustRead(t, value) != float32(12.5) {
+		t.Fatalf("expected the setpoint to be written")
+	}
+
+	if response := server.handle(request(WriteSingleRegister, 6, 0)); !bytes.Equal(response, []byte{WriteSingleRegister | 0x80, IllegalDataAddress}) {
+		t.Fatalf("expected a partial write to need read access, got %x", response)
+	}
+}
+
+func TestScaledRegister(t *testing.T) {
+	server := newTestServer(t)
+	bits := math.Float32bits(500)
+
+	if words := registers(server.handle(request(ReadHoldingRegisters, 7, 2))); words[0] != uint16(bits>>16) || words[1] != uint16(bits) {
+		t.Fatalf("expected the raw value 500, got %04x", words)
+	}
+
+	bits = math.Float32bits(250)
+	pdu := append(request(WriteMultipleRegisters, 7, 2), 4)
+	pdu = binary.BigEndian.AppendUint16(pdu, uint16(bits>>16))
+	pdu = binary.BigEndian.AppendUint16(pdu, uint16(bits))
+	server.handle(pdu)
+
+	if value, _ := server.storage.Read("pressure"); value != float32(25) {
+		t.Fatalf("expected the engineering value 25, got %v", value)
+	}
+}
+
+func TestCoils(t *testing.T) {
+	server := newTestServer(t)
+
+	if response := server.handle(request(ReadCoils, 0, 2)); !bytes.Equal(response, []byte{ReadCoils, 1, 0b10}) {
+		t.Fatalf("unexpected coils %x", response)
+	}
+
+	if response := server.handle(request(WriteSingleCoil, 0, 0xFF00)); !bytes.Equal(response, request(WriteSingleCoil, 0, 0xFF00)) {
+		t.Fatalf("unexpected response %x", response)
+	}
+
+	if response := server.handle(append(request(WriteMultipleCoils, 0, 2), 1, 0b01)); !bytes.Equal(response, request(WriteMultipleCoils, 0, 2)) {
+		t.Fatalf("unexpected response %x", response)
+	}
+
+	if response := server.handle(request(ReadDiscreteInputs, 0, 1)); !bytes.Equal(response, []byte{ReadDiscreteInputs, 1, 0}) {
+		t.Fatalf("unexpected discrete inputs %x", response)
+	}
+}
+
+func TestExceptions(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		pdu  []byte
+		code byte
+	}{
+		{pdu: []byte{0x2B}, code: IllegalFunction},
+		{pdu: request(ReadHoldingRegisters, 9, 1), code: IllegalDataAddress},
+		{pdu: request(ReadHoldingRegisters, 0, 0), code: IllegalDataValue},
+		{pdu: request(WriteSingleCoil, 0, 0x1234), code: IllegalDataValue},
+		{pdu: request(ReadCoils, 2, 1), code: IllegalDataAddress},
+	}
+
+	for _, test := range tests {
+		if response := server.handle(test.pdu); !bytes.Equal(response, []byte{test.pdu[0] | 0x80, test.code}) {
+			t.Fatalf("%x: expected exception %d, got %x", test.pdu, test.code, response)
+		}
+	}
+}
+
+func TestServeOverTCP(t *testing.T) {
+	server := newTestServer(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+
+	server.Serve(listener)
+	defer server.Stop()
+
+	connection, err := net.Dial("tcp", listener.Addr().String())
+
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	defer connection.Close()
+
+	pdu := request(ReadHoldingRegisters, 2, 2)
+	frame := binary.BigEndian.AppendUint16(nil, 0x0102)
+	frame = binary.BigEndian.AppendUint16(frame, protocolModbus)
+	frame = binary.BigEndian.AppendUint16(frame, uint16(len(pdu)+1))
+	frame = append(append(frame, 0x11), pdu...)
+
+	if _, err := connection.Write(frame); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	response := make([]byte, headerLength+6)
+
+	if _, err := io.ReadFull(connection, response); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	expected := []byte{0x01, 0x02, 0x00, 0x00, 0x00, 0x07, 0x11, ReadHoldingRegisters, 4, 0xFF, 0xFF, 0xFF, 0xFE}
+
+	if !bytes.Equal(response, expected) {
+		t.Fatalf("expected %x, got %x", expected, response)
+	}
+}
+
+func mustRead(t *testing.T, found storage.Resource) any {
+	t.Helper()
+
+	value, err := found.(storage.Reader).Read()
+
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	return value
+}
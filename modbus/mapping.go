@@ -0,0 +1,149 @@
+package modbus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/studiolambda/immersim/storage"
+)
+
+type WordOrder int
+
+const (
+	HighWordFirst WordOrder = iota
+	LowWordFirst
+)
+
+type Mapping struct {
+	HoldingRegisters map[uint16]string
+	InputRegisters   map[uint16]string
+	Coils            map[uint16]string
+	DiscreteInputs   map[uint16]string
+	WordOrder        WordOrder
+}
+
+var (
+	ErrUnsupportedType = errors.New("unsupported register type")
+)
+
+func (server *Server) lookupRegister(registers map[uint16]string, address uint16) (string, uint16, bool) {
+	if resource, ok := registers[address]; ok {
+		return resource, address, true
+	}
+
+	if address > 0 {
+		if resource, ok := registers[address-1]; ok && server.width(resource) == 2 {
+			return resource, address - 1, true
+		}
+	}
+
+	return "", 0, false
+}
+
+func (server *Server) like(name string) (any, error) {
+	info, ok := server.storage.Inspect(name)
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storage.ErrResourceNotFound, name)
+	}
+
+	switch info.Type {
+	case "int32":
+		return int32(0), nil
+	case "float32":
+		return float32(0), nil
+	case "bool":
+		return false, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, info.Type)
+}
+
+func (server *Server) width(name string) int {
+	if like, err := server.like(name); err == nil {
+		if _, ok := like.(bool); ok {
+			return 1
+		}
+	}
+
+	return 2
+}
+
+func convert(value any, transform func(float64) float64) any {
+	switch v := value.(type) {
+	case int32:
+		return int32(math.Round(transform(float64(v))))
+	case float32:
+		return float32(transform(float64(v)))
+	}
+
+	return value
+}
+
+func (server *Server) raw(name string, value any) any {
+	if metadata, ok := server.storage.Metadata(name); ok && metadata.Raw != nil {
+		return convert(value, metadata.Unscale)
+	}
+
+	return value
+}
+
+func (server *Server) engineering(name string, value any) any {
+	if metadata, ok := server.storage.Metadata(name); ok && metadata.Raw != nil {
+		return convert(value, metadata.Scale)
+	}
+
+	return value
+}
+
+func encodeWords(value any, order WordOrder) ([]uint16, error) {
+	var bits uint32
+
+	switch v := value.(type) {
+	case int32:
+		bits = uint32(v)
+	case float32:
+		bits = math.Float32bits(v)
+	case bool:
+		if v {
+			return []uint16{1}, nil
+		}
+
+		return []uint16{0}, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, value)
+	}
+
+	high := uint16(bits >> 16)
+	low := uint16(bits)
+
+	if order == LowWordFirst {
+		return []uint16{low, high}, nil
+	}
+
+	return []uint16{high, low}, nil
+}
+
+func decodeWords(words []uint16, like any, order WordOrder) (any, error) {
+	if _, ok := like.(bool); ok {
+		return words[0] != 0, nil
+	}
+
+	high, low := words[0], words[1]
+
+	if order == LowWordFirst {
+		high, low = words[1], words[0]
+	}
+
+	bits := uint32(high)<<16 | uint32(low)
+
+	switch like.(type) {
+	case int32:
+		return int32(bits), nil
+	case float32:
+		return math.Float32frombits(bits), nil
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, like)
+}
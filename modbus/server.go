@@ -0,0 +1,150 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/studiolambda/immersim/storage"
+)
+
+type Server struct {
+	storage     *storage.Storage
+	mapping     Mapping
+	listener    net.Listener
+	connections map[net.Conn]struct{}
+	mutex       sync.Mutex
+	wg          sync.WaitGroup
+}
+
+const (
+	headerLength   = 7
+	maxPDULength   = 253
+	protocolModbus = 0
+)
+
+func NewServer(storage *storage.Storage, mapping Mapping) *Server {
+	return &Server{
+		storage:     storage,
+		mapping:     mapping,
+		listener:    nil,
+		connections: make(map[net.Conn]struct{}),
+		mutex:       sync.Mutex{},
+		wg:          sync.WaitGroup{},
+	}
+}
+
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+
+	if err != nil {
+		return err
+	}
+
+	server.Serve(listener)
+
+	return nil
+}
+
+func (server *Server) Serve(listener net.Listener) {
+	server.mutex.Lock()
+	server.listener = listener
+	server.mutex.Unlock()
+
+	server.wg.Add(1)
+	go server.accept(listener)
+}
+
+func (server *Server) Address() net.Addr {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.listener == nil {
+		return nil
+	}
+
+	return server.listener.Addr()
+}
+
+func (server *Server) Stop() {
+	server.mutex.Lock()
+
+	if server.listener != nil {
+		server.listener.Close()
+		server.listener = nil
+	}
+
+	for connection := range server.connections {
+		connection.Close()
+	}
+
+	server.mutex.Unlock()
+	server.wg.Wait()
+}
+
+func (server *Server) accept(listener net.Listener) {
+	defer server.wg.Done()
+
+	for {
+		connection, err := listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		server.mutex.Lock()
+		server.connections[connection] = struct{}{}
+		server.mutex.Unlock()
+
+		server.wg.Add(1)
+		go server.serve(connection)
+	}
+}
+
+func (server *Server) serve(connection net.Conn) {
+	defer server.wg.Done()
+	defer func() {
+		server.mutex.Lock()
+		delete(server.connections, connection)
+		server.mutex.Unlock()
+
+		connection.Close()
+	}()
+
+	header := make([]byte, headerLength)
+
+	for {
+		if _, err := io.ReadFull(connection, header); err != nil {
+			return
+		}
+
+		transaction := binary.BigEndian.Uint16(header[0:2])
+		protocol := binary.BigEndian.Uint16(header[2:4])
+		length := binary.BigEndian.Uint16(header[4:6])
+		unit := header[6]
+
+		if protocol != protocolModbus || length < 2 || length > maxPDULength+1 {
+			return
+		}
+
+		request := make([]byte, length-1)
+
+		if _, err := io.ReadFull(connection, request); err != nil {
+			return
+		}
+
+		response := server.handle(request)
+		frame := make([]byte, headerLength, headerLength+len(response))
+
+		binary.BigEndian.PutUint16(frame[0:2], transaction)
+		binary.BigEndian.PutUint16(frame[2:4], protocolModbus)
+		binary.BigEndian.PutUint16(frame[4:6], uint16(len(response)+1))
+		frame[6] = unit
+		frame = append(frame, response...)
+
+		if _, err := connection.Write(frame); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/studiolambda/immersim"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/storage"
+)
+
+const (
+	DefaultTopic = "immersim/{resource}"
+)
+
+var (
+	ErrReservedName = errors.New("resource name collides with a bridge command topic")
+)
+
+type Bridge struct {
+	application *immersim.Application
+	client      Client
+	topic       string
+	resources   []string
+	listeners   map[string]chan any
+	wg          sync.WaitGroup
+}
+
+type errorPayload struct {
+	Resource string `json:"resource"`
+	Error    string `json:"error"`
+}
+
+func NewBridge(application *immersim.Application, client Client, topic string, resources []string) *Bridge {
+	if topic == "" {
+		topic = DefaultTopic
+	}
+
+	return &Bridge{
+		application: application,
+		client:      client,
+		topic:       topic,
+		resources:   resources,
+		listeners:   make(map[string]chan any),
+		wg:          sync.WaitGroup{},
+	}
+}
+
+func (bridge *Bridge) Topic(resource string) string {
+	return strings.ReplaceAll(bridge.topic, "{resource}", resource)
+}
+
+func (bridge *Bridge) Start() error {
+	for _, resource := range bridge.resources {
+		if reserved(resource) {
+			return fmt.Errorf("%w: %s", ErrReservedName, resource)
+		}
+	}
+
+	for _, resource := range bridge.resources {
+		listener := make(chan any, 16)
+		bridge.listeners[resource] = listener
+
+		bridge.wg.Add(1)
+		go bridge.forward(listener)
+
+		bridge.application.SubscribeChangesWith(resource, listener, event.DropOldest, event.DefaultCapacity)
+
+		if err := bridge.client.Subscribe(bridge.Topic(resource)+"/set", bridge.set(resource)); err != nil {
+			bridge.Stop()
+			return err
+		}
+
+		if err := bridge.client.Subscribe(bridge.Topic(resource)+"/action/+", bridge.action(resource)); err != nil {
+			bridge.Stop()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func reserved(resource string) bool {
+	parent := storage.Dir(resource)
+
+	if parent == "" {
+		return false
+	}
+
+	switch storage.Base(resource) {
+	case "set", "error":
+		return true
+	}
+
+	return strings.HasSuffix(parent, storage.Separator+"action")
+}
+
+func (bridge *Bridge) Stop() {
+	for resource, listener := range bridge.listeners {
+		bridge.client.Unsubscribe(bridge.Topic(resource) + "/set")
+		bridge.client.Unsubscribe(bridge.Topic(resource) + "/action/+")
+		bridge.application.UnsubscribeChanges(resource, listener)
+		close(listener)
+	}
+
+	bridge.wg.Wait()
+	bridge.listeners = make(map[string]chan any)
+}
+
+func (bridge *Bridge) forward(listener chan any) {
+	defer bridge.wg.Done()
+
+	for payload := range listener {
+		changed, ok := payload.(event.ChangedPayload)
+
+		if !ok {
+			continue
+		}
+
+		if data, err := json.Marshal(changed); err == nil {
+			bridge.client.Publish(bridge.Topic(changed.Resource), data)
+		}
+	}
+}
+
+func (bridge *Bridge) set(resource string) Handler {
+	return func(topic string, payload []byte) {
+		if err := bridge.application.WriteJSON(resource, payload); err != nil {
+			bridge.fail(resource, err)
+		}
+	}
+}
+
+func (bridge *Bridge) action(resource string) Handler {
+	return func(topic string, payload []byte) {
+		name := topic[strings.LastIndex(topic, "/")+1:]
+
+		var value any
+
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &value); err != nil {
+				bridge.fail(resource, err)
+				return
+			}
+		}
+
+		bridge.application.Action(resource, name, value)
+	}
+}
+
+func (bridge *Bridge) fail(resource string, err error) {
+	if data, err := json.Marshal(errorPayload{Resource: resource, Error: err.Error()}); err == nil {
+		bridge.client.Publish(bridge.Topic(resource)+"/error", data)
+	}
+}
@@ -0,0 +1,182 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim"
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type message struct {
+	topic   string
+	payload []byte
+}
+
+func startBridge(t *testing.T) (*immersim.Application, *Broker, *Bridge) {
+	t.Helper()
+
+	application := immersim.NewApplication(
+		storage.NewStorage(map[string]storage.Resource{
+			"tank/level": resource.NewStatic[float32](1),
+		}),
+		event.NewEvents(time.Second),
+		clock.NewReal(),
+	)
+
+	if err := application.Start(context.Background()); err != nil {
+		t.Fatalf("start application: %v", err)
+	}
+
+	broker := NewBroker()
+	bridge := NewBridge(application, broker.Client(), "", []string{"tank/level"})
+
+	if err := bridge.Start(); err != nil {
+		t.Fatalf("start bridge: %v", err)
+	}
+
+	t.Cleanup(func() {
+		bridge.Stop()
+		application.Stop(context.Background())
+	})
+
+	return application, broker, bridge
+}
+
+func listen(t *testing.T, client *MemoryClient, filter string) <-chan message {
+	t.Helper()
+
+	messages := make(chan message, 16)
+
+	if err := client.Subscribe(filter, func(topic string, payload []byte) {
+		messages <- message{topic: topic, payload: payload}
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	return messages
+}
+
+func receive(t *testing.T, messages <-chan message) message {
+	t.Helper()
+
+	select {
+	case received := <-messages:
+		return received
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a message")
+	}
+
+	return message{}
+}
+
+func TestBridgeSetPublishesChange(t *testing.T) {
+	application, broker, bridge := startBridge(t)
+	dashboard := broker.Client()
+	changes := listen(t, dashboard, bridge.Topic("tank/level"))
+
+	if err := dashboard.Publish(bridge.Topic("tank/level")+"/set", []byte("2.5")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	received := receive(t, changes)
+
+	if received.topic != "immersim/tank/level" {
+		t.Fatalf("unexpected topic %q", received.topic)
+	}
+
+	var payload event.ChangedPayload
+
+	if err := json.Unmarshal(received.payload, &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if payload.Resource != "tank/level" || payload.Value != 2.5 {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	if value, _ := application.Read("tank/level"); value != float32(2.5) {
+		t.Fatalf("expected the write to reach storage, got %v", value)
+	}
+}
+
+func TestBridgeSetReportsErrors(t *testing.T) {
+	_, broker, bridge := startBridge(t)
+	dashboard := broker.Client()
+	errs := listen(t, dashboard, bridge.Topic("tank/level")+"/error")
+
+	dashboard.Publish(bridge.Topic("tank/level")+"/set", []byte(`"full"`))
+
+	var payload errorPayload
+
+	if err := json.Unmarshal(receive(t, errs).payload, &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if payload.Resource != "tank/level" || payload.Error == "" {
+		t.Fatalf("unexpected error payload %+v", payload)
+	}
+}
+
+func TestBridgeAction(t *testing.T) {
+	application, broker, bridge := startBridge(t)
+	actions := make(chan any, 1)
+	application.SubscribeAction("tank/level", "fill", actions)
+
+	defer application.UnsubscribeAction("tank/level", "fill", actions)
+
+	broker.Client().Publish(bridge.Topic("tank/level")+"/action/fill", []byte(`{"rate":3}`))
+
+	select {
+	case payload := <-actions:
+		if rate := payload.(map[string]any)["rate"]; rate != float64(3) {
+			t.Fatalf("unexpected action payload %v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the action")
+	}
+}
+
+func TestBridgeStopUnsubscribes(t *testing.T) {
+	application, broker, bridge := startBridge(t)
+	bridge.Stop()
+
+	broker.Client().Publish(bridge.Topic("tank/level")+"/set", []byte("7"))
+
+	if value, _ := application.Read("tank/level"); value != float32(1) {
+		t.Fatalf("expected no write after stop, got %v", value)
+	}
+}
+
+func TestBridgeRejectsReservedNames(t *testing.T) {
+	application, broker, _ := startBridge(t)
+
+	tests := []struct {
+		name     string
+		reserved bool
+	}{
+		{name: "tank/set", reserved: true},
+		{name: "tank/error", reserved: true},
+		{name: "tank/action/fill", reserved: true},
+		{name: "set", reserved: false},
+		{name: "action/fill", reserved: false},
+		{name: "tank/action", reserved: false},
+		{name: "tank/setpoint", reserved: false},
+	}
+
+	for _, test := range tests {
+		bridge := NewBridge(application, broker.Client(), "", []string{"tank/level", test.name})
+		err := bridge.Start()
+		bridge.Stop()
+
+		if reserved := errors.Is(err, ErrReservedName); reserved != test.reserved {
+			t.Fatalf("%s: expected reserved %v, got %v", test.name, test.reserved, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package mqtt
+
+import (
+	"slices"
+	"sync"
+)
+
+type Broker struct {
+	mutex         sync.RWMutex
+	subscriptions []*brokerSubscription
+}
+
+type brokerSubscription struct {
+	client  *MemoryClient
+	filter  string
+	handler Handler
+}
+
+type MemoryClient struct {
+	broker *Broker
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		mutex:         sync.RWMutex{},
+		subscriptions: nil,
+	}
+}
+
+func (broker *Broker) Client() *MemoryClient {
+	return &MemoryClient{
+		broker: broker,
+	}
+}
+
+func (broker *Broker) Publish(topic string, payload []byte) {
+	broker.mutex.RLock()
+
+	var handlers []Handler
+
+	for _, subscription := range broker.subscriptions {
+		if Match(subscription.filter, topic) {
+			handlers = append(handlers, subscription.handler)
+		}
+	}
+
+	broker.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(topic, slices.Clone(payload))
+	}
+}
+
+func (client *MemoryClient) Publish(topic string, payload []byte) error {
+	client.broker.Publish(topic, payload)
+
+	return nil
+}
+
+func (client *MemoryClient) Subscribe(filter string, handler Handler) error {
+	client.broker.mutex.Lock()
+	defer client.broker.mutex.Unlock()
+
+	client.broker.subscriptions = append(client.broker.subscriptions, &brokerSubscription{
+		client:  client,
+		filter:  filter,
+		handler: handler,
+	})
+
+	return nil
+}
+
+func (client *MemoryClient) Unsubscribe(filter string) error {
+	client.broker.mutex.Lock()
+	defer client.broker.mutex.Unlock()
+
+	client.broker.subscriptions = slices.DeleteFunc(client.broker.subscriptions, func(subscription *brokerSubscription) bool {
+		return subscription.client == client && subscription.filter == filter
+	})
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package mqtt
+
+import (
+	"strings"
+)
+
+type Handler func(topic string, payload []byte)
+
+type Client interface {
+	Publish(topic string, payload []byte) error
+	Subscribe(filter string, handler Handler) error
+	Unsubscribe(filter string) error
+}
+
+func Match(filter string, topic string) bool {
+	filters := strings.Split(filter, "/")
+	topics := strings.Split(topic, "/")
+
+	for i, level := range filters {
+		switch {
+		case level == "#":
+			return true
+		case i >= len(topics):
+			return false
+		case level != "+" && level != topics[i]:
+			return false
+		}
+	}
+
+	return len(filters) == len(topics)
+}
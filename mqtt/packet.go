@@ -0,0 +1,172 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	packetConnect     byte = 1
+	packetConnack     byte = 2
+	packetPublish     byte = 3
+	packetPuback      byte = 4
+	packetSubscribe   byte = 8
+	packetSuback      byte = 9
+	packetUnsubscribe byte = 10
+	packetUnsuback    byte = 11
+	packetPingreq     byte = 12
+	packetPingresp    byte = 13
+	packetDisconnect  byte = 14
+)
+
+const (
+	maxRemainingLength = 268_435_455
+)
+
+var (
+	ErrMalformedPacket = errors.New("malformed mqtt packet")
+)
+
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+func appendVarint(buffer []byte, value int) []byte {
+	for {
+		digit := byte(value % 128)
+		value /= 128
+
+		if value > 0 {
+			digit |= 0x80
+		}
+
+		buffer = append(buffer, digit)
+
+		if value == 0 {
+			return buffer
+		}
+	}
+}
+
+func appendString(buffer []byte, value string) []byte {
+	buffer = binary.BigEndian.AppendUint16(buffer, uint16(len(value)))
+
+	return append(buffer, value...)
+}
+
+func encodePacket(kind byte, flags byte, body []byte) []byte {
+	buffer := make([]byte, 0, len(body)+5)
+	buffer = append(buffer, kind<<4|flags)
+	buffer = appendVarint(buffer, len(body))
+
+	return append(buffer, body...)
+}
+
+func readVarint(reader io.ByteReader) (int, error) {
+	value := 0
+	multiplier := 1
+
+	for i := 0; i < 4; i++ {
+		digit, err := reader.ReadByte()
+
+		if err != nil {
+			return 0, err
+		}
+
+		value += int(digit&0x7F) * multiplier
+		multiplier *= 128
+
+		if digit&0x80 == 0 {
+			return value, nil
+		}
+	}
+
+	return 0, ErrMalformedPacket
+}
+
+func readPacket(reader *bufio.Reader) (packet, error) {
+	header, err := reader.ReadByte()
+
+	if err != nil {
+		return packet{}, err
+	}
+
+	length, err := readVarint(reader)
+
+	if err != nil {
+		return packet{}, err
+	}
+
+	if length > maxRemainingLength {
+		return packet{}, ErrMalformedPacket
+	}
+
+	body := make([]byte, length)
+
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return packet{}, err
+	}
+
+	return packet{kind: header >> 4, flags: header & 0x0F, body: body}, nil
+}
+
+type packetReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (reader *packetReader) take(length int) []byte {
+	if reader.err != nil {
+		return nil
+	}
+
+	if length < 0 || reader.offset+length > len(reader.data) {
+		reader.err = ErrMalformedPacket
+		return nil
+	}
+
+	value := reader.data[reader.offset : reader.offset+length]
+	reader.offset += length
+
+	return value
+}
+
+func (reader *packetReader) ReadByte() (byte, error) {
+	if value := reader.take(1); value != nil {
+		return value[0], nil
+	}
+
+	return 0, reader.err
+}
+
+func (reader *packetReader) readUint16() uint16 {
+	if value := reader.take(2); value != nil {
+		return binary.BigEndian.Uint16(value)
+	}
+
+	return 0
+}
+
+func (reader *packetReader) readString() string {
+	return string(reader.take(int(reader.readUint16())))
+}
+
+func (reader *packetReader) skipProperties() {
+	length, err := readVarint(reader)
+
+	if err != nil {
+		reader.err = err
+		return
+	}
+
+	reader.take(length)
+}
+
+func (reader *packetReader) rest() []byte {
+	return reader.take(len(reader.data) - reader.offset)
+}
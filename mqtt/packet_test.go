@@ -0,0 +1,89 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVarint(t *testing.T) {
+	tests := []struct {
+		value   int
+		encoded []byte
+	}{
+		{value: 0, encoded: []byte{0x00}},
+		{value: 127, encoded: []byte{0x7F}},
+		{value: 128, encoded: []byte{0x80, 0x01}},
+		{value: 16_383, encoded: []byte{0xFF, 0x7F}},
+		{value: 16_384, encoded: []byte{0x80, 0x80, 0x01}},
+		{value: maxRemainingLength, encoded: []byte{0xFF, 0xFF, 0xFF, 0x7F}},
+	}
+
+	for _, test := range tests {
+		encoded := appendVarint(nil, test.value)
+
+		if !bytes.Equal(encoded, test.encoded) {
+			t.Fatalf("%d: expected %x, got %x", test.value, test.encoded, encoded)
+		}
+
+		decoded, err := readVarint(bytes.NewReader(encoded))
+
+		if err != nil || decoded != test.value {
+			t.Fatalf("%d: decoded %d, %v", test.value, decoded, err)
+		}
+	}
+}
+
+func TestVarintTooLong(t *testing.T) {
+	if _, err := readVarint(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x01})); !errors.Is(err, ErrMalformedPacket) {
+		t.Fatalf("expected %v, got %v", ErrMalformedPacket, err)
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	body := appendString(nil, "immersim/tank")
+	body = append(body, []byte(`{"value":1}`)...)
+
+	read, err := readPacket(bufio.NewReader(bytes.NewReader(encodePacket(packetPublish, 0x01, body))))
+
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if read.kind != packetPublish || read.flags != 0x01 || !bytes.Equal(read.body, body) {
+		t.Fatalf("unexpected packet %+v", read)
+	}
+
+	reader := &packetReader{data: read.body}
+
+	if topic := reader.readString(); topic != "immersim/tank" {
+		t.Fatalf("unexpected topic %q", topic)
+	}
+
+	if payload := reader.rest(); string(payload) != `{"value":1}` {
+		t.Fatalf("unexpected payload %q", payload)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter  string
+		topic   string
+		matches bool
+	}{
+		{filter: "immersim/tank/set", topic: "immersim/tank/set", matches: true},
+		{filter: "immersim/tank/set", topic: "immersim/pump/set", matches: false},
+		{filter: "immersim/+/set", topic: "immersim/tank/set", matches: true},
+		{filter: "immersim/tank/action/+", topic: "immersim/tank/action/fill", matches: true},
+		{filter: "immersim/tank/action/+", topic: "immersim/tank/action", matches: false},
+		{filter: "immersim/#", topic: "immersim/tank/level", matches: true},
+		{filter: "immersim/tank", topic: "immersim/tank/level", matches: false},
+	}
+
+	for _, test := range tests {
+		if got := Match(test.filter, test.topic); got != test.matches {
+			t.Fatalf("%s against %s: expected %t, got %t", test.filter, test.topic, test.matches, got)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package mqtt
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+type Version byte
+
+const (
+	Version311 Version = 4
+	Version5   Version = 5
+)
+
+type ClientOptions struct {
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+	Timeout   time.Duration
+	Version   Version
+}
+
+type TCPClient struct {
+	connection net.Conn
+	options    ClientOptions
+	handlers   map[string]Handler
+	pending    map[uint16]chan []byte
+	packetID   uint16
+	mutex      sync.Mutex
+	writeMutex sync.Mutex
+	quit       chan struct{}
+	wg         sync.WaitGroup
+}
+
+var (
+	ErrConnectionRefused = errors.New("mqtt connection refused")
+	ErrSubscribeRejected = errors.New("mqtt subscription rejected")
+	ErrTimeout           = errors.New("mqtt operation timed out")
+	ErrClosed            = errors.New("mqtt client closed")
+)
+
+func Dial(address string, options ClientOptions) (*TCPClient, error) {
+	if options.Version == 0 {
+		options.Version = Version311
+	}
+
+	if options.Timeout == 0 {
+		options.Timeout = 5 * time.Second
+	}
+
+	if options.ClientID == "" {
+		suffix := make([]byte, 6)
+		rand.Read(suffix)
+		options.ClientID = "immersim-" + hex.EncodeToString(suffix)
+	}
+
+	connection, err := net.DialTimeout("tcp", address, options.Timeout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := &TCPClient{
+		connection: connection,
+		options:    options,
+		handlers:   make(map[string]Handler),
+		pending:    make(map[uint16]chan []byte),
+		packetID:   0,
+		mutex:      sync.Mutex{},
+		writeMutex: sync.Mutex{},
+		quit:       make(chan struct{}),
+		wg:         sync.WaitGroup{},
+	}
+
+	reader := bufio.NewReader(connection)
+
+	if err := client.connect(reader); err != nil {
+		connection.Close()
+		return nil, err
+	}
+
+	client.wg.Add(1)
+	go client.read(reader)
+
+	if options.KeepAlive > 0 {
+		client.wg.Add(1)
+		go client.ping()
+	}
+
+	return client, nil
+}
+
+func (client *TCPClient) connect(reader *bufio.Reader) error {
+	flags := byte(0x02)
+	body := appendString(nil, "MQTT")
+	payload := appendString(nil, client.options.ClientID)
+
+	if client.options.Username != "" {
+		flags |= 0x80
+		payload = appendString(payload, client.options.Username)
+	}
+
+	if client.options.Password != "" {
+		flags |= 0x40
+		payload = appendString(payload, client.options.Password)
+	}
+
+	body = append(body, byte(client.options.Version), flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(client.options.KeepAlive/time.Second))
+
+	if client.options.Version == Version5 {
+		body = appendVarint(body, 0)
+	}
+
+	client.connection.SetDeadline(time.Now().Add(client.options.Timeout))
+	defer client.connection.SetDeadline(time.Time{})
+
+	if err := client.write(packetConnect, 0, append(body, payload...)); err != nil {
+		return err
+	}
+
+	response, err := readPacket(reader)
+
+	if err != nil {
+		return err
+	}
+
+	if response.kind != packetConnack || len(response.body) < 2 {
+		return ErrMalformedPacket
+	}
+
+	if code := response.body[1]; code != 0 {
+		return fmt.Errorf("%w: code %d", ErrConnectionRefused, code)
+	}
+
+	return nil
+}
+
+func (client *TCPClient) Publish(topic string, payload []byte) error {
+	body := appendString(nil, topic)
+
+	if client.options.Version == Version5 {
+		body = appendVarint(body, 0)
+	}
+
+	return client.write(packetPublish, 0, append(body, payload...))
+}
+
+func (client *TCPClient) Subscribe(filter string, handler Handler) error {
+	client.mutex.Lock()
+	client.handlers[filter] = handler
+	client.mutex.Unlock()
+
+	response, err := client.request(packetSubscribe, func(body []byte) []byte {
+		body = appendString(body, filter)
+
+		return append(body, 0)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(response) == 0 || response[0] >= 0x80 {
+		client.mutex.Lock()
+		delete(client.handlers, filter)
+		client.mutex.Unlock()
+
+		return fmt.Errorf("%w: %s", ErrSubscribeRejected, filter)
+	}
+
+	return nil
+}
+
+func (client *TCPClient) Unsubscribe(filter string) error {
+	client.mutex.Lock()
+	delete(client.handlers, filter)
+	client.mutex.Unlock()
+
+	_, err := client.request(packetUnsubscribe, func(body []byte) []byte {
+		return appendString(body, filter)
+	})
+
+	return err
+}
+
+func (client *TCPClient) Close() error {
+	select {
+	case <-client.quit:
+		return nil
+	default:
+	}
+
+	close(client.quit)
+	client.write(packetDisconnect, 0, nil)
+	err := client.connection.Close()
+	client.wg.Wait()
+
+	return err
+}
+
+func (client *TCPClient) request(kind byte, payload func(body []byte) []byte) ([]byte, error) {
+	client.mutex.Lock()
+	client.packetID++
+
+	if client.packetID == 0 {
+		client.packetID++
+	}
+
+	id := client.packetID
+	response := make(chan []byte, 1)
+	client.pending[id] = response
+	client.mutex.Unlock()
+
+	defer func() {
+		client.mutex.Lock()
+		delete(client.pending, id)
+		client.mutex.Unlock()
+	}()
+
+	body := binary.BigEndian.AppendUint16(nil, id)
+
+	if client.options.Version == Version5 {
+		body = appendVarint(body, 0)
+	}
+
+	if err := client.write(kind, 0x02, payload(body)); err != nil {
+		return nil, err
+	}
+
+	select {
+	case codes := <-response:
+		return codes, nil
+	case <-time.After(client.options.Timeout):
+		return nil, ErrTimeout
+	case <-client.quit:
+		return nil, ErrClosed
+	}
+}
+
+func (client *TCPClient) write(kind byte, flags byte, body []byte) error {
+	client.writeMutex.Lock()
+	defer client.writeMutex.Unlock()
+
+	_, err := client.connection.Write(encodePacket(kind, flags, body))
+
+	return err
+}
+
+func (client *TCPClient) read(reader *bufio.Reader) {
+	defer client.wg.Done()
+
+	for {
+		packet, err := readPacket(reader)
+
+		if err != nil {
+			return
+		}
+
+		switch packet.kind {
+		case packetPublish:
+			client.receive(packet)
+		case packetSuback, packetUnsuback:
+			body := &packetReader{data: packet.body}
+			id := body.readUint16()
+
+			if client.options.Version == Version5 {
+				body.skipProperties()
+			}
+
+			codes := body.rest()
+
+			client.mutex.Lock()
+			response, ok := client.pending[id]
+			client.mutex.Unlock()
+
+			if ok && body.err == nil {
+				response <- slices.Clone(codes)
+			}
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+func (client *TCPClient) receive(packet packet) {
+	body := &packetReader{data: packet.body}
+	topic := body.readString()
+	qos := (packet.flags >> 1) & 0x03
+	id := uint16(0)
+
+	if qos > 0 {
+		id = body.readUint16()
+	}
+
+	if client.options.Version == Version5 {
+		body.skipProperties()
+	}
+
+	payload := body.rest()
+
+	if body.err != nil {
+		return
+	}
+
+	if qos == 1 {
+		client.write(packetPuback, 0, binary.BigEndian.AppendUint16(nil, id))
+	}
+
+	client.mutex.Lock()
+
+	var handlers []Handler
+
+	for filter, handler := range client.handlers {
+		if Match(filter, topic) {
+			handlers = append(handlers, handler)
+		}
+	}
+
+	client.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(topic, payload)
+	}
+}
+
+func (client *TCPClient) ping() {
+	defer client.wg.Done()
+
+	ticker := time.NewTicker(client.options.KeepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := client.write(packetPingreq, 0, nil); err != nil {
+				return
+			}
+		case <-client.quit:
+			return
+		}
+	}
+}
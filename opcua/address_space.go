@@ -0,0 +1,465 @@
+package opcua
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/fault"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type reference struct {
+	referenceType uint32
+	forward       bool
+	target        NodeId
+}
+
+type node struct {
+	id             NodeId
+	class          uint32
+	browseName     qualifiedName
+	displayName    localizedText
+	typeDefinition NodeId
+	dataType       NodeId
+	valueRank      int32
+	accessLevel    byte
+	resource       string
+	owner          string
+	value          any
+	references     []reference
+	call           func() StatusCode
+}
+
+type addressSpace struct {
+	storage *storage.Storage
+	events  *event.Events
+	nodes   map[NodeId]*node
+	mutex   sync.RWMutex
+}
+
+var referenceSupertypes = map[uint32]uint32{
+	idHierarchicalReferences: idReferences,
+	32:                       idReferences,
+	idHasChild:               idHierarchicalReferences,
+	idOrganizes:              idHierarchicalReferences,
+	idAggregates:             idHasChild,
+	idHasProperty:            idAggregates,
+	idHasComponent:           idAggregates,
+	idHasTypeDefinition:      32,
+}
+
+func newAddressSpace(storage *storage.Storage, events *event.Events) *addressSpace {
+	space := &addressSpace{
+		storage: storage,
+		events:  events,
+		nodes:   make(map[NodeId]*node),
+		mutex:   sync.RWMutex{},
+	}
+
+	space.add(&node{
+		id:             numericNodeId(idRootFolder),
+		class:          nodeClassObject,
+		browseName:     qualifiedName{name: "Root"},
+		displayName:    localizedText{text: "Root"},
+		typeDefinition: numericNodeId(idFolderType),
+	})
+
+	space.add(&node{
+		id:             numericNodeId(idObjectsFolder),
+		class:          nodeClassObject,
+		browseName:     qualifiedName{name: "Objects"},
+		displayName:    localizedText{text: "Objects"},
+		typeDefinition: numericNodeId(idFolderType),
+	})
+
+	space.add(&node{
+		id:             numericNodeId(idServer),
+		class:          nodeClassObject,
+		browseName:     qualifiedName{name: "Server"},
+		displayName:    localizedText{text: "Server"},
+		typeDefinition: numericNodeId(idServerType),
+	})
+
+	space.add(&node{
+		id:             numericNodeId(idNamespaceArray),
+		class:          nodeClassVariable,
+		browseName:     qualifiedName{name: "NamespaceArray"},
+		displayName:    localizedText{text: "NamespaceArray"},
+		typeDefinition: numericNodeId(idPropertyType),
+		dataType:       numericNodeId(idString),
+		valueRank:      1,
+		accessLevel:    accessLevelRead,
+		value:          []string{"http://opcfoundation.org/UA/", namespaceURI},
+	})
+
+	space.add(&node{
+		id:             numericNodeId(idServerArray),
+		class:          nodeClassVariable,
+		browseName:     qualifiedName{name: "ServerArray"},
+		displayName:    localizedText{text: "ServerArray"},
+		typeDefinition: numericNodeId(idPropertyType),
+		dataType:       numericNodeId(idString),
+		valueRank:      1,
+		accessLevel:    accessLevelRead,
+		value:          []string{applicationURI},
+	})
+
+	space.link(numericNodeId(idRootFolder), idOrganizes, numericNodeId(idObjectsFolder))
+	space.link(numericNodeId(idObjectsFolder), idOrganizes, numericNodeId(idServer))
+	space.link(numericNodeId(idServer), idHasProperty, numericNodeId(idNamespaceArray))
+	space.link(numericNodeId(idServer), idHasProperty, numericNodeId(idServerArray))
+
+	for _, folder := range storage.Folders() {
+		space.addFolder(folder)
+	}
+
+	for _, name := range storage.Names() {
+		space.addResource(name)
+	}
+
+	return space
+}
+
+func (space *addressSpace) lookup(id NodeId) (*node, bool) {
+	space.mutex.RLock()
+	defer space.mutex.RUnlock()
+
+	node, ok := space.nodes[id]
+
+	return node, ok
+}
+
+func (space *addressSpace) references(id NodeId) ([]reference, bool) {
+	space.mutex.RLock()
+	defer space.mutex.RUnlock()
+
+	node, ok := space.nodes[id]
+
+	if !ok {
+		return nil, false
+	}
+
+	return slices.Clone(node.references), true
+}
+
+func (space *addressSpace) sync(name string) {
+	space.mutex.Lock()
+	defer space.mutex.Unlock()
+
+	for id, node := range space.nodes {
+		if node.owner == name {
+			space.remove(id)
+		}
+	}
+
+	if _, ok := space.storage.Lookup(name); ok {
+		space.ensureFolder(storage.Dir(name))
+		space.addResource(name)
+		return
+	}
+
+	space.prune(storage.Dir(name))
+}
+
+func (space *addressSpace) ensureFolder(folder string) {
+	if _, ok := space.nodes[folderNodeId(folder)]; ok {
+		return
+	}
+
+	space.ensureFolder(storage.Dir(folder))
+	space.addFolder(folder)
+}
+
+func (space *addressSpace) prune(folder string) {
+	for ; folder != ""; folder = storage.Dir(folder) {
+		for _, reference := range space.nodes[folderNodeId(folder)].references {
+			if reference.forward && isSubtype(reference.referenceType, idHierarchicalReferences) {
+				return
+			}
+		}
+
+		space.remove(folderNodeId(folder))
+	}
+}
+
+func (space *addressSpace) remove(id NodeId) {
+	removed := space.nodes[id]
+	delete(space.nodes, id)
+
+	for _, link := range removed.references {
+		if target, ok := space.nodes[link.target]; ok {
+			target.references = slices.DeleteFunc(target.references, func(candidate reference) bool {
+				return candidate.target == id
+			})
+		}
+	}
+}
+
+func (space *addressSpace) add(node *node) {
+	space.nodes[node.id] = node
+
+	if !node.typeDefinition.isNull() {
+		node.references = append(node.references, reference{
+			referenceType: idHasTypeDefinition,
+			forward:       true,
+			target:        node.typeDefinition,
+		})
+	}
+}
+
+func (space *addressSpace) link(source NodeId, referenceType uint32, target NodeId) {
+	space.nodes[source].references = append(space.nodes[source].references, reference{
+		referenceType: referenceType,
+		forward:       true,
+		target:        target,
+	})
+
+	space.nodes[target].references = append(space.nodes[target].references, reference{
+		referenceType: referenceType,
+		forward:       false,
+		target:        source,
+	})
+}
+
+func (space *addressSpace) addFolder(folder string) {
+	space.add(&node{
+		id:             folderNodeId(folder),
+		class:          nodeClassObject,
+		browseName:     qualifiedName{namespace: 1, name: storage.Base(folder)},
+		displayName:    localizedText{text: storage.Base(folder)},
+		typeDefinition: numericNodeId(idFolderType),
+	})
+
+	space.link(folderNodeId(storage.Dir(folder)), idOrganizes, folderNodeId(folder))
+}
+
+func folderNodeId(folder string) NodeId {
+	if folder == "" {
+		return numericNodeId(idObjectsFolder)
+	}
+
+	return StringNodeId(1, folder+storage.Separator)
+}
+
+func (space *addressSpace) addResource(name string) {
+	res, _ := space.storage.Lookup(name)
+	variable := &node{
+		id:             StringNodeId(1, name),
+		class:          nodeClassVariable,
+		browseName:     qualifiedName{namespace: 1, name: storage.Base(name)},
+		displayName:    localizedText{text: storage.Base(name)},
+		typeDefinition: numericNodeId(idBaseDataVariableType),
+		dataType:       numericNodeId(idBaseDataType),
+		valueRank:      -1,
+		resource:       name,
+		owner:          name,
+	}
+
+	metadata, _ := space.storage.Metadata(name)
+
+	if _, ok := res.(storage.Reader); ok && metadata.Access.Readable() {
+		variable.accessLevel |= accessLevelRead
+
+		if value, err := space.storage.Read(name); err == nil {
+			variable.dataType = dataTypeOf(value)
+		}
+	}
+
+	if _, ok := res.(storage.Writer); ok && metadata.Access.Writable() {
+		variable.accessLevel |= accessLevelWrite
+	}
+
+	space.add(variable)
+	space.link(folderNodeId(storage.Dir(name)), idOrganizes, variable.id)
+
+	if _, ok := res.(*resource.Action); ok {
+		space.addMethod(name, "trigger", func() StatusCode {
+			return statusOf(space.storage.Write(name, true))
+		})
+	}
+
+	if actionable, ok := res.(storage.Actionable); ok {
+		for _, action := range actionable.Actions() {
+			space.addMethod(name, action, func() StatusCode {
+				space.events.Emit(event.Action(name, action), nil)
+
+				return StatusGood
+			})
+		}
+	}
+}
+
+func (space *addressSpace) addMethod(name string, action string, call func() StatusCode) {
+	method := &node{
+		id:          StringNodeId(1, string(event.Action(name, action))),
+		class:       nodeClassMethod,
+		browseName:  qualifiedName{namespace: 1, name: action},
+		displayName: localizedText{text: action},
+		owner:       name,
+		call:        call,
+	}
+
+	space.add(method)
+	space.link(StringNodeId(1, name), idHasComponent, method.id)
+}
+
+func (space *addressSpace) parentOf(id NodeId) (NodeId, bool) {
+	space.mutex.RLock()
+	defer space.mutex.RUnlock()
+
+	if node, ok := space.nodes[id]; ok {
+		for _, reference := range node.references {
+			if !reference.forward && isSubtype(reference.referenceType, idHierarchicalReferences) {
+				return reference.target, true
+			}
+		}
+	}
+
+	return NodeId{}, false
+}
+
+func (space *addressSpace) readValue(node *node) dataValue {
+	now := time.Now()
+
+	if node.resource == "" {
+		if node.class != nodeClassVariable {
+			return dataValue{status: StatusBadAttributeIdInvalid}
+		}
+
+		return dataValue{value: node.value, hasValue: true, source: now, server: now}
+	}
+
+	sample, err := space.storage.ReadSample(node.resource)
+
+	if err != nil {
+		return dataValue{status: statusOf(err), server: now}
+	}
+
+	return dataValue{
+		value:    sample.Value,
+		hasValue: true,
+		status:   StatusCode(sample.Quality),
+		source:   sample.SourceTimestamp,
+		server:   sample.ServerTimestamp,
+	}
+}
+
+func (space *addressSpace) readAttribute(node *node, attribute uint32) dataValue {
+	value := func(value any) dataValue {
+		return dataValue{value: value, hasValue: true}
+	}
+
+	switch attribute {
+	case attributeNodeId:
+		return value(node.id)
+	case attributeNodeClass:
+		return value(int32(node.class))
+	case attributeBrowseName:
+		return value(node.browseName)
+	case attributeDisplayName:
+		return value(node.displayName)
+	case attributeDescription:
+		return value(localizedText{})
+	case attributeWriteMask, attributeUserWriteMask:
+		return value(uint32(0))
+	}
+
+	switch node.class {
+	case nodeClassObject:
+		if attribute == attributeEventNotifier {
+			return value(byte(0))
+		}
+	case nodeClassVariable:
+		switch attribute {
+		case attributeValue:
+			return space.readValue(node)
+		case attributeDataType:
+			return value(node.dataType)
+		case attributeValueRank:
+			return value(node.valueRank)
+		case attributeArrayDimensions:
+			if node.valueRank == 1 {
+				return dataValue{value: []uint32{0}, hasValue: true}
+			}
+
+			return dataValue{value: nil, hasValue: true}
+		case attributeAccessLevel, attributeUserAccessLevel:
+			return value(node.accessLevel)
+		case attributeMinimumSamplingInterval:
+			return value(float64(0))
+		case attributeHistorizing:
+			return value(false)
+		}
+	case nodeClassMethod:
+		if attribute == attributeExecutable || attribute == attributeUserExecutable {
+			return value(true)
+		}
+	}
+
+	return dataValue{status: StatusBadAttributeIdInvalid}
+}
+
+func (space *addressSpace) write(node *node, value any) StatusCode {
+	if node.resource == "" || node.accessLevel&accessLevelWrite == 0 {
+		return StatusBadNotWritable
+	}
+
+	return statusOf(space.storage.Write(node.resource, value))
+}
+
+func isSubtype(referenceType uint32, parent uint32) bool {
+	for {
+		if referenceType == parent {
+			return true
+		}
+
+		next, ok := referenceSupertypes[referenceType]
+
+		if !ok {
+			return false
+		}
+
+		referenceType = next
+	}
+}
+
+func dataTypeOf(value any) NodeId {
+	switch value.(type) {
+	case bool:
+		return numericNodeId(idBoolean)
+	case int32:
+		return numericNodeId(idInt32)
+	case float32:
+		return numericNodeId(idFloat)
+	}
+
+	return numericNodeId(idBaseDataType)
+}
+
+func statusOf(err error) StatusCode {
+	var status StatusCode
+
+	switch {
+	case err == nil:
+		return StatusGood
+	case errors.As(err, &status):
+		return status
+	case errors.Is(err, storage.ErrResourceNotReadable):
+		return StatusBadNotReadable
+	case errors.Is(err, storage.ErrResourceNotWritable):
+		return StatusBadNotWritable
+	case errors.Is(err, resource.ErrMissmatchedTypes):
+		return StatusBadTypeMismatch
+	case errors.Is(err, storage.ErrOutOfRange):
+		return StatusBadOutOfRange
+	case errors.Is(err, fault.ErrDropout):
+		return StatusCode(quality.BadNotConnected)
+	}
+
+	return StatusBadInternalError
+}
@@ -0,0 +1,264 @@
+package opcua
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	bufferSize         = 65535
+	minimumBufferSize  = 8192
+	maxMessageSize     = 16 * 1024 * 1024
+	messageHeaderSize  = 8
+	symmetricHeader    = 8
+	sequenceHeaderSize = 8
+)
+
+type channel struct {
+	server            *Server
+	connection        net.Conn
+	id                uint32
+	token             uint32
+	endpoint          string
+	receiveBufferSize uint32
+	sendBufferSize    uint32
+	sequence          uint32
+	pending           []byte
+	mutex             sync.Mutex
+}
+
+func newChannel(server *Server, connection net.Conn) *channel {
+	return &channel{
+		server:            server,
+		connection:        connection,
+		id:                0,
+		token:             0,
+		endpoint:          "",
+		receiveBufferSize: bufferSize,
+		sendBufferSize:    bufferSize,
+		sequence:          0,
+		pending:           nil,
+		mutex:             sync.Mutex{},
+	}
+}
+
+func (channel *channel) serve() {
+	header := make([]byte, messageHeaderSize)
+
+	for {
+		if _, err := io.ReadFull(channel.connection, header); err != nil {
+			return
+		}
+
+		size := binary.LittleEndian.Uint32(header[4:8])
+
+		if size < messageHeaderSize || size > channel.receiveBufferSize {
+			channel.fail(StatusBadTcpMessageTooLarge)
+			return
+		}
+
+		body := make([]byte, size-messageHeaderSize)
+
+		if _, err := io.ReadFull(channel.connection, body); err != nil {
+			return
+		}
+
+		var err error
+
+		switch string(header[0:3]) {
+		case "HEL":
+			err = channel.hello(body)
+		case "OPN":
+			err = channel.open(body)
+		case "MSG":
+			err = channel.message(header[3], body)
+		case "CLO":
+			return
+		default:
+			err = StatusBadTcpMessageTypeInvalid
+		}
+
+		if err != nil {
+			channel.fail(statusOf(err))
+			return
+		}
+	}
+}
+
+func (channel *channel) hello(body []byte) error {
+	decoder := newDecoder(body)
+	decoder.readUint32()
+	receiveBufferSize := decoder.readUint32()
+	sendBufferSize := decoder.readUint32()
+	decoder.readUint32()
+	decoder.readUint32()
+	channel.endpoint = decoder.readString()
+
+	if decoder.err != nil {
+		return StatusBadDecodingError
+	}
+
+	channel.sendBufferSize = max(min(receiveBufferSize, bufferSize), minimumBufferSize)
+	channel.receiveBufferSize = max(min(sendBufferSize, bufferSize), minimumBufferSize)
+
+	encoder := newEncoder()
+	encoder.writeUint32(0)
+	encoder.writeUint32(channel.receiveBufferSize)
+	encoder.writeUint32(channel.sendBufferSize)
+	encoder.writeUint32(maxMessageSize)
+	encoder.writeUint32(0)
+
+	return channel.write("ACKF", encoder.bytes())
+}
+
+func (channel *channel) open(body []byte) error {
+	decoder := newDecoder(body)
+	decoder.readUint32()
+	policy := decoder.readString()
+	decoder.readByteString()
+	decoder.readByteString()
+	decoder.readUint32()
+	requestId := decoder.readUint32()
+	encoding := decoder.readNodeId()
+	header := decoder.readRequestHeader()
+	decoder.readUint32()
+	requestType := decoder.readUint32()
+	securityMode := decoder.readUint32()
+	decoder.readByteString()
+	lifetime := decoder.readUint32()
+
+	if decoder.err != nil || encoding != numericNodeId(encodingOpenSecureChannelRequest) {
+		return StatusBadDecodingError
+	}
+
+	if policy != securityPolicyNone || securityMode != 1 {
+		return StatusBadSecurityPolicyRejected
+	}
+
+	if requestType == 0 {
+		channel.id = channel.server.nextChannelId()
+		channel.token = 0
+	}
+
+	channel.token++
+
+	encoder := newEncoder()
+	encoder.writeNodeId(numericNodeId(encodingOpenSecureChannelResponse))
+	encoder.writeResponseHeader(header.handle, StatusGood)
+	encoder.writeUint32(0)
+	encoder.writeUint32(channel.id)
+	encoder.writeUint32(channel.token)
+	encoder.writeDateTime(time.Now())
+	encoder.writeUint32(max(lifetime, 10_000))
+	encoder.writeByteString([]byte{})
+
+	channel.mutex.Lock()
+	defer channel.mutex.Unlock()
+
+	channel.sequence++
+
+	message := newEncoder()
+	message.writeUint32(channel.id)
+	message.writeString(securityPolicyNone)
+	message.writeByteString(nil)
+	message.writeByteString(nil)
+	message.writeUint32(channel.sequence)
+	message.writeUint32(requestId)
+	message.writeRaw(encoder.bytes())
+
+	return channel.write("OPNF", message.bytes())
+}
+
+func (channel *channel) message(chunk byte, body []byte) error {
+	decoder := newDecoder(body)
+	id := decoder.readUint32()
+	decoder.readUint32()
+	decoder.readUint32()
+	requestId := decoder.readUint32()
+
+	if decoder.err != nil {
+		return StatusBadDecodingError
+	}
+
+	if channel.id == 0 || id != channel.id {
+		return StatusBadSecureChannelIdInvalid
+	}
+
+	switch chunk {
+	case 'C':
+		channel.pending = append(channel.pending, body[decoder.offset:]...)
+
+		if len(channel.pending) > maxMessageSize {
+			return StatusBadTcpMessageTooLarge
+		}
+	case 'A':
+		channel.pending = nil
+	case 'F':
+		message := append(channel.pending, body[decoder.offset:]...)
+		channel.pending = nil
+		channel.server.dispatch(channel, requestId, message)
+	default:
+		return StatusBadTcpMessageTypeInvalid
+	}
+
+	return nil
+}
+
+func (channel *channel) send(requestId uint32, body []byte) error {
+	channel.mutex.Lock()
+	defer channel.mutex.Unlock()
+
+	limit := int(channel.sendBufferSize) - messageHeaderSize - symmetricHeader - sequenceHeaderSize
+
+	for {
+		part := body[:min(limit, len(body))]
+		body = body[len(part):]
+		kind := "MSGC"
+
+		if len(body) == 0 {
+			kind = "MSGF"
+		}
+
+		channel.sequence++
+
+		message := newEncoder()
+		message.writeUint32(channel.id)
+		message.writeUint32(channel.token)
+		message.writeUint32(channel.sequence)
+		message.writeUint32(requestId)
+		message.writeRaw(part)
+
+		if err := channel.write(kind, message.bytes()); err != nil {
+			return err
+		}
+
+		if len(body) == 0 {
+			return nil
+		}
+	}
+}
+
+func (channel *channel) write(kind string, body []byte) error {
+	frame := make([]byte, messageHeaderSize, messageHeaderSize+len(body))
+	copy(frame, kind)
+	binary.LittleEndian.PutUint32(frame[4:8], uint32(messageHeaderSize+len(body)))
+	frame = append(frame, body...)
+
+	_, err := channel.connection.Write(frame)
+
+	return err
+}
+
+func (channel *channel) fail(status StatusCode) {
+	encoder := newEncoder()
+	encoder.writeStatusCode(status)
+	encoder.writeNullString()
+
+	channel.mutex.Lock()
+	defer channel.mutex.Unlock()
+
+	channel.write("ERRF", encoder.bytes())
+}
@@ -0,0 +1,677 @@
+package opcua
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	ErrDecoding = errors.New("failed to decode message")
+)
+
+const (
+	ticksPerSecond = 10_000_000
+	epochDelta     = 11644473600
+)
+
+type encoder struct {
+	buffer []byte
+}
+
+func newEncoder() *encoder {
+	return &encoder{
+		buffer: make([]byte, 0, 256),
+	}
+}
+
+func (encoder *encoder) bytes() []byte {
+	return encoder.buffer
+}
+
+func (encoder *encoder) writeBoolean(value bool) {
+	if value {
+		encoder.writeByte(1)
+		return
+	}
+
+	encoder.writeByte(0)
+}
+
+func (encoder *encoder) writeByte(value byte) {
+	encoder.buffer = append(encoder.buffer, value)
+}
+
+func (encoder *encoder) writeUint16(value uint16) {
+	encoder.buffer = binary.LittleEndian.AppendUint16(encoder.buffer, value)
+}
+
+func (encoder *encoder) writeUint32(value uint32) {
+	encoder.buffer = binary.LittleEndian.AppendUint32(encoder.buffer, value)
+}
+
+func (encoder *encoder) writeInt32(value int32) {
+	encoder.writeUint32(uint32(value))
+}
+
+func (encoder *encoder) writeUint64(value uint64) {
+	encoder.buffer = binary.LittleEndian.AppendUint64(encoder.buffer, value)
+}
+
+func (encoder *encoder) writeInt64(value int64) {
+	encoder.writeUint64(uint64(value))
+}
+
+func (encoder *encoder) writeFloat(value float32) {
+	encoder.writeUint32(math.Float32bits(value))
+}
+
+func (encoder *encoder) writeDouble(value float64) {
+	encoder.writeUint64(math.Float64bits(value))
+}
+
+func (encoder *encoder) writeString(value string) {
+	encoder.writeInt32(int32(len(value)))
+	encoder.buffer = append(encoder.buffer, value...)
+}
+
+func (encoder *encoder) writeNullString() {
+	encoder.writeInt32(-1)
+}
+
+func (encoder *encoder) writeByteString(value []byte) {
+	if value == nil {
+		encoder.writeInt32(-1)
+		return
+	}
+
+	encoder.writeInt32(int32(len(value)))
+	encoder.buffer = append(encoder.buffer, value...)
+}
+
+func (encoder *encoder) writeRaw(value []byte) {
+	encoder.buffer = append(encoder.buffer, value...)
+}
+
+func (encoder *encoder) writeDateTime(value time.Time) {
+	if value.IsZero() {
+		encoder.writeInt64(0)
+		return
+	}
+
+	encoder.writeInt64((value.Unix()+epochDelta)*ticksPerSecond + int64(value.Nanosecond()/100))
+}
+
+func (encoder *encoder) writeStatusCode(value StatusCode) {
+	encoder.writeUint32(uint32(value))
+}
+
+func (encoder *encoder) writeStrings(values []string) {
+	encoder.writeInt32(int32(len(values)))
+
+	for _, value := range values {
+		encoder.writeString(value)
+	}
+}
+
+func (encoder *encoder) writeStatusCodes(values []StatusCode) {
+	encoder.writeInt32(int32(len(values)))
+
+	for _, value := range values {
+		encoder.writeStatusCode(value)
+	}
+}
+
+func (encoder *encoder) writeEmptyArray() {
+	encoder.writeInt32(0)
+}
+
+func (encoder *encoder) writeNodeId(id NodeId) {
+	switch id.kind {
+	case identifierNumeric:
+		switch {
+		case id.Namespace == 0 && id.numeric <= math.MaxUint8:
+			encoder.writeByte(0x00)
+			encoder.writeByte(byte(id.numeric))
+		case id.Namespace <= math.MaxUint8 && id.numeric <= math.MaxUint16:
+			encoder.writeByte(0x01)
+			encoder.writeByte(byte(id.Namespace))
+			encoder.writeUint16(uint16(id.numeric))
+		default:
+			encoder.writeByte(0x02)
+			encoder.writeUint16(id.Namespace)
+			encoder.writeUint32(id.numeric)
+		}
+	case identifierString:
+		encoder.writeByte(0x03)
+		encoder.writeUint16(id.Namespace)
+		encoder.writeString(id.text)
+	case identifierGuid:
+		encoder.writeByte(0x04)
+		encoder.writeUint16(id.Namespace)
+		encoder.writeRaw([]byte(id.text))
+	case identifierOpaque:
+		encoder.writeByte(0x05)
+		encoder.writeUint16(id.Namespace)
+		encoder.writeByteString([]byte(id.text))
+	}
+}
+
+func (encoder *encoder) writeExpandedNodeId(id NodeId) {
+	encoder.writeNodeId(id)
+}
+
+func (encoder *encoder) writeQualifiedName(name qualifiedName) {
+	encoder.writeUint16(name.namespace)
+	encoder.writeString(name.name)
+}
+
+func (encoder *encoder) writeLocalizedText(text localizedText) {
+	var mask byte
+
+	if text.locale != "" {
+		mask |= 0x01
+	}
+
+	if text.text != "" {
+		mask |= 0x02
+	}
+
+	encoder.writeByte(mask)
+
+	if text.locale != "" {
+		encoder.writeString(text.locale)
+	}
+
+	if text.text != "" {
+		encoder.writeString(text.text)
+	}
+}
+
+func (encoder *encoder) writeExtensionObject(encoding uint32, body []byte) {
+	encoder.writeNodeId(numericNodeId(encoding))
+	encoder.writeByte(0x01)
+	encoder.writeByteString(body)
+}
+
+func (encoder *encoder) writeNullExtensionObject() {
+	encoder.writeNodeId(numericNodeId(0))
+	encoder.writeByte(0x00)
+}
+
+func (encoder *encoder) writeVariant(value any) {
+	switch v := value.(type) {
+	case nil:
+		encoder.writeByte(variantNull)
+	case bool:
+		encoder.writeByte(variantBoolean)
+		encoder.writeBoolean(v)
+	case byte:
+		encoder.writeByte(variantByte)
+		encoder.writeByte(v)
+	case uint16:
+		encoder.writeByte(variantUint16)
+		encoder.writeUint16(v)
+	case int32:
+		encoder.writeByte(variantInt32)
+		encoder.writeInt32(v)
+	case uint32:
+		encoder.writeByte(variantUint32)
+		encoder.writeUint32(v)
+	case int64:
+		encoder.writeByte(variantInt64)
+		encoder.writeInt64(v)
+	case float32:
+		encoder.writeByte(variantFloat)
+		encoder.writeFloat(v)
+	case float64:
+		encoder.writeByte(variantDouble)
+		encoder.writeDouble(v)
+	case string:
+		encoder.writeByte(variantString)
+		encoder.writeString(v)
+	case time.Time:
+		encoder.writeByte(variantDateTime)
+		encoder.writeDateTime(v)
+	case []byte:
+		encoder.writeByte(variantByteString)
+		encoder.writeByteString(v)
+	case NodeId:
+		encoder.writeByte(variantNodeId)
+		encoder.writeNodeId(v)
+	case StatusCode:
+		encoder.writeByte(variantStatusCode)
+		encoder.writeStatusCode(v)
+	case qualifiedName:
+		encoder.writeByte(variantQualifiedName)
+		encoder.writeQualifiedName(v)
+	case localizedText:
+		encoder.writeByte(variantLocalizedText)
+		encoder.writeLocalizedText(v)
+	case []string:
+		encoder.writeByte(variantString | variantArray)
+		encoder.writeStrings(v)
+	case []uint32:
+		encoder.writeByte(variantUint32 | variantArray)
+		encoder.writeInt32(int32(len(v)))
+
+		for _, value := range v {
+			encoder.writeUint32(value)
+		}
+	default:
+		encoder.writeByte(variantNull)
+	}
+}
+
+func (encoder *encoder) writeDataValue(value dataValue) {
+	var mask byte
+
+	if value.hasValue {
+		mask |= 0x01
+	}
+
+	if value.status != StatusGood {
+		mask |= 0x02
+	}
+
+	if !value.source.IsZero() {
+		mask |= 0x04
+	}
+
+	if !value.server.IsZero() {
+		mask |= 0x08
+	}
+
+	encoder.writeByte(mask)
+
+	if value.hasValue {
+		encoder.writeVariant(value.value)
+	}
+
+	if value.status != StatusGood {
+		encoder.writeStatusCode(value.status)
+	}
+
+	if !value.source.IsZero() {
+		encoder.writeDateTime(value.source)
+	}
+
+	if !value.server.IsZero() {
+		encoder.writeDateTime(value.server)
+	}
+}
+
+type decoder struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func newDecoder(data []byte) *decoder {
+	return &decoder{
+		data:   data,
+		offset: 0,
+		err:    nil,
+	}
+}
+
+func (decoder *decoder) remaining() int {
+	return len(decoder.data) - decoder.offset
+}
+
+func (decoder *decoder) take(length int) []byte {
+	if decoder.err != nil {
+		return nil
+	}
+
+	if length < 0 || length > decoder.remaining() {
+		decoder.err = ErrDecoding
+		return nil
+	}
+
+	value := decoder.data[decoder.offset : decoder.offset+length]
+	decoder.offset += length
+
+	return value
+}
+
+func (decoder *decoder) readBoolean() bool {
+	return decoder.readByte() != 0
+}
+
+func (decoder *decoder) readByte() byte {
+	if value := decoder.take(1); value != nil {
+		return value[0]
+	}
+
+	return 0
+}
+
+func (decoder *decoder) readUint16() uint16 {
+	if value := decoder.take(2); value != nil {
+		return binary.LittleEndian.Uint16(value)
+	}
+
+	return 0
+}
+
+func (decoder *decoder) readUint32() uint32 {
+	if value := decoder.take(4); value != nil {
+		return binary.LittleEndian.Uint32(value)
+	}
+
+	return 0
+}
+
+func (decoder *decoder) readInt32() int32 {
+	return int32(decoder.readUint32())
+}
+
+func (decoder *decoder) readUint64() uint64 {
+	if value := decoder.take(8); value != nil {
+		return binary.LittleEndian.Uint64(value)
+	}
+
+	return 0
+}
+
+func (decoder *decoder) readInt64() int64 {
+	return int64(decoder.readUint64())
+}
+
+func (decoder *decoder) readFloat() float32 {
+	return math.Float32frombits(decoder.readUint32())
+}
+
+func (decoder *decoder) readDouble() float64 {
+	return math.Float64frombits(decoder.readUint64())
+}
+
+func (decoder *decoder) readByteString() []byte {
+	length := decoder.readInt32()
+
+	if length < 0 {
+		return nil
+	}
+
+	value := decoder.take(int(length))
+
+	if value == nil {
+		return nil
+	}
+
+	return append([]byte{}, value...)
+}
+
+func (decoder *decoder) readString() string {
+	return string(decoder.readByteString())
+}
+
+func (decoder *decoder) readDateTime() time.Time {
+	ticks := decoder.readInt64()
+
+	if ticks <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(ticks/ticksPerSecond-epochDelta, (ticks%ticksPerSecond)*100).UTC()
+}
+
+func (decoder *decoder) readStatusCode() StatusCode {
+	return StatusCode(decoder.readUint32())
+}
+
+func (decoder *decoder) readArrayLength() int {
+	length := decoder.readInt32()
+
+	if length < 0 {
+		return 0
+	}
+
+	if int(length) > decoder.remaining() {
+		decoder.err = ErrDecoding
+		return 0
+	}
+
+	return int(length)
+}
+
+func (decoder *decoder) readStrings() []string {
+	values := make([]string, decoder.readArrayLength())
+
+	for i := range values {
+		values[i] = decoder.readString()
+	}
+
+	return values
+}
+
+func (decoder *decoder) readUint32s() []uint32 {
+	values := make([]uint32, decoder.readArrayLength())
+
+	for i := range values {
+		values[i] = decoder.readUint32()
+	}
+
+	return values
+}
+
+func (decoder *decoder) readNodeId() NodeId {
+	encoding := decoder.readByte()
+
+	return decoder.readNodeIdBody(encoding & 0x3F)
+}
+
+func (decoder *decoder) readNodeIdBody(encoding byte) NodeId {
+	switch encoding {
+	case 0x00:
+		return numericNodeId(uint32(decoder.readByte()))
+	case 0x01:
+		namespace := uint16(decoder.readByte())
+		return NodeId{Namespace: namespace, kind: identifierNumeric, numeric: uint32(decoder.readUint16())}
+	case 0x02:
+		namespace := decoder.readUint16()
+		return NodeId{Namespace: namespace, kind: identifierNumeric, numeric: decoder.readUint32()}
+	case 0x03:
+		namespace := decoder.readUint16()
+		return NodeId{Namespace: namespace, kind: identifierString, text: decoder.readString()}
+	case 0x04:
+		namespace := decoder.readUint16()
+		return NodeId{Namespace: namespace, kind: identifierGuid, text: string(decoder.take(16))}
+	case 0x05:
+		namespace := decoder.readUint16()
+		return NodeId{Namespace: namespace, kind: identifierOpaque, text: string(decoder.readByteString())}
+	}
+
+	decoder.err = ErrDecoding
+
+	return NodeId{}
+}
+
+func (decoder *decoder) readExpandedNodeId() NodeId {
+	encoding := decoder.readByte()
+	id := decoder.readNodeIdBody(encoding & 0x3F)
+
+	if encoding&0x80 != 0 {
+		decoder.readString()
+	}
+
+	if encoding&0x40 != 0 {
+		decoder.readUint32()
+	}
+
+	return id
+}
+
+func (decoder *decoder) readQualifiedName() qualifiedName {
+	namespace := decoder.readUint16()
+
+	return qualifiedName{namespace: namespace, name: decoder.readString()}
+}
+
+func (decoder *decoder) readLocalizedText() localizedText {
+	mask := decoder.readByte()
+	text := localizedText{}
+
+	if mask&0x01 != 0 {
+		text.locale = decoder.readString()
+	}
+
+	if mask&0x02 != 0 {
+		text.text = decoder.readString()
+	}
+
+	return text
+}
+
+func (decoder *decoder) readExtensionObject() (NodeId, []byte) {
+	encoding := decoder.readNodeId()
+	mask := decoder.readByte()
+
+	if mask == 0x00 {
+		return encoding, nil
+	}
+
+	return encoding, decoder.readByteString()
+}
+
+func (decoder *decoder) readDiagnosticInfo() {
+	mask := decoder.readByte()
+
+	for _, field := range []byte{0x01, 0x02, 0x04, 0x08} {
+		if mask&field != 0 {
+			decoder.readInt32()
+		}
+	}
+
+	if mask&0x10 != 0 {
+		decoder.readString()
+	}
+
+	if mask&0x20 != 0 {
+		decoder.readStatusCode()
+	}
+
+	if mask&0x40 != 0 && decoder.err == nil {
+		decoder.readDiagnosticInfo()
+	}
+}
+
+func (decoder *decoder) readVariant() any {
+	mask := decoder.readByte()
+	kind := mask & 0x3F
+
+	if mask&variantArray == 0 {
+		return decoder.readScalar(kind)
+	}
+
+	values := make([]any, decoder.readArrayLength())
+
+	for i := range values {
+		values[i] = decoder.readScalar(kind)
+	}
+
+	if mask&variantDimensions != 0 {
+		dimensions := decoder.readArrayLength()
+
+		for i := 0; i < dimensions; i++ {
+			decoder.readInt32()
+		}
+	}
+
+	return values
+}
+
+func (decoder *decoder) readScalar(kind byte) any {
+	switch kind {
+	case variantNull:
+		return nil
+	case variantBoolean:
+		return decoder.readBoolean()
+	case variantSByte:
+		return int8(decoder.readByte())
+	case variantByte:
+		return decoder.readByte()
+	case variantInt16:
+		return int16(decoder.readUint16())
+	case variantUint16:
+		return decoder.readUint16()
+	case variantInt32:
+		return decoder.readInt32()
+	case variantUint32:
+		return decoder.readUint32()
+	case variantInt64:
+		return decoder.readInt64()
+	case variantUint64:
+		return decoder.readUint64()
+	case variantFloat:
+		return decoder.readFloat()
+	case variantDouble:
+		return decoder.readDouble()
+	case variantString:
+		return decoder.readString()
+	case variantDateTime:
+		return decoder.readDateTime()
+	case variantGuid:
+		return decoder.take(16)
+	case variantByteString, variantXmlElement:
+		return decoder.readByteString()
+	case variantNodeId:
+		return decoder.readNodeId()
+	case variantExpandedNodeId:
+		return decoder.readExpandedNodeId()
+	case variantStatusCode:
+		return decoder.readStatusCode()
+	case variantQualifiedName:
+		return decoder.readQualifiedName()
+	case variantLocalizedText:
+		return decoder.readLocalizedText()
+	case variantExtensionObject:
+		_, body := decoder.readExtensionObject()
+		return body
+	case variantDataValue:
+		return decoder.readDataValue()
+	case variantVariant:
+		return decoder.readVariant()
+	case variantDiagnosticInfo:
+		decoder.readDiagnosticInfo()
+		return nil
+	}
+
+	decoder.err = ErrDecoding
+
+	return nil
+}
+
+func (decoder *decoder) readDataValue() dataValue {
+	mask := decoder.readByte()
+	value := dataValue{}
+
+	if mask&0x01 != 0 {
+		value.hasValue = true
+		value.value = decoder.readVariant()
+	}
+
+	if mask&0x02 != 0 {
+		value.status = decoder.readStatusCode()
+	}
+
+	if mask&0x04 != 0 {
+		value.source = decoder.readDateTime()
+	}
+
+	if mask&0x10 != 0 {
+		decoder.readUint16()
+	}
+
+	if mask&0x08 != 0 {
+		value.server = decoder.readDateTime()
+	}
+
+	if mask&0x20 != 0 {
+		decoder.readUint16()
+	}
+
+	return value
+}
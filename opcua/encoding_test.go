@@ -0,0 +1,101 @@
+package opcua
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVariantRoundTrip(t *testing.T) {
+	timestamp := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+
+	for _, value := range []any{
+		nil,
+		true,
+		byte(7),
+		uint16(513),
+		int32(-42),
+		uint32(42),
+		int64(-1) << 40,
+		float32(2.5),
+		float64(-0.125),
+		"tank/level",
+		timestamp.Truncate(100 * time.Nanosecond),
+		StatusBadInternalError,
+		StringNodeId(1, "tank/level"),
+	} {
+		encoder := newEncoder()
+		encoder.writeVariant(value)
+
+		decoder := newDecoder(encoder.bytes())
+		decoded := decoder.readVariant()
+
+		if decoder.err != nil || decoder.remaining() != 0 {
+			t.Fatalf("%T: err %v with %d bytes left", value, decoder.err, decoder.remaining())
+		}
+
+		if decoded != value {
+			t.Fatalf("expected %v (%T), got %v (%T)", value, value, decoded, decoded)
+		}
+	}
+}
+
+func TestNodeIdEncoding(t *testing.T) {
+	tests := []struct {
+		id      NodeId
+		encoded []byte
+	}{
+		{id: numericNodeId(85), encoded: []byte{0x00, 0x55}},
+		{id: NodeId{Namespace: 1, kind: identifierNumeric, numeric: 1000}, encoded: []byte{0x01, 0x01, 0xE8, 0x03}},
+		{id: NodeId{Namespace: 300, kind: identifierNumeric, numeric: 70000}, encoded: []byte{0x02, 0x2C, 0x01, 0x70, 0x11, 0x01, 0x00}},
+		{id: StringNodeId(1, "ab"), encoded: []byte{0x03, 0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 'a', 'b'}},
+	}
+
+	for _, test := range tests {
+		encoder := newEncoder()
+		encoder.writeNodeId(test.id)
+
+		if !bytes.Equal(encoder.bytes(), test.encoded) {
+			t.Fatalf("%s: expected %x, got %x", test.id, test.encoded, encoder.bytes())
+		}
+
+		decoder := newDecoder(encoder.bytes())
+
+		if decoded := decoder.readNodeId(); decoded != test.id || decoder.err != nil {
+			t.Fatalf("%s: decoded %s, %v", test.id, decoded, decoder.err)
+		}
+	}
+}
+
+func TestDataValueRoundTrip(t *testing.T) {
+	source := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	value := dataValue{
+		value:    float32(1.5),
+		hasValue: true,
+		status:   StatusBadUnexpectedError,
+		source:   source,
+		server:   source.Add(time.Second),
+	}
+
+	encoder := newEncoder()
+	encoder.writeDataValue(value)
+
+	decoder := newDecoder(encoder.bytes())
+
+	if decoded := decoder.readDataValue(); decoded != value || decoder.err != nil {
+		t.Fatalf("expected %+v, got %+v, %v", value, decoded, decoder.err)
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	encoder := newEncoder()
+	encoder.writeVariant("tank/level")
+
+	decoder := newDecoder(encoder.bytes()[:len(encoder.bytes())-1])
+	decoder.readVariant()
+
+	if !errors.Is(decoder.err, ErrDecoding) {
+		t.Fatalf("expected %v, got %v", ErrDecoding, decoder.err)
+	}
+}
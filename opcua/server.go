@@ -0,0 +1,181 @@
+package opcua
+
+import (
+	"net"
+	"sync"
+
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type Server struct {
+	storage     *storage.Storage
+	events      *event.Events
+	space       *addressSpace
+	listener    net.Listener
+	lifecycle   chan any
+	channels    map[*channel]struct{}
+	sessions    map[NodeId]*session
+	lastChannel uint32
+	lastSession uint32
+	lastHandle  uint32
+	mutex       sync.Mutex
+	wg          sync.WaitGroup
+}
+
+func NewServer(storage *storage.Storage, events *event.Events) *Server {
+	return &Server{
+		storage:     storage,
+		events:      events,
+		space:       nil,
+		listener:    nil,
+		lifecycle:   nil,
+		channels:    make(map[*channel]struct{}),
+		sessions:    make(map[NodeId]*session),
+		lastChannel: 0,
+		lastSession: 0,
+		lastHandle:  0,
+		mutex:       sync.Mutex{},
+		wg:          sync.WaitGroup{},
+	}
+}
+
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+
+	if err != nil {
+		return err
+	}
+
+	server.Serve(listener)
+
+	return nil
+}
+
+func (server *Server) Serve(listener net.Listener) {
+	lifecycle := make(chan any, event.DefaultCapacity)
+
+	for _, name := range []event.Event{event.Added, event.Removed, event.Replaced} {
+		server.events.Subscribe(name, lifecycle)
+	}
+
+	server.mutex.Lock()
+	server.space = newAddressSpace(server.storage, server.events)
+	server.listener = listener
+	server.lifecycle = lifecycle
+	server.mutex.Unlock()
+
+	server.wg.Add(2)
+	go server.accept(listener)
+	go server.watch(server.space, lifecycle)
+}
+
+func (server *Server) watch(space *addressSpace, lifecycle chan any) {
+	defer server.wg.Done()
+
+	for payload := range lifecycle {
+		if changed, ok := payload.(event.LifecyclePayload); ok {
+			space.sync(changed.Resource)
+		}
+	}
+}
+
+func (server *Server) Address() net.Addr {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.listener == nil {
+		return nil
+	}
+
+	return server.listener.Addr()
+}
+
+func (server *Server) Stop() {
+	server.mutex.Lock()
+
+	if server.listener != nil {
+		server.listener.Close()
+		server.listener = nil
+	}
+
+	if server.lifecycle != nil {
+		for _, name := range []event.Event{event.Added, event.Removed, event.Replaced} {
+			server.events.Unsubscribe(name, server.lifecycle)
+		}
+
+		close(server.lifecycle)
+		server.lifecycle = nil
+	}
+
+	for channel := range server.channels {
+		channel.connection.Close()
+	}
+
+	server.mutex.Unlock()
+	server.wg.Wait()
+}
+
+func (server *Server) accept(listener net.Listener) {
+	defer server.wg.Done()
+
+	for {
+		connection, err := listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		channel := newChannel(server, connection)
+
+		server.mutex.Lock()
+		server.channels[channel] = struct{}{}
+		server.mutex.Unlock()
+
+		server.wg.Add(1)
+		go server.serve(channel)
+	}
+}
+
+func (server *Server) serve(channel *channel) {
+	defer server.wg.Done()
+
+	channel.serve()
+	channel.connection.Close()
+
+	server.mutex.Lock()
+	delete(server.channels, channel)
+
+	var closed []*session
+
+	for token, session := range server.sessions {
+		if session.channel == channel {
+			delete(server.sessions, token)
+			closed = append(closed, session)
+		}
+	}
+
+	server.mutex.Unlock()
+
+	for _, session := range closed {
+		session.close()
+	}
+}
+
+func (server *Server) nextChannelId() uint32 {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.lastChannel++
+
+	return server.lastChannel
+}
+
+func (server *Server) nextHandle() uint32 {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.lastHandle++
+
+	return server.lastHandle
+}
@@ -0,0 +1,933 @@
+package opcua
+
+import (
+	"crypto/rand"
+	"slices"
+	"time"
+)
+
+type requestHeader struct {
+	token  NodeId
+	handle uint32
+}
+
+type monitoredItemNotification struct {
+	handle uint32
+	value  dataValue
+}
+
+type service func(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode
+
+type serviceEntry struct {
+	response uint32
+	handler  service
+	session  bool
+}
+
+func (server *Server) services() map[uint32]serviceEntry {
+	return map[uint32]serviceEntry{
+		encodingFindServersRequest:          {encodingFindServersResponse, server.findServers, false},
+		encodingGetEndpointsRequest:         {encodingGetEndpointsResponse, server.getEndpoints, false},
+		encodingCreateSessionRequest:        {encodingCreateSessionResponse, server.createSession, false},
+		encodingActivateSessionRequest:      {encodingActivateSessionResponse, server.activateSession, false},
+		encodingCloseSessionRequest:         {encodingCloseSessionResponse, server.closeSession, true},
+		encodingBrowseRequest:               {encodingBrowseResponse, server.browse, true},
+		encodingBrowseNextRequest:           {encodingBrowseNextResponse, server.browseNext, true},
+		encodingReadRequest:                 {encodingReadResponse, server.read, true},
+		encodingWriteRequest:                {encodingWriteResponse, server.write, true},
+		encodingCallRequest:                 {encodingCallResponse, server.call, true},
+		encodingCreateSubscriptionRequest:   {encodingCreateSubscriptionResponse, server.createSubscription, true},
+		encodingModifySubscriptionRequest:   {encodingModifySubscriptionResponse, server.modifySubscription, true},
+		encodingSetPublishingModeRequest:    {encodingSetPublishingModeResponse, server.setPublishingMode, true},
+		encodingDeleteSubscriptionsRequest:  {encodingDeleteSubscriptionsResponse, server.deleteSubscriptions, true},
+		encodingCreateMonitoredItemsRequest: {encodingCreateMonitoredItemsResponse, server.createMonitoredItems, true},
+		encodingDeleteMonitoredItemsRequest: {encodingDeleteMonitoredItemsResponse, server.deleteMonitoredItems, true},
+		encodingRepublishRequest:            {encodingRepublishResponse, server.republish, true},
+	}
+}
+
+func (server *Server) dispatch(channel *channel, requestId uint32, message []byte) {
+	decoder := newDecoder(message)
+	encoding := decoder.readNodeId()
+	header := decoder.readRequestHeader()
+
+	if decoder.err != nil {
+		channel.send(requestId, serviceFault(header.handle, StatusBadDecodingError))
+		return
+	}
+
+	if encoding == numericNodeId(encodingCloseSecureChannelRequest) {
+		channel.connection.Close()
+		return
+	}
+
+	server.mutex.Lock()
+	session := server.sessions[header.token]
+	server.mutex.Unlock()
+
+	if encoding == numericNodeId(encodingPublishRequest) {
+		if status := server.publish(channel, session, requestId, header, decoder); status != StatusGood {
+			channel.send(requestId, serviceFault(header.handle, status))
+		}
+
+		return
+	}
+
+	entry, ok := server.services()[encoding.numeric]
+
+	if !ok || encoding.Namespace != 0 {
+		channel.send(requestId, serviceFault(header.handle, StatusBadServiceUnsupported))
+		return
+	}
+
+	if entry.session {
+		if status := validateSession(channel, session); status != StatusGood {
+			channel.send(requestId, serviceFault(header.handle, status))
+			return
+		}
+	}
+
+	body := newEncoder()
+
+	if status := entry.handler(channel, session, decoder, body); status != StatusGood {
+		channel.send(requestId, serviceFault(header.handle, status))
+		return
+	}
+
+	if decoder.err != nil {
+		channel.send(requestId, serviceFault(header.handle, StatusBadDecodingError))
+		return
+	}
+
+	response := newEncoder()
+	response.writeNodeId(numericNodeId(entry.response))
+	response.writeResponseHeader(header.handle, StatusGood)
+	response.writeRaw(body.bytes())
+
+	channel.send(requestId, response.bytes())
+}
+
+func validateSession(channel *channel, session *session) StatusCode {
+	if session == nil {
+		return StatusBadSessionIdInvalid
+	}
+
+	if !session.activated || session.channel != channel {
+		return StatusBadSessionNotActivated
+	}
+
+	return StatusGood
+}
+
+func serviceFault(handle uint32, status StatusCode) []byte {
+	encoder := newEncoder()
+	encoder.writeNodeId(numericNodeId(encodingServiceFault))
+	encoder.writeResponseHeader(handle, status)
+
+	return encoder.bytes()
+}
+
+func (decoder *decoder) readRequestHeader() requestHeader {
+	header := requestHeader{}
+	header.token = decoder.readNodeId()
+	decoder.readDateTime()
+	header.handle = decoder.readUint32()
+	decoder.readUint32()
+	decoder.readString()
+	decoder.readUint32()
+	decoder.readExtensionObject()
+
+	return header
+}
+
+func (decoder *decoder) readApplicationDescription() {
+	decoder.readString()
+	decoder.readString()
+	decoder.readLocalizedText()
+	decoder.readUint32()
+	decoder.readString()
+	decoder.readString()
+	decoder.readStrings()
+}
+
+func (decoder *decoder) readSignatureData() {
+	decoder.readString()
+	decoder.readByteString()
+}
+
+func (decoder *decoder) readReadValueId() (NodeId, uint32) {
+	id := decoder.readNodeId()
+	attribute := decoder.readUint32()
+	decoder.readString()
+	decoder.readQualifiedName()
+
+	return id, attribute
+}
+
+func (encoder *encoder) writeResponseHeader(handle uint32, status StatusCode) {
+	encoder.writeDateTime(time.Now())
+	encoder.writeUint32(handle)
+	encoder.writeStatusCode(status)
+	encoder.writeByte(0)
+	encoder.writeEmptyArray()
+	encoder.writeNullExtensionObject()
+}
+
+func (encoder *encoder) writeApplicationDescription(url string) {
+	encoder.writeString(applicationURI)
+	encoder.writeString(productURI)
+	encoder.writeLocalizedText(localizedText{text: "Immersim"})
+	encoder.writeUint32(0)
+	encoder.writeNullString()
+	encoder.writeNullString()
+	encoder.writeStrings([]string{url})
+}
+
+func (encoder *encoder) writeEndpointDescription(url string) {
+	encoder.writeString(url)
+	encoder.writeApplicationDescription(url)
+	encoder.writeByteString(nil)
+	encoder.writeUint32(1)
+	encoder.writeString(securityPolicyNone)
+	encoder.writeInt32(1)
+	encoder.writeString(anonymousPolicyId)
+	encoder.writeUint32(0)
+	encoder.writeNullString()
+	encoder.writeNullString()
+	encoder.writeNullString()
+	encoder.writeString(transportProfileTCP)
+	encoder.writeByte(0)
+}
+
+func (encoder *encoder) writeReferenceDescription(space *addressSpace, reference reference) {
+	target, _ := space.lookup(reference.target)
+
+	encoder.writeNodeId(numericNodeId(reference.referenceType))
+	encoder.writeBoolean(reference.forward)
+	encoder.writeExpandedNodeId(reference.target)
+
+	if target == nil {
+		encoder.writeQualifiedName(qualifiedName{})
+		encoder.writeLocalizedText(localizedText{})
+		encoder.writeUint32(0)
+		encoder.writeExpandedNodeId(NodeId{})
+
+		return
+	}
+
+	encoder.writeQualifiedName(target.browseName)
+	encoder.writeLocalizedText(target.displayName)
+	encoder.writeUint32(target.class)
+	encoder.writeExpandedNodeId(target.typeDefinition)
+}
+
+func (encoder *encoder) writeNotificationMessage(sequence uint32, notifications []monitoredItemNotification) {
+	encoder.writeUint32(sequence)
+	encoder.writeDateTime(time.Now())
+
+	if len(notifications) == 0 {
+		encoder.writeEmptyArray()
+		return
+	}
+
+	body := newEncoder()
+	body.writeInt32(int32(len(notifications)))
+
+	for _, notification := range notifications {
+		body.writeUint32(notification.handle)
+		body.writeDataValue(notification.value)
+	}
+
+	body.writeEmptyArray()
+
+	encoder.writeInt32(1)
+	encoder.writeExtensionObject(encodingDataChangeNotification, body.bytes())
+}
+
+func endpointOf(channel *channel, url string) string {
+	if url == "" {
+		return channel.endpoint
+	}
+
+	return url
+}
+
+func nonce() []byte {
+	value := make([]byte, 32)
+	rand.Read(value)
+
+	return value
+}
+
+func (server *Server) findServers(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	url := decoder.readString()
+	decoder.readStrings()
+	decoder.readStrings()
+
+	encoder.writeInt32(1)
+	encoder.writeApplicationDescription(endpointOf(channel, url))
+
+	return StatusGood
+}
+
+func (server *Server) getEndpoints(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	url := decoder.readString()
+	decoder.readStrings()
+	decoder.readStrings()
+
+	encoder.writeInt32(1)
+	encoder.writeEndpointDescription(endpointOf(channel, url))
+
+	return StatusGood
+}
+
+func (server *Server) createSession(channel *channel, _ *session, decoder *decoder, encoder *encoder) StatusCode {
+	decoder.readApplicationDescription()
+	decoder.readString()
+	url := decoder.readString()
+	decoder.readString()
+	decoder.readByteString()
+	decoder.readByteString()
+	timeout := decoder.readDouble()
+	decoder.readUint32()
+
+	if decoder.err != nil {
+		return StatusBadDecodingError
+	}
+
+	server.mutex.Lock()
+	server.lastSession++
+	session := newSession(server.lastSession, channel)
+	server.sessions[session.token] = session
+	server.mutex.Unlock()
+
+	encoder.writeNodeId(session.id)
+	encoder.writeNodeId(session.token)
+	encoder.writeDouble(min(max(timeout, 10_000), 3_600_000))
+	encoder.writeByteString(nonce())
+	encoder.writeByteString(nil)
+	encoder.writeInt32(1)
+	encoder.writeEndpointDescription(endpointOf(channel, url))
+	encoder.writeEmptyArray()
+	encoder.writeNullString()
+	encoder.writeByteString(nil)
+	encoder.writeUint32(maxMessageSize)
+
+	return StatusGood
+}
+
+func (server *Server) activateSession(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	decoder.readSignatureData()
+
+	for i := decoder.readArrayLength(); i > 0; i-- {
+		decoder.readByteString()
+		decoder.readByteString()
+	}
+
+	decoder.readStrings()
+	identity, _ := decoder.readExtensionObject()
+	decoder.readSignatureData()
+
+	if decoder.err != nil {
+		return StatusBadDecodingError
+	}
+
+	if session == nil {
+		return StatusBadSessionIdInvalid
+	}
+
+	if !identity.isNull() && identity != numericNodeId(encodingAnonymousIdentityToken) {
+		return StatusBadIdentityTokenInvalid
+	}
+
+	session.mutex.Lock()
+	session.activated = true
+	session.channel = channel
+	session.mutex.Unlock()
+
+	encoder.writeByteString(nonce())
+	encoder.writeEmptyArray()
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) closeSession(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	decoder.readBoolean()
+
+	server.mutex.Lock()
+	delete(server.sessions, session.token)
+	server.mutex.Unlock()
+
+	session.close()
+
+	return StatusGood
+}
+
+func (server *Server) browse(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	decoder.readNodeId()
+	decoder.readDateTime()
+	decoder.readUint32()
+	limit := decoder.readUint32()
+	count := decoder.readArrayLength()
+
+	if count == 0 {
+		return StatusBadNothingToDo
+	}
+
+	encoder.writeInt32(int32(count))
+
+	for i := 0; i < count; i++ {
+		id := decoder.readNodeId()
+		direction := decoder.readUint32()
+		referenceType := decoder.readNodeId()
+		subtypes := decoder.readBoolean()
+		classes := decoder.readUint32()
+		decoder.readUint32()
+
+		candidates, ok := server.space.references(id)
+
+		if !ok {
+			encoder.writeStatusCode(StatusBadNodeIdUnknown)
+			encoder.writeByteString(nil)
+			encoder.writeEmptyArray()
+			continue
+		}
+
+		var references []reference
+
+		for _, reference := range candidates {
+			if direction == browseForward && !reference.forward || direction == browseInverse && reference.forward {
+				continue
+			}
+
+			if !referenceType.isNull() {
+				if referenceType.Namespace != 0 || !(reference.referenceType == referenceType.numeric || subtypes && isSubtype(reference.referenceType, referenceType.numeric)) {
+					continue
+				}
+			}
+
+			if target, ok := server.space.lookup(reference.target); classes != 0 && (!ok || target.class&classes == 0) {
+				continue
+			}
+
+			references = append(references, reference)
+		}
+
+		server.writeBrowseResult(session, references, limit, encoder)
+	}
+
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) browseNext(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	release := decoder.readBoolean()
+	count := decoder.readArrayLength()
+
+	if count == 0 {
+		return StatusBadNothingToDo
+	}
+
+	encoder.writeInt32(int32(count))
+
+	for i := 0; i < count; i++ {
+		point := string(decoder.readByteString())
+
+		session.mutex.Lock()
+		references, ok := session.continuations[point]
+		delete(session.continuations, point)
+		session.mutex.Unlock()
+
+		if !ok {
+			encoder.writeStatusCode(StatusBadContinuationPointInvalid)
+			encoder.writeByteString(nil)
+			encoder.writeEmptyArray()
+			continue
+		}
+
+		if release {
+			encoder.writeStatusCode(StatusGood)
+			encoder.writeByteString(nil)
+			encoder.writeEmptyArray()
+			continue
+		}
+
+		server.writeBrowseResult(session, references, uint32(len(references)), encoder)
+	}
+
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) writeBrowseResult(session *session, references []reference, limit uint32, encoder *encoder) {
+	var point []byte
+
+	if limit > 0 && uint32(len(references)) > limit {
+		point = nonce()[:8]
+
+		session.mutex.Lock()
+		session.continuations[string(point)] = references[limit:]
+		session.mutex.Unlock()
+
+		references = references[:limit]
+	}
+
+	encoder.writeStatusCode(StatusGood)
+	encoder.writeByteString(point)
+	encoder.writeInt32(int32(len(references)))
+
+	for _, reference := range references {
+		encoder.writeReferenceDescription(server.space, reference)
+	}
+}
+
+func (server *Server) read(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	decoder.readDouble()
+	timestamps := decoder.readUint32()
+	count := decoder.readArrayLength()
+
+	if count == 0 {
+		return StatusBadNothingToDo
+	}
+
+	encoder.writeInt32(int32(count))
+
+	for i := 0; i < count; i++ {
+		id, attribute := decoder.readReadValueId()
+		node, ok := server.space.lookup(id)
+
+		if !ok {
+			encoder.writeDataValue(dataValue{status: StatusBadNodeIdUnknown})
+			continue
+		}
+
+		value := server.space.readAttribute(node, attribute)
+
+		if timestamps == 1 || timestamps == 3 {
+			value.source = time.Time{}
+		}
+
+		if timestamps == 0 || timestamps == 3 {
+			value.server = time.Time{}
+		}
+
+		encoder.writeDataValue(value)
+	}
+
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) write(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	count := decoder.readArrayLength()
+
+	if count == 0 {
+		return StatusBadNothingToDo
+	}
+
+	results := make([]StatusCode, count)
+
+	for i := range results {
+		id := decoder.readNodeId()
+		attribute := decoder.readUint32()
+		indexRange := decoder.readString()
+		value := decoder.readDataValue()
+
+		if decoder.err != nil {
+			return StatusBadDecodingError
+		}
+
+		node, ok := server.space.lookup(id)
+
+		switch {
+		case !ok:
+			results[i] = StatusBadNodeIdUnknown
+		case attribute != attributeValue:
+			results[i] = StatusBadNotWritable
+		case indexRange != "":
+			results[i] = StatusBadIndexRangeInvalid
+		case !value.hasValue:
+			results[i] = StatusBadTypeMismatch
+		default:
+			results[i] = server.space.write(node, value.value)
+		}
+	}
+
+	encoder.writeStatusCodes(results)
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) call(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	count := decoder.readArrayLength()
+
+	if count == 0 {
+		return StatusBadNothingToDo
+	}
+
+	encoder.writeInt32(int32(count))
+
+	for i := 0; i < count; i++ {
+		object := decoder.readNodeId()
+		method := decoder.readNodeId()
+		arguments := decoder.readArrayLength()
+
+		for j := 0; j < arguments; j++ {
+			decoder.readVariant()
+		}
+
+		if decoder.err != nil {
+			return StatusBadDecodingError
+		}
+
+		status := StatusGood
+		node, ok := server.space.lookup(method)
+
+		if parent, found := server.space.parentOf(method); !ok || node.class != nodeClassMethod || !found || parent != object {
+			status = StatusBadMethodInvalid
+		} else if arguments > 0 {
+			status = StatusBadTooManyArguments
+		} else {
+			status = node.call()
+		}
+
+		encoder.writeStatusCode(status)
+		encoder.writeEmptyArray()
+		encoder.writeEmptyArray()
+		encoder.writeEmptyArray()
+	}
+
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) createSubscription(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	interval := decoder.readDouble()
+	lifetimeCount := decoder.readUint32()
+	keepAliveCount := decoder.readUint32()
+	decoder.readUint32()
+	publishing := decoder.readBoolean()
+	decoder.readByte()
+
+	if decoder.err != nil {
+		return StatusBadDecodingError
+	}
+
+	subscription := newSubscription(server.nextHandle(), session)
+	subscription.revise(interval, lifetimeCount, keepAliveCount)
+	subscription.publishing = publishing
+
+	session.mutex.Lock()
+	session.subscriptions[subscription.id] = subscription
+	session.mutex.Unlock()
+
+	subscription.start()
+
+	encoder.writeUint32(subscription.id)
+	encoder.writeDouble(float64(subscription.interval) / float64(time.Millisecond))
+	encoder.writeUint32(subscription.lifetimeCount)
+	encoder.writeUint32(subscription.keepAliveCount)
+
+	return StatusGood
+}
+
+func (server *Server) modifySubscription(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	id := decoder.readUint32()
+	interval := decoder.readDouble()
+	lifetimeCount := decoder.readUint32()
+	keepAliveCount := decoder.readUint32()
+	decoder.readUint32()
+	decoder.readByte()
+
+	if decoder.err != nil {
+		return StatusBadDecodingError
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	subscription, ok := session.subscriptions[id]
+
+	if !ok {
+		return StatusBadSubscriptionIdInvalid
+	}
+
+	subscription.revise(interval, lifetimeCount, keepAliveCount)
+
+	select {
+	case subscription.reset <- struct{}{}:
+	default:
+	}
+
+	encoder.writeDouble(float64(subscription.interval) / float64(time.Millisecond))
+	encoder.writeUint32(subscription.lifetimeCount)
+	encoder.writeUint32(subscription.keepAliveCount)
+
+	return StatusGood
+}
+
+func (server *Server) setPublishingMode(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	publishing := decoder.readBoolean()
+	ids := decoder.readUint32s()
+
+	if len(ids) == 0 {
+		return StatusBadNothingToDo
+	}
+
+	results := make([]StatusCode, len(ids))
+
+	session.mutex.Lock()
+
+	for i, id := range ids {
+		if subscription, ok := session.subscriptions[id]; ok {
+			subscription.publishing = publishing
+			continue
+		}
+
+		results[i] = StatusBadSubscriptionIdInvalid
+	}
+
+	session.mutex.Unlock()
+
+	encoder.writeStatusCodes(results)
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) deleteSubscriptions(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	ids := decoder.readUint32s()
+
+	if len(ids) == 0 {
+		return StatusBadNothingToDo
+	}
+
+	results := make([]StatusCode, len(ids))
+	var deleted []*subscription
+
+	session.mutex.Lock()
+
+	for i, id := range ids {
+		if subscription, ok := session.subscriptions[id]; ok {
+			delete(session.subscriptions, id)
+			deleted = append(deleted, subscription)
+			continue
+		}
+
+		results[i] = StatusBadSubscriptionIdInvalid
+	}
+
+	session.mutex.Unlock()
+
+	for _, subscription := range deleted {
+		subscription.stop()
+	}
+
+	encoder.writeStatusCodes(results)
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) createMonitoredItems(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	id := decoder.readUint32()
+	decoder.readUint32()
+	count := decoder.readArrayLength()
+
+	if count == 0 {
+		return StatusBadNothingToDo
+	}
+
+	session.mutex.Lock()
+	subscription, ok := session.subscriptions[id]
+	session.mutex.Unlock()
+
+	if !ok {
+		return StatusBadSubscriptionIdInvalid
+	}
+
+	encoder.writeInt32(int32(count))
+
+	for i := 0; i < count; i++ {
+		target, attribute := decoder.readReadValueId()
+		mode := decoder.readUint32()
+		handle := decoder.readUint32()
+		decoder.readDouble()
+		decoder.readExtensionObject()
+		queueSize := decoder.readUint32()
+		discardOldest := decoder.readBoolean()
+
+		if decoder.err != nil {
+			return StatusBadDecodingError
+		}
+
+		node, ok := server.space.lookup(target)
+		status := StatusGood
+
+		if !ok {
+			status = StatusBadNodeIdUnknown
+		} else if value := server.space.readAttribute(node, attribute); value.status == StatusBadAttributeIdInvalid {
+			status = StatusBadAttributeIdInvalid
+		}
+
+		if status != StatusGood {
+			encoder.writeStatusCode(status)
+			encoder.writeUint32(0)
+			encoder.writeDouble(0)
+			encoder.writeUint32(0)
+			encoder.writeNullExtensionObject()
+			continue
+		}
+
+		item := &monitoredItem{
+			id:            server.nextHandle(),
+			handle:        handle,
+			node:          node,
+			mode:          mode,
+			queueSize:     min(max(queueSize, 1), maxQueueSize),
+			discardOldest: discardOldest,
+			queue:         nil,
+			listener:      nil,
+			events:        server.events,
+		}
+
+		session.mutex.Lock()
+		subscription.items[item.id] = item
+		item.push(server.space.readAttribute(node, attribute))
+		interval := subscription.interval
+		session.mutex.Unlock()
+
+		if node.resource != "" && attribute == attributeValue {
+			item.watch(session)
+		}
+
+		encoder.writeStatusCode(StatusGood)
+		encoder.writeUint32(item.id)
+		encoder.writeDouble(float64(interval) / float64(time.Millisecond))
+		encoder.writeUint32(item.queueSize)
+		encoder.writeNullExtensionObject()
+	}
+
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) deleteMonitoredItems(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	id := decoder.readUint32()
+	ids := decoder.readUint32s()
+
+	if len(ids) == 0 {
+		return StatusBadNothingToDo
+	}
+
+	results := make([]StatusCode, len(ids))
+	var deleted []*monitoredItem
+
+	session.mutex.Lock()
+	subscription, ok := session.subscriptions[id]
+
+	if !ok {
+		session.mutex.Unlock()
+		return StatusBadSubscriptionIdInvalid
+	}
+
+	for i, id := range ids {
+		if item, ok := subscription.items[id]; ok {
+			delete(subscription.items, id)
+			deleted = append(deleted, item)
+			continue
+		}
+
+		results[i] = StatusBadMonitoredItemIdInvalid
+	}
+
+	session.mutex.Unlock()
+
+	for _, item := range deleted {
+		item.stop()
+	}
+
+	encoder.writeStatusCodes(results)
+	encoder.writeEmptyArray()
+
+	return StatusGood
+}
+
+func (server *Server) republish(channel *channel, session *session, decoder *decoder, encoder *encoder) StatusCode {
+	id := decoder.readUint32()
+	sequence := decoder.readUint32()
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	subscription, ok := session.subscriptions[id]
+
+	if !ok {
+		return StatusBadSubscriptionIdInvalid
+	}
+
+	message, ok := subscription.retransmissions[sequence]
+
+	if !ok {
+		return StatusBadMessageNotAvailable
+	}
+
+	encoder.writeRaw(message)
+
+	return StatusGood
+}
+
+func (server *Server) publish(channel *channel, session *session, requestId uint32, header requestHeader, decoder *decoder) StatusCode {
+	if status := validateSession(channel, session); status != StatusGood {
+		return status
+	}
+
+	count := decoder.readArrayLength()
+	results := make([]StatusCode, count)
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	for i := range results {
+		id := decoder.readUint32()
+		sequence := decoder.readUint32()
+		subscription, ok := session.subscriptions[id]
+
+		switch {
+		case !ok:
+			results[i] = StatusBadSubscriptionIdInvalid
+		case subscription.retransmissions[sequence] == nil:
+			results[i] = StatusBadSequenceNumberUnknown
+		default:
+			delete(subscription.retransmissions, sequence)
+		}
+	}
+
+	if decoder.err != nil {
+		return StatusBadDecodingError
+	}
+
+	if len(session.subscriptions) == 0 {
+		return StatusBadNoSubscription
+	}
+
+	if len(session.publishes) >= maxPublishRequests {
+		return StatusBadTooManyPublishRequests
+	}
+
+	session.publishes = append(session.publishes, &publishRequest{
+		channel:   channel,
+		requestId: requestId,
+		handle:    header.handle,
+		results:   slices.Clip(results),
+	})
+
+	return StatusGood
+}
@@ -0,0 +1,331 @@
+package opcua
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type browsed struct {
+	referenceType uint32
+	forward       bool
+	target        NodeId
+	browseName    string
+	class         uint32
+}
+
+func newTestServer(t *testing.T) (*Server, *session) {
+	t.Helper()
+
+	events := event.NewEvents(time.Second)
+	store := storage.NewStorage(map[string]storage.Resource{
+		"tank/level":   resource.NewStatic[float32](1.5),
+		"tank/limit":   resource.NewConstant[int32](10),
+		"tank/counter": resource.NewIncrement[int32](0, 1, time.Hour),
+	})
+
+	if err := store.Start(context.Background(), events, clock.NewReal(), 1); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	t.Cleanup(func() {
+		store.Stop(context.Background())
+	})
+
+	server := NewServer(store, events)
+	server.space = newAddressSpace(store, events)
+
+	return server, newSession(1, nil)
+}
+
+func browse(t *testing.T, server *Server, session *session, id NodeId, direction uint32) []browsed {
+	t.Helper()
+
+	request := newEncoder()
+	request.writeNodeId(NodeId{})
+	request.writeDateTime(time.Time{})
+	request.writeUint32(0)
+	request.writeUint32(0)
+	request.writeInt32(1)
+	request.writeNodeId(id)
+	request.writeUint32(direction)
+	request.writeNodeId(numericNodeId(idHierarchicalReferences))
+	request.writeBoolean(true)
+	request.writeUint32(0)
+	request.writeUint32(0x3F)
+
+	response := newEncoder()
+
+	if status := server.browse(nil, session, newDecoder(request.bytes()), response); status != StatusGood {
+		t.Fatalf("browse %s: %v", id, status)
+	}
+
+	decoder := newDecoder(response.bytes())
+	decoder.readArrayLength()
+
+	if status := decoder.readStatusCode(); status != StatusGood {
+		t.Fatalf("browse %s: %v", id, status)
+	}
+
+	decoder.readByteString()
+
+	var references []browsed
+
+	for i := decoder.readArrayLength(); i > 0; i-- {
+		reference := browsed{}
+		reference.referenceType = decoder.readNodeId().numeric
+		reference.forward = decoder.readBoolean()
+		reference.target = decoder.readExpandedNodeId()
+		reference.browseName = decoder.readQualifiedName().name
+		decoder.readLocalizedText()
+		reference.class = decoder.readUint32()
+		decoder.readExpandedNodeId()
+		references = append(references, reference)
+	}
+
+	if decoder.readArrayLength(); decoder.err != nil || decoder.remaining() != 0 {
+		t.Fatalf("browse %s: malformed response, %v", id, decoder.err)
+	}
+
+	return references
+}
+
+func read(t *testing.T, server *Server, ids ...NodeId) []dataValue {
+	t.Helper()
+
+	request := newEncoder()
+	request.writeDouble(0)
+	request.writeUint32(0)
+	request.writeInt32(int32(len(ids)))
+
+	for _, id := range ids {
+		request.writeNodeId(id)
+		request.writeUint32(attributeValue)
+		request.writeNullString()
+		request.writeQualifiedName(qualifiedName{})
+	}
+
+	response := newEncoder()
+
+	if status := server.read(nil, nil, newDecoder(request.bytes()), response); status != StatusGood {
+		t.Fatalf("read: %v", status)
+	}
+
+	decoder := newDecoder(response.bytes())
+	values := make([]dataValue, decoder.readArrayLength())
+
+	for i := range values {
+		values[i] = decoder.readDataValue()
+	}
+
+	if decoder.readArrayLength(); decoder.err != nil || decoder.remaining() != 0 {
+		t.Fatalf("read: malformed response, %v", decoder.err)
+	}
+
+	return values
+}
+
+func write(t *testing.T, server *Server, id NodeId, value any) StatusCode {
+	t.Helper()
+
+	request := newEncoder()
+	request.writeInt32(1)
+	request.writeNodeId(id)
+	request.writeUint32(attributeValue)
+	request.writeNullString()
+	request.writeDataValue(dataValue{value: value, hasValue: true})
+
+	response := newEncoder()
+
+	if status := server.write(nil, nil, newDecoder(request.bytes()), response); status != StatusGood {
+		t.Fatalf("write: %v", status)
+	}
+
+	decoder := newDecoder(response.bytes())
+	decoder.readArrayLength()
+	status := decoder.readStatusCode()
+
+	if decoder.readArrayLength(); decoder.err != nil || decoder.remaining() != 0 {
+		t.Fatalf("write: malformed response, %v", decoder.err)
+	}
+
+	return status
+}
+
+func call(t *testing.T, server *Server, object NodeId, method NodeId) StatusCode {
+	t.Helper()
+
+	request := newEncoder()
+	request.writeInt32(1)
+	request.writeNodeId(object)
+	request.writeNodeId(method)
+	request.writeEmptyArray()
+
+	response := newEncoder()
+
+	if status := server.call(nil, nil, newDecoder(request.bytes()), response); status != StatusGood {
+		t.Fatalf("call: %v", status)
+	}
+
+	decoder := newDecoder(response.bytes())
+	decoder.readArrayLength()
+
+	return decoder.readStatusCode()
+}
+
+func TestBrowseFolders(t *testing.T) {
+	server, session := newTestServer(t)
+
+	objects := browse(t, server, session, numericNodeId(idObjectsFolder), browseForward)
+
+	if !slices.ContainsFunc(objects, func(reference browsed) bool {
+		return reference.target == folderNodeId("tank") && reference.referenceType == idOrganizes && reference.class == nodeClassObject
+	}) {
+		t.Fatalf("expected the tank folder under Objects, got %+v", objects)
+	}
+
+	var names []string
+
+	for _, reference := range browse(t, server, session, folderNodeId("tank"), browseForward) {
+		names = append(names, reference.browseName)
+	}
+
+	slices.Sort(names)
+
+	if !slices.Equal(names, []string{"counter", "level", "limit"}) {
+		t.Fatalf("expected only the tank variables, got %v", names)
+	}
+}
+
+func TestBrowseMethodsUnderOwner(t *testing.T) {
+	server, session := newTestServer(t)
+
+	var methods []string
+
+	for _, reference := range browse(t, server, session, StringNodeId(1, "tank/counter"), browseForward) {
+		if reference.referenceType != idHasComponent || reference.class != nodeClassMethod {
+			t.Fatalf("unexpected reference %+v", reference)
+		}
+
+		methods = append(methods, reference.browseName)
+	}
+
+	slices.Sort(methods)
+
+	if !slices.Equal(methods, []string{"pause", "reset", "resume"}) {
+		t.Fatalf("expected the counter actions, got %v", methods)
+	}
+
+	inverse := browse(t, server, session, StringNodeId(1, "tank/counter:reset"), browseInverse)
+
+	if len(inverse) != 1 || inverse[0].target != StringNodeId(1, "tank/counter") {
+		t.Fatalf("expected the counter to own its method, got %+v", inverse)
+	}
+}
+
+func TestBrowseUnknownNode(t *testing.T) {
+	server, session := newTestServer(t)
+
+	request := newEncoder()
+	request.writeNodeId(NodeId{})
+	request.writeDateTime(time.Time{})
+	request.writeUint32(0)
+	request.writeUint32(0)
+	request.writeInt32(1)
+	request.writeNodeId(StringNodeId(1, "missing"))
+	request.writeUint32(browseForward)
+	request.writeNodeId(NodeId{})
+	request.writeBoolean(true)
+	request.writeUint32(0)
+	request.writeUint32(0x3F)
+
+	response := newEncoder()
+	server.browse(nil, session, newDecoder(request.bytes()), response)
+
+	decoder := newDecoder(response.bytes())
+	decoder.readArrayLength()
+
+	if status := decoder.readStatusCode(); status != StatusBadNodeIdUnknown {
+		t.Fatalf("expected %v, got %v", StatusBadNodeIdUnknown, status)
+	}
+}
+
+func TestReadValues(t *testing.T) {
+	server, _ := newTestServer(t)
+	values := read(t, server, StringNodeId(1, "tank/level"), StringNodeId(1, "tank/limit"), StringNodeId(1, "missing"), folderNodeId("tank"))
+
+	if values[0].value != float32(1.5) || values[0].status != StatusGood {
+		t.Fatalf("unexpected level %+v", values[0])
+	}
+
+	if values[1].value != int32(10) || values[1].status != StatusGood {
+		t.Fatalf("unexpected limit %+v", values[1])
+	}
+
+	if values[2].status != StatusBadNodeIdUnknown {
+		t.Fatalf("expected %v, got %+v", StatusBadNodeIdUnknown, values[2])
+	}
+
+	if values[3].status != StatusBadAttributeIdInvalid {
+		t.Fatalf("expected %v, got %+v", StatusBadAttributeIdInvalid, values[3])
+	}
+}
+
+func TestWriteValues(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	tests := []struct {
+		id     NodeId
+		value  any
+		status StatusCode
+	}{
+		{id: StringNodeId(1, "tank/level"), value: float32(2.5), status: StatusGood},
+		{id: StringNodeId(1, "tank/level"), value: int32(2), status: StatusBadTypeMismatch},
+		{id: StringNodeId(1, "tank/limit"), value: int32(20), status: StatusBadNotWritable},
+		{id: StringNodeId(1, "missing"), value: int32(1), status: StatusBadNodeIdUnknown},
+	}
+
+	for _, test := range tests {
+		if status := write(t, server, test.id, test.value); status != test.status {
+			t.Fatalf("%s = %v: expected %v, got %v", test.id, test.value, test.status, status)
+		}
+	}
+
+	if values := read(t, server, StringNodeId(1, "tank/level")); values[0].value != float32(2.5) {
+		t.Fatalf("expected the write to be visible, got %+v", values[0])
+	}
+}
+
+func TestCallMethod(t *testing.T) {
+	server, _ := newTestServer(t)
+	method := StringNodeId(1, "tank/counter:reset")
+
+	if status := call(t, server, StringNodeId(1, "tank/counter"), method); status != StatusGood {
+		t.Fatalf("expected the call to succeed, got %v", status)
+	}
+
+	if status := call(t, server, folderNodeId("tank"), method); status != StatusBadMethodInvalid {
+		t.Fatalf("expected %v for the wrong owner, got %v", StatusBadMethodInvalid, status)
+	}
+}
+
+func TestMessageBeforeOpen(t *testing.T) {
+	server, _ := newTestServer(t)
+	channel := newChannel(server, nil)
+
+	body := newEncoder()
+	body.writeUint32(0)
+	body.writeUint32(0)
+	body.writeUint32(1)
+	body.writeUint32(1)
+
+	if err := channel.message('F', body.bytes()); err != StatusBadSecureChannelIdInvalid {
+		t.Fatalf("expected %v, got %v", StatusBadSecureChannelIdInvalid, err)
+	}
+}
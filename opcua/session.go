@@ -0,0 +1,369 @@
+package opcua
+
+import (
+	"crypto/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/studiolambda/immersim/event"
+)
+
+const (
+	maxPublishRequests  = 64
+	maxRetransmissions  = 16
+	maxQueueSize        = 100
+	minPublishInterval  = 50 * time.Millisecond
+	defaultKeepAlive    = 10
+	monitoringReporting = 2
+	listenerBufferSize  = 16
+)
+
+type publishRequest struct {
+	channel   *channel
+	requestId uint32
+	handle    uint32
+	results   []StatusCode
+}
+
+type session struct {
+	id            NodeId
+	token         NodeId
+	channel       *channel
+	activated     bool
+	subscriptions map[uint32]*subscription
+	publishes     []*publishRequest
+	continuations map[string][]reference
+	mutex         sync.Mutex
+}
+
+type subscription struct {
+	id               uint32
+	session          *session
+	interval         time.Duration
+	keepAliveCount   uint32
+	lifetimeCount    uint32
+	publishing       bool
+	items            map[uint32]*monitoredItem
+	sequence         uint32
+	keepAliveCounter uint32
+	lifetimeCounter  uint32
+	retransmissions  map[uint32][]byte
+	reset            chan struct{}
+	quit             chan struct{}
+	wg               sync.WaitGroup
+}
+
+type monitoredItem struct {
+	id            uint32
+	handle        uint32
+	node          *node
+	mode          uint32
+	queueSize     uint32
+	discardOldest bool
+	queue         []dataValue
+	listener      chan any
+	events        *event.Events
+	wg            sync.WaitGroup
+}
+
+func newSession(id uint32, channel *channel) *session {
+	token := make([]byte, 32)
+	rand.Read(token)
+
+	return &session{
+		id:            NodeId{Namespace: 1, kind: identifierNumeric, numeric: id},
+		token:         NodeId{Namespace: 1, kind: identifierOpaque, text: string(token)},
+		channel:       channel,
+		activated:     false,
+		subscriptions: make(map[uint32]*subscription),
+		publishes:     nil,
+		continuations: make(map[string][]reference),
+		mutex:         sync.Mutex{},
+	}
+}
+
+func (session *session) close() {
+	session.mutex.Lock()
+	subscriptions := session.subscriptions
+	session.subscriptions = make(map[uint32]*subscription)
+	session.publishes = nil
+	session.mutex.Unlock()
+
+	for _, subscription := range subscriptions {
+		subscription.stop()
+	}
+}
+
+func (session *session) takePublish() *publishRequest {
+	if len(session.publishes) == 0 {
+		return nil
+	}
+
+	request := session.publishes[0]
+	session.publishes = session.publishes[1:]
+
+	return request
+}
+
+func newSubscription(id uint32, session *session) *subscription {
+	return &subscription{
+		id:               id,
+		session:          session,
+		interval:         time.Second,
+		keepAliveCount:   defaultKeepAlive,
+		lifetimeCount:    3 * defaultKeepAlive,
+		publishing:       true,
+		items:            make(map[uint32]*monitoredItem),
+		sequence:         1,
+		keepAliveCounter: 0,
+		lifetimeCounter:  0,
+		retransmissions:  make(map[uint32][]byte),
+		reset:            make(chan struct{}, 1),
+		quit:             make(chan struct{}),
+		wg:               sync.WaitGroup{},
+	}
+}
+
+func (subscription *subscription) revise(interval float64, lifetimeCount uint32, keepAliveCount uint32) {
+	subscription.interval = max(time.Duration(interval*float64(time.Millisecond)), minPublishInterval)
+	subscription.keepAliveCount = keepAliveCount
+
+	if subscription.keepAliveCount == 0 {
+		subscription.keepAliveCount = defaultKeepAlive
+	}
+
+	subscription.lifetimeCount = max(lifetimeCount, 3*subscription.keepAliveCount)
+}
+
+func (subscription *subscription) start() {
+	subscription.wg.Add(1)
+	go subscription.loop()
+}
+
+func (subscription *subscription) stop() {
+	close(subscription.quit)
+	subscription.wg.Wait()
+
+	for _, item := range subscription.items {
+		item.stop()
+	}
+}
+
+func (subscription *subscription) loop() {
+	defer subscription.wg.Done()
+
+	subscription.session.mutex.Lock()
+	ticker := time.NewTicker(subscription.interval)
+	subscription.session.mutex.Unlock()
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if subscription.tick() {
+				return
+			}
+		case <-subscription.reset:
+			subscription.session.mutex.Lock()
+			ticker.Reset(subscription.interval)
+			subscription.session.mutex.Unlock()
+		case <-subscription.quit:
+			return
+		}
+	}
+}
+
+func (subscription *subscription) tick() bool {
+	session := subscription.session
+	session.mutex.Lock()
+
+	if len(session.publishes) == 0 {
+		subscription.lifetimeCounter++
+
+		if subscription.lifetimeCounter >= subscription.lifetimeCount {
+			delete(session.subscriptions, subscription.id)
+			session.mutex.Unlock()
+
+			for _, item := range subscription.items {
+				item.stop()
+			}
+
+			return true
+		}
+	} else {
+		subscription.lifetimeCounter = 0
+	}
+
+	var notifications []monitoredItemNotification
+
+	if subscription.publishing {
+		notifications = subscription.collect()
+	}
+
+	if len(notifications) == 0 {
+		subscription.keepAliveCounter++
+
+		if subscription.keepAliveCounter < subscription.keepAliveCount {
+			session.mutex.Unlock()
+			return false
+		}
+	}
+
+	request := session.takePublish()
+
+	if request == nil {
+		session.mutex.Unlock()
+		return false
+	}
+
+	subscription.keepAliveCounter = 0
+
+	message := newEncoder()
+
+	if len(notifications) == 0 {
+		message.writeNotificationMessage(subscription.sequence, nil)
+	} else {
+		message.writeNotificationMessage(subscription.sequence, notifications)
+		subscription.retain(subscription.sequence, message.bytes())
+		subscription.sequence++
+	}
+
+	response := subscription.publishResponse(request, message.bytes())
+	session.mutex.Unlock()
+
+	request.channel.send(request.requestId, response)
+
+	return false
+}
+
+func (subscription *subscription) collect() []monitoredItemNotification {
+	var notifications []monitoredItemNotification
+
+	ids := make([]uint32, 0, len(subscription.items))
+
+	for id := range subscription.items {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		item := subscription.items[id]
+
+		for _, value := range item.queue {
+			notifications = append(notifications, monitoredItemNotification{
+				handle: item.handle,
+				value:  value,
+			})
+		}
+
+		item.queue = nil
+	}
+
+	return notifications
+}
+
+func (subscription *subscription) retain(sequence uint32, message []byte) {
+	subscription.retransmissions[sequence] = message
+
+	for len(subscription.retransmissions) > maxRetransmissions {
+		delete(subscription.retransmissions, slices.Min(subscription.available()))
+	}
+}
+
+func (subscription *subscription) available() []uint32 {
+	sequences := make([]uint32, 0, len(subscription.retransmissions))
+
+	for sequence := range subscription.retransmissions {
+		sequences = append(sequences, sequence)
+	}
+
+	slices.Sort(sequences)
+
+	return sequences
+}
+
+func (subscription *subscription) publishResponse(request *publishRequest, message []byte) []byte {
+	encoder := newEncoder()
+	encoder.writeNodeId(numericNodeId(encodingPublishResponse))
+	encoder.writeResponseHeader(request.handle, StatusGood)
+	encoder.writeUint32(subscription.id)
+
+	available := subscription.available()
+	encoder.writeInt32(int32(len(available)))
+
+	for _, sequence := range available {
+		encoder.writeUint32(sequence)
+	}
+
+	encoder.writeBoolean(false)
+	encoder.writeRaw(message)
+	encoder.writeStatusCodes(request.results)
+	encoder.writeEmptyArray()
+
+	return encoder.bytes()
+}
+
+func (item *monitoredItem) push(value dataValue) {
+	if item.mode != monitoringReporting {
+		return
+	}
+
+	item.queue = append(item.queue, value)
+
+	if uint32(len(item.queue)) > item.queueSize {
+		if item.discardOldest {
+			item.queue = item.queue[1:]
+		} else {
+			item.queue = append(item.queue[:item.queueSize-1], value)
+		}
+	}
+}
+
+func (item *monitoredItem) watch(session *session) {
+	item.listener = make(chan any, listenerBufferSize)
+
+	item.wg.Add(1)
+	go func() {
+		defer item.wg.Done()
+
+		for payload := range item.listener {
+			changed, ok := payload.(event.ChangedPayload)
+
+			if !ok {
+				continue
+			}
+
+			session.mutex.Lock()
+			item.push(dataValue{
+				value:    changed.Value,
+				hasValue: changed.Value != nil,
+				status:   StatusCode(changed.Quality),
+				source:   changed.SourceTimestamp,
+				server:   changed.ServerTimestamp,
+			})
+			session.mutex.Unlock()
+		}
+	}()
+
+	policy := event.DropNewest
+
+	if item.discardOldest {
+		policy = event.DropOldest
+	}
+
+	item.events.SubscribeWith(event.Changed(item.node.resource), item.listener, policy, int(item.queueSize))
+}
+
+func (item *monitoredItem) stop() {
+	if item.listener == nil {
+		return
+	}
+
+	item.events.Unsubscribe(event.Changed(item.node.resource), item.listener)
+	close(item.listener)
+	item.wg.Wait()
+	item.listener = nil
+}
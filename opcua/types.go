@@ -0,0 +1,252 @@
+package opcua
+
+import (
+	"fmt"
+	"time"
+)
+
+type StatusCode uint32
+
+const (
+	StatusGood                        StatusCode = 0x00000000
+	StatusBadUnexpectedError          StatusCode = 0x80010000
+	StatusBadInternalError            StatusCode = 0x80020000
+	StatusBadDecodingError            StatusCode = 0x80070000
+	StatusBadServiceUnsupported       StatusCode = 0x800B0000
+	StatusBadNothingToDo              StatusCode = 0x800F0000
+	StatusBadTooManyOperations        StatusCode = 0x80100000
+	StatusBadIdentityTokenInvalid     StatusCode = 0x80200000
+	StatusBadSecureChannelIdInvalid   StatusCode = 0x80220000
+	StatusBadSessionIdInvalid         StatusCode = 0x80250000
+	StatusBadSessionNotActivated      StatusCode = 0x80270000
+	StatusBadSubscriptionIdInvalid    StatusCode = 0x80280000
+	StatusBadNodeIdUnknown            StatusCode = 0x80340000
+	StatusBadAttributeIdInvalid       StatusCode = 0x80350000
+	StatusBadIndexRangeInvalid        StatusCode = 0x80360000
+	StatusBadNotReadable              StatusCode = 0x803A0000
+	StatusBadNotWritable              StatusCode = 0x803B0000
+	StatusBadOutOfRange               StatusCode = 0x803C0000
+	StatusBadMonitoredItemIdInvalid   StatusCode = 0x80420000
+	StatusBadContinuationPointInvalid StatusCode = 0x804A0000
+	StatusBadSecurityPolicyRejected   StatusCode = 0x80550000
+	StatusBadWriteNotSupported        StatusCode = 0x80730000
+	StatusBadTypeMismatch             StatusCode = 0x80740000
+	StatusBadMethodInvalid            StatusCode = 0x80750000
+	StatusBadTooManyPublishRequests   StatusCode = 0x80780000
+	StatusBadNoSubscription           StatusCode = 0x80790000
+	StatusBadSequenceNumberUnknown    StatusCode = 0x807A0000
+	StatusBadMessageNotAvailable      StatusCode = 0x807B0000
+	StatusBadTcpMessageTypeInvalid    StatusCode = 0x807E0000
+	StatusBadTcpMessageTooLarge       StatusCode = 0x80800000
+	StatusBadTooManyArguments         StatusCode = 0x80E50000
+)
+
+func (status StatusCode) Error() string {
+	return fmt.Sprintf("opcua status 0x%08X", uint32(status))
+}
+
+const (
+	identifierNumeric byte = iota
+	identifierString
+	identifierGuid
+	identifierOpaque
+)
+
+type NodeId struct {
+	Namespace uint16
+	kind      byte
+	numeric   uint32
+	text      string
+}
+
+func numericNodeId(id uint32) NodeId {
+	return NodeId{Namespace: 0, kind: identifierNumeric, numeric: id}
+}
+
+func StringNodeId(namespace uint16, id string) NodeId {
+	return NodeId{Namespace: namespace, kind: identifierString, text: id}
+}
+
+func (id NodeId) String() string {
+	switch id.kind {
+	case identifierNumeric:
+		return fmt.Sprintf("ns=%d;i=%d", id.Namespace, id.numeric)
+	case identifierString:
+		return fmt.Sprintf("ns=%d;s=%s", id.Namespace, id.text)
+	case identifierGuid:
+		return fmt.Sprintf("ns=%d;g=%x", id.Namespace, id.text)
+	}
+
+	return fmt.Sprintf("ns=%d;b=%x", id.Namespace, id.text)
+}
+
+func (id NodeId) isNull() bool {
+	return id == NodeId{}
+}
+
+type qualifiedName struct {
+	namespace uint16
+	name      string
+}
+
+type localizedText struct {
+	locale string
+	text   string
+}
+
+type dataValue struct {
+	value    any
+	hasValue bool
+	status   StatusCode
+	source   time.Time
+	server   time.Time
+}
+
+const (
+	variantNull            byte = 0
+	variantBoolean         byte = 1
+	variantSByte           byte = 2
+	variantByte            byte = 3
+	variantInt16           byte = 4
+	variantUint16          byte = 5
+	variantInt32           byte = 6
+	variantUint32          byte = 7
+	variantInt64           byte = 8
+	variantUint64          byte = 9
+	variantFloat           byte = 10
+	variantDouble          byte = 11
+	variantString          byte = 12
+	variantDateTime        byte = 13
+	variantGuid            byte = 14
+	variantByteString      byte = 15
+	variantXmlElement      byte = 16
+	variantNodeId          byte = 17
+	variantExpandedNodeId  byte = 18
+	variantStatusCode      byte = 19
+	variantQualifiedName   byte = 20
+	variantLocalizedText   byte = 21
+	variantExtensionObject byte = 22
+	variantDataValue       byte = 23
+	variantVariant         byte = 24
+	variantDiagnosticInfo  byte = 25
+	variantDimensions      byte = 0x40
+	variantArray           byte = 0x80
+)
+
+const (
+	nodeClassObject   uint32 = 1
+	nodeClassVariable uint32 = 2
+	nodeClassMethod   uint32 = 4
+)
+
+const (
+	attributeNodeId                  uint32 = 1
+	attributeNodeClass               uint32 = 2
+	attributeBrowseName              uint32 = 3
+	attributeDisplayName             uint32 = 4
+	attributeDescription             uint32 = 5
+	attributeWriteMask               uint32 = 6
+	attributeUserWriteMask           uint32 = 7
+	attributeEventNotifier           uint32 = 12
+	attributeValue                   uint32 = 13
+	attributeDataType                uint32 = 14
+	attributeValueRank               uint32 = 15
+	attributeArrayDimensions         uint32 = 16
+	attributeAccessLevel             uint32 = 17
+	attributeUserAccessLevel         uint32 = 18
+	attributeMinimumSamplingInterval uint32 = 19
+	attributeHistorizing             uint32 = 20
+	attributeExecutable              uint32 = 21
+	attributeUserExecutable          uint32 = 22
+)
+
+const (
+	accessLevelRead  byte = 0x01
+	accessLevelWrite byte = 0x02
+)
+
+const (
+	browseForward uint32 = 0
+	browseInverse uint32 = 1
+	browseBoth    uint32 = 2
+)
+
+const (
+	idBoolean                uint32 = 1
+	idInt32                  uint32 = 6
+	idFloat                  uint32 = 10
+	idString                 uint32 = 12
+	idBaseDataType           uint32 = 24
+	idReferences             uint32 = 31
+	idHierarchicalReferences uint32 = 33
+	idHasChild               uint32 = 34
+	idOrganizes              uint32 = 35
+	idHasTypeDefinition      uint32 = 40
+	idAggregates             uint32 = 44
+	idHasProperty            uint32 = 46
+	idHasComponent           uint32 = 47
+	idBaseObjectType         uint32 = 58
+	idFolderType             uint32 = 61
+	idBaseDataVariableType   uint32 = 63
+	idPropertyType           uint32 = 68
+	idRootFolder             uint32 = 84
+	idObjectsFolder          uint32 = 85
+	idServerType             uint32 = 2004
+	idServer                 uint32 = 2253
+	idServerArray            uint32 = 2254
+	idNamespaceArray         uint32 = 2255
+)
+
+const (
+	encodingAnonymousIdentityToken       uint32 = 321
+	encodingServiceFault                 uint32 = 397
+	encodingFindServersRequest           uint32 = 422
+	encodingFindServersResponse          uint32 = 425
+	encodingGetEndpointsRequest          uint32 = 428
+	encodingGetEndpointsResponse         uint32 = 431
+	encodingOpenSecureChannelRequest     uint32 = 446
+	encodingOpenSecureChannelResponse    uint32 = 449
+	encodingCloseSecureChannelRequest    uint32 = 452
+	encodingCreateSessionRequest         uint32 = 461
+	encodingCreateSessionResponse        uint32 = 464
+	encodingActivateSessionRequest       uint32 = 467
+	encodingActivateSessionResponse      uint32 = 470
+	encodingCloseSessionRequest          uint32 = 473
+	encodingCloseSessionResponse         uint32 = 476
+	encodingBrowseRequest                uint32 = 527
+	encodingBrowseResponse               uint32 = 530
+	encodingBrowseNextRequest            uint32 = 533
+	encodingBrowseNextResponse           uint32 = 536
+	encodingReadRequest                  uint32 = 631
+	encodingReadResponse                 uint32 = 634
+	encodingWriteRequest                 uint32 = 673
+	encodingWriteResponse                uint32 = 676
+	encodingCallRequest                  uint32 = 712
+	encodingCallResponse                 uint32 = 715
+	encodingCreateMonitoredItemsRequest  uint32 = 751
+	encodingCreateMonitoredItemsResponse uint32 = 754
+	encodingDeleteMonitoredItemsRequest  uint32 = 781
+	encodingDeleteMonitoredItemsResponse uint32 = 784
+	encodingCreateSubscriptionRequest    uint32 = 787
+	encodingCreateSubscriptionResponse   uint32 = 790
+	encodingModifySubscriptionRequest    uint32 = 793
+	encodingModifySubscriptionResponse   uint32 = 796
+	encodingSetPublishingModeRequest     uint32 = 799
+	encodingSetPublishingModeResponse    uint32 = 802
+	encodingDataChangeNotification       uint32 = 811
+	encodingPublishRequest               uint32 = 826
+	encodingPublishResponse              uint32 = 829
+	encodingRepublishRequest             uint32 = 832
+	encodingRepublishResponse            uint32 = 835
+	encodingDeleteSubscriptionsRequest   uint32 = 847
+	encodingDeleteSubscriptionsResponse  uint32 = 850
+)
+
+const (
+	securityPolicyNone  = "http://opcfoundation.org/UA/SecurityPolicy#None"
+	transportProfileTCP = "http://opcfoundation.org/UA-Profile/Transport/uatcp-uasc-uabinary"
+	namespaceURI        = "urn:studiolambda:immersim"
+	applicationURI      = "urn:studiolambda:immersim:server"
+	productURI          = "urn:studiolambda:immersim"
+	anonymousPolicyId   = "anonymous"
+)
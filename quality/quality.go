@@ -0,0 +1,106 @@
+package quality
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Quality uint32
+
+const (
+	Good                              Quality = 0x00000000
+	GoodLocalOverride                 Quality = 0x00D80000
+	Uncertain                         Quality = 0x40000000
+	UncertainLastUsableValue          Quality = 0x40900000
+	UncertainSensorNotAccurate        Quality = 0x40930000
+	UncertainEngineeringUnitsExceeded Quality = 0x40940000
+	UncertainInitialValue             Quality = 0x40920000
+	Bad                               Quality = 0x80000000
+	BadConfigurationError             Quality = 0x80890000
+	BadNotConnected                   Quality = 0x808A0000
+	BadDeviceFailure                  Quality = 0x808B0000
+	BadSensorFailure                  Quality = 0x808C0000
+	BadOutOfService                   Quality = 0x808D0000
+	BadCommunicationFailure           Quality = 0x80050000
+	BadWaitingForInitialData          Quality = 0x80320000
+)
+
+const severity Quality = 0xC0000000
+
+var ErrUnknownQuality = errors.New("unknown quality")
+
+var names = map[Quality]string{
+	Good:                              "good",
+	GoodLocalOverride:                 "good_local_override",
+	Uncertain:                         "uncertain",
+	UncertainLastUsableValue:          "uncertain_last_usable_value",
+	UncertainSensorNotAccurate:        "uncertain_sensor_not_accurate",
+	UncertainEngineeringUnitsExceeded: "uncertain_engineering_units_exceeded",
+	UncertainInitialValue:             "uncertain_initial_value",
+	Bad:                               "bad",
+	BadConfigurationError:             "bad_configuration_error",
+	BadNotConnected:                   "bad_not_connected",
+	BadDeviceFailure:                  "bad_device_failure",
+	BadSensorFailure:                  "bad_sensor_failure",
+	BadOutOfService:                   "bad_out_of_service",
+	BadCommunicationFailure:           "bad_communication_failure",
+	BadWaitingForInitialData:          "bad_waiting_for_initial_data",
+}
+
+func Parse(name string) (Quality, error) {
+	for quality, candidate := range names {
+		if candidate == name {
+			return quality, nil
+		}
+	}
+
+	return Bad, fmt.Errorf("%w: %s", ErrUnknownQuality, name)
+}
+
+func (quality Quality) Good() bool {
+	return quality&severity == Good
+}
+
+func (quality Quality) Uncertain() bool {
+	return quality&severity == Uncertain
+}
+
+func (quality Quality) Bad() bool {
+	return quality&severity == Bad
+}
+
+func Worst(qualities ...Quality) Quality {
+	worst := Good
+
+	for _, quality := range qualities {
+		if quality&severity > worst&severity {
+			worst = quality
+		}
+	}
+
+	return worst
+}
+
+func (quality Quality) String() string {
+	if name, ok := names[quality]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%08X", uint32(quality))
+}
+
+func (quality Quality) MarshalText() ([]byte, error) {
+	return []byte(quality.String()), nil
+}
+
+func (quality *Quality) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+
+	if err != nil {
+		return err
+	}
+
+	*quality = parsed
+
+	return nil
+}
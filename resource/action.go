@@ -1,10 +1,12 @@
 package resource
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
 	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
 	"github.com/studiolambda/immersim/storage"
 )
 
@@ -14,6 +16,7 @@ type Action struct {
 	events   *event.Events
 	current  bool
 	mutex    sync.RWMutex
+	stamp    stamp
 	callback func(storage *storage.Storage, events *event.Events) bool
 }
 
@@ -24,21 +27,31 @@ func NewAction(callback func(storage *storage.Storage, events *event.Events) boo
 		events:   nil,
 		current:  false,
 		mutex:    sync.RWMutex{},
+		stamp:    newStamp(),
 		callback: callback,
 	}
 }
 
-func (action *Action) Start(name string, storage *storage.Storage, events *event.Events) {
+func (action *Action) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
 	action.current = false
 	action.name = name
 	action.storage = storage
 	action.events = events
+	action.stamp.start(storage.Clock(), quality.Good)
+
+	return nil
 }
 
-func (action *Action) Stop() {
+func (action *Action) Stop(ctx context.Context) error {
+	action.mutex.Lock()
+	action.stamp.stop()
+	action.mutex.Unlock()
+
 	action.name = ""
 	action.storage = nil
 	action.events = nil
+
+	return nil
 }
 
 func (action *Action) Read() (any, error) {
@@ -48,16 +61,25 @@ func (action *Action) Read() (any, error) {
 	return action.current, nil
 }
 
+func (action *Action) Sample() (storage.Sample, error) {
+	action.mutex.RLock()
+	defer action.mutex.RUnlock()
+
+	return action.stamp.sample(action.current), nil
+}
+
 func (action *Action) Write(value any) error {
 	if val, ok := value.(bool); ok {
 		action.mutex.Lock()
 		action.current = val
+		action.stamp.changed(action.name, action.current)
 		action.mutex.Unlock()
 
 		if action.current {
 			if action.callback(action.storage, action.events) {
 				action.mutex.Lock()
 				action.current = false
+				action.stamp.changed(action.name, action.current)
 				action.mutex.Unlock()
 			}
 		}
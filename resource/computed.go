@@ -1,9 +1,11 @@
 package resource
 
 import (
+	"context"
 	"sync"
 
 	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
 	"github.com/studiolambda/immersim/storage"
 )
 
@@ -12,55 +14,109 @@ type Computed[T storage.Supported] struct {
 	name         string
 	storage      *storage.Storage
 	events       *event.Events
-	callback     func(name string, storage *storage.Storage) T
+	evaluate     func(name string, storage *storage.Storage) (T, error)
 	dependencies []string
+	resolved     []string
+	propagated   bool
 	mutex        sync.RWMutex
+	stamp        stamp
 	listener     chan any
 	waitGroup    sync.WaitGroup
 }
 
 func NewComputed[T storage.Supported](callback func(name string, storage *storage.Storage) T, dependencies []string) *Computed[T] {
+	return newComputed(func(name string, storage *storage.Storage) (T, error) {
+		return callback(name, storage), nil
+	}, dependencies)
+}
+
+func newComputed[T storage.Supported](evaluate func(name string, storage *storage.Storage) (T, error), dependencies []string) *Computed[T] {
 	return &Computed[T]{
 		name:         "",
 		storage:      nil,
 		events:       nil,
-		callback:     callback,
+		evaluate:     evaluate,
 		dependencies: dependencies,
+		resolved:     nil,
+		propagated:   false,
 		listener:     nil,
 		current:      *new(T),
 		mutex:        sync.RWMutex{},
+		stamp:        newStamp(),
 		waitGroup:    sync.WaitGroup{},
 	}
 }
 
-func (computed *Computed[T]) Start(name string, storage *storage.Storage, events *event.Events) {
+func (computed *Computed[T]) Dependencies() []string {
+	return computed.dependencies
+}
+
+func (computed *Computed[T]) Rewrite(rewrite func(reference string) string) {
+	for i, dependency := range computed.dependencies {
+		computed.dependencies[i] = rewrite(dependency)
+	}
+}
+
+func (computed *Computed[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
 	computed.name = name
 	computed.storage = storage
 	computed.events = events
+	computed.propagated = storage.Topological()
 	computed.listener = make(chan any, len(computed.dependencies))
-	computed.current = computed.callback(computed.name, computed.storage)
+	computed.resolved = make([]string, 0, len(computed.dependencies))
+
+	for _, dependency := range computed.dependencies {
+		computed.resolved = append(computed.resolved, storage.Resolve(name, dependency))
+	}
+
+	current, err := computed.evaluate(computed.name, computed.storage)
+
+	if err != nil {
+		computed.stamp.start(storage.Clock(), quality.Bad)
+	} else {
+		computed.current = current
+		computed.stamp.start(storage.Clock(), computed.inputs())
+	}
 
 	computed.waitGroup.Add(1)
 	go computed.loop()
 
-	for _, dependency := range computed.dependencies {
-		computed.events.Subscribe(event.Changed(dependency), computed.listener)
+	if computed.propagated {
+		return nil
+	}
+
+	for _, dependency := range computed.resolved {
+		computed.events.SubscribeWith(event.Changed(dependency), computed.listener, event.Coalesce, 1)
 	}
+
+	return nil
 }
 
-func (computed *Computed[T]) Stop() {
-	for _, dependency := range computed.dependencies {
-		computed.events.Unsubscribe(event.Changed(dependency), computed.listener)
+func (computed *Computed[T]) Stop(ctx context.Context) error {
+	if !computed.propagated {
+		for _, dependency := range computed.resolved {
+			computed.events.Unsubscribe(event.Changed(dependency), computed.listener)
+		}
 	}
 
 	close(computed.listener)
-	computed.waitGroup.Wait()
+
+	if err := wait(ctx, &computed.waitGroup); err != nil {
+		return err
+	}
+
+	computed.mutex.Lock()
+	computed.stamp.stop()
+	computed.mutex.Unlock()
 
 	computed.name = ""
 	computed.storage = nil
 	computed.events = nil
 	computed.listener = nil
+	computed.resolved = nil
 	computed.current = *new(T)
+
+	return nil
 }
 
 func (computed *Computed[T]) Read() (any, error) {
@@ -70,22 +126,60 @@ func (computed *Computed[T]) Read() (any, error) {
 	return computed.current, nil
 }
 
+func (computed *Computed[T]) Sample() (storage.Sample, error) {
+	computed.mutex.RLock()
+	defer computed.mutex.RUnlock()
+
+	return computed.stamp.sample(computed.current), nil
+}
+
 func (computed *Computed[T]) loop() {
 	defer computed.waitGroup.Done()
 
 	for range computed.listener {
-		computed.mutex.Lock()
-		new := computed.callback(computed.name, computed.storage)
-		hasChanged := computed.current != new
-		computed.current = new
-
-		if hasChanged {
-			computed.events.Emit(event.Changed(computed.name), event.ChangedPayload{
-				Resource: computed.name,
-				Value:    computed.current,
-			})
+		if payload, ok := computed.Recompute(); ok {
+			computed.events.Emit(event.Changed(computed.name), payload)
+		}
+	}
+}
+
+func (computed *Computed[T]) Recompute() (event.ChangedPayload, bool) {
+	computed.mutex.Lock()
+	defer computed.mutex.Unlock()
+
+	new, err := computed.evaluate(computed.name, computed.storage)
+
+	if err != nil {
+		if computed.stamp.mark(quality.UncertainLastUsableValue) {
+			return computed.stamp.payload(computed.name, computed.current), true
 		}
 
-		computed.mutex.Unlock()
+		return event.ChangedPayload{}, false
 	}
+
+	status := computed.inputs()
+
+	if computed.current == new && computed.stamp.quality == status {
+		return event.ChangedPayload{}, false
+	}
+
+	computed.current = new
+
+	return computed.stamp.record(computed.name, computed.current, status), true
+}
+
+func (computed *Computed[T]) inputs() quality.Quality {
+	status := quality.Good
+
+	for _, dependency := range computed.resolved {
+		sample, err := computed.storage.ReadSample(dependency)
+
+		if err != nil {
+			return quality.Bad
+		}
+
+		status = quality.Worst(status, sample.Quality)
+	}
+
+	return status
 }
@@ -0,0 +1,74 @@
+package resource_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func sum(dependencies ...string) *resource.Computed[int32] {
+	return resource.NewComputed(func(name string, store *storage.Storage) int32 {
+		var total int32
+
+		for _, dependency := range dependencies {
+			value, _ := store.Read(dependency)
+			total += value.(int32)
+		}
+
+		return total
+	}, dependencies)
+}
+
+func TestTopologicalWriteIsGlitchFree(t *testing.T) {
+	store := storage.NewStorage(map[string]storage.Resource{
+		"input":  resource.NewStatic[int32](1),
+		"double": sum("input", "input"),
+		"total":  sum("input", "double"),
+	})
+	store.SetPropagation(storage.PropagateTopological)
+
+	events := event.NewEvents(time.Second)
+	listener := make(chan any, 16)
+	events.SubscribeWith(event.Changed("total"), listener, event.Block, 16)
+
+	if err := store.Start(context.Background(), events, clock.NewManual(time.Time{}), 1); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	defer store.Stop(context.Background())
+
+	var totals []int32
+
+	for _, value := range []int32{2, 3} {
+		if err := store.Write("input", value); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		if total := read[int32](t, store, "total"); total != 3*value {
+			t.Fatalf("expected the write to propagate before returning, got %d", total)
+		}
+
+		select {
+		case payload := <-listener:
+			totals = append(totals, payload.(event.ChangedPayload).Value.(int32))
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the total to change")
+		}
+	}
+
+	select {
+	case payload := <-listener:
+		totals = append(totals, payload.(event.ChangedPayload).Value.(int32))
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if !slices.Equal(totals, []int32{6, 9}) {
+		t.Fatalf("expected one consistent total per write, got %v", totals)
+	}
+}
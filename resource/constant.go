@@ -1,7 +1,11 @@
 package resource
 
 import (
+	"context"
+	"sync"
+
 	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
 	"github.com/studiolambda/immersim/storage"
 )
 
@@ -9,6 +13,8 @@ type Constant[T storage.Supported] struct {
 	current T
 	name    string
 	events  *event.Events
+	stamp   stamp
+	mutex   sync.RWMutex
 }
 
 func NewConstant[T storage.Supported](value T) *Constant[T] {
@@ -16,6 +22,8 @@ func NewConstant[T storage.Supported](value T) *Constant[T] {
 		current: value,
 		name:    "",
 		events:  nil,
+		stamp:   newStamp(),
+		mutex:   sync.RWMutex{},
 	}
 }
 
@@ -23,12 +31,31 @@ func (static *Constant[T]) Read() (any, error) {
 	return static.current, nil
 }
 
-func (static *Constant[T]) Start(name string, storage *storage.Storage, events *event.Events) {
+func (static *Constant[T]) Sample() (storage.Sample, error) {
+	static.mutex.RLock()
+	defer static.mutex.RUnlock()
+
+	return static.stamp.sample(static.current), nil
+}
+
+func (static *Constant[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
 	static.name = name
 	static.events = events
+
+	static.mutex.Lock()
+	static.stamp.start(storage.Clock(), quality.Good)
+	static.mutex.Unlock()
+
+	return nil
 }
 
-func (static *Constant[T]) Stop() {
+func (static *Constant[T]) Stop(ctx context.Context) error {
+	static.mutex.Lock()
+	static.stamp.stop()
+	static.mutex.Unlock()
+
 	static.name = ""
 	static.events = nil
+
+	return nil
 }
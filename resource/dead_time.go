@@ -0,0 +1,36 @@
+package resource
+
+import (
+	"time"
+)
+
+type deadTime struct {
+	samples []float64
+	index   int
+}
+
+func newDeadTime(delay time.Duration, interval time.Duration, initial float64) *deadTime {
+	size := int((delay + interval - 1) / interval)
+	samples := make([]float64, size)
+
+	for i := range samples {
+		samples[i] = initial
+	}
+
+	return &deadTime{
+		samples: samples,
+		index:   0,
+	}
+}
+
+func (line *deadTime) shift(value float64) float64 {
+	if len(line.samples) == 0 {
+		return value
+	}
+
+	delayed := line.samples[line.index]
+	line.samples[line.index] = value
+	line.index = (line.index + 1) % len(line.samples)
+
+	return delayed
+}
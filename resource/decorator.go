@@ -0,0 +1,184 @@
+package resource
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type transform func(input float64, elapsed time.Duration, generator *rand.Rand) float64
+
+type Decorator[T storage.SupportedNumeric] struct {
+	name      string
+	kind      string
+	storage   *storage.Storage
+	events    *event.Events
+	source    string
+	factory   func() transform
+	check     func() error
+	transform transform
+	interval  time.Duration
+	input     float64
+	current   T
+	last      time.Time
+	generator *rand.Rand
+	mutex     sync.RWMutex
+	stamp     stamp
+	ticker    clock.Ticker
+	listener  chan any
+	wg        sync.WaitGroup
+}
+
+func newDecorator[T storage.SupportedNumeric](kind string, source string, interval time.Duration, factory func() transform) *Decorator[T] {
+	return &Decorator[T]{
+		name:      "",
+		kind:      kind,
+		storage:   nil,
+		events:    nil,
+		source:    source,
+		factory:   factory,
+		check:     nil,
+		transform: nil,
+		interval:  interval,
+		input:     0,
+		current:   *new(T),
+		last:      time.Time{},
+		generator: nil,
+		mutex:     sync.RWMutex{},
+		stamp:     newStamp(),
+		ticker:    nil,
+		listener:  nil,
+		wg:        sync.WaitGroup{},
+	}
+}
+
+func (decorator *Decorator[T]) loop() {
+	defer decorator.wg.Done()
+
+	for range decorator.listener {
+		input, err := readNumeric(decorator.storage, storage.Resolve(decorator.name, decorator.source))
+
+		decorator.mutex.Lock()
+
+		if err != nil {
+			decorator.stamp.signal(decorator.events, decorator.name, decorator.current, quality.UncertainLastUsableValue)
+			decorator.mutex.Unlock()
+
+			continue
+		}
+
+		decorator.input = input
+		decorator.update(decorator.storage.Clock().Now())
+		decorator.mutex.Unlock()
+	}
+}
+
+func (decorator *Decorator[T]) tick(now time.Time) {
+	decorator.mutex.Lock()
+	defer decorator.mutex.Unlock()
+
+	decorator.update(now)
+}
+
+func (decorator *Decorator[T]) update(now time.Time) {
+	elapsed := max(now.Sub(decorator.last), 0)
+	decorator.last = now
+
+	current := fromFloat[T](decorator.transform(decorator.input, elapsed, decorator.generator))
+
+	if current == decorator.current {
+		decorator.stamp.signal(decorator.events, decorator.name, decorator.current, quality.Good)
+		return
+	}
+
+	decorator.current = current
+	decorator.events.Emit(event.Changed(decorator.name), decorator.stamp.changed(decorator.name, decorator.current))
+}
+
+func (decorator *Decorator[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if decorator.check != nil {
+		if err := decorator.check(); err != nil {
+			return err
+		}
+	}
+
+	decorator.name = name
+	decorator.storage = storage
+	decorator.events = events
+	decorator.stamp.start(storage.Clock(), quality.Good)
+	decorator.listener = make(chan any, 1)
+	decorator.transform = decorator.factory()
+	decorator.generator = rand.New(storage.Source(name))
+	decorator.input, _ = readNumeric(storage, storage.Resolve(decorator.name, decorator.source))
+	decorator.last = storage.Clock().Now()
+	decorator.current = fromFloat[T](decorator.transform(decorator.input, 0, decorator.generator))
+
+	if decorator.interval > 0 {
+		decorator.ticker = storage.Clock().Every(decorator.interval, decorator.tick)
+	}
+
+	decorator.wg.Add(1)
+	go decorator.loop()
+
+	decorator.events.SubscribeWith(event.Changed(storage.Resolve(decorator.name, decorator.source)), decorator.listener, event.Coalesce, 1)
+
+	return nil
+}
+
+func (decorator *Decorator[T]) Stop(ctx context.Context) error {
+	decorator.events.Unsubscribe(event.Changed(storage.Resolve(decorator.name, decorator.source)), decorator.listener)
+	close(decorator.listener)
+
+	if decorator.ticker != nil {
+		decorator.ticker.Stop()
+	}
+
+	if err := wait(ctx, &decorator.wg); err != nil {
+		return err
+	}
+
+	decorator.mutex.Lock()
+	decorator.stamp.stop()
+	decorator.mutex.Unlock()
+
+	decorator.ticker = nil
+	decorator.transform = nil
+	decorator.generator = nil
+	decorator.name = ""
+	decorator.storage = nil
+	decorator.events = nil
+
+	return nil
+}
+
+func (decorator *Decorator[T]) Kind() string {
+	return decorator.kind
+}
+
+func (decorator *Decorator[T]) Dependencies() []string {
+	return []string{decorator.source}
+}
+
+func (decorator *Decorator[T]) Rewrite(rewrite func(reference string) string) {
+	decorator.source = rewrite(decorator.source)
+}
+
+func (decorator *Decorator[T]) Read() (any, error) {
+	decorator.mutex.RLock()
+	defer decorator.mutex.RUnlock()
+
+	return decorator.current, nil
+}
+
+func (decorator *Decorator[T]) Sample() (storage.Sample, error) {
+	decorator.mutex.RLock()
+	defer decorator.mutex.RUnlock()
+
+	return decorator.stamp.sample(decorator.current), nil
+}
@@ -0,0 +1,104 @@
+package resource_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func decorate(t *testing.T, input float32, decorator storage.Resource) float32 {
+	t.Helper()
+
+	store, _, _ := simulate(t, map[string]storage.Resource{
+		"input":     resource.NewStatic(input),
+		"decorated": decorator,
+	})
+
+	return read[float32](t, store, "decorated")
+}
+
+func TestQuantizerLevels(t *testing.T) {
+	tests := []struct {
+		input    float32
+		expected float32
+	}{
+		{input: 0, expected: 0},
+		{input: 1.4, expected: 1},
+		{input: 1.6, expected: 2},
+		{input: 3, expected: 3},
+		{input: 10, expected: 3},
+		{input: -5, expected: 0},
+	}
+
+	for _, test := range tests {
+		if output := decorate(t, test.input, resource.NewQuantizer[float32]("input", 2, 0, 3)); output != test.expected {
+			t.Fatalf("input %v: expected %v, got %v", test.input, test.expected, output)
+		}
+	}
+}
+
+func TestQuantizerFollowsSource(t *testing.T) {
+	store, _, _ := simulate(t, map[string]storage.Resource{
+		"input":     resource.NewStatic[float32](0),
+		"quantized": resource.NewQuantizer[float32]("input", 1, 0, 10),
+	})
+
+	store.Write("input", float32(6))
+	eventually(t, func() bool { return read[float32](t, store, "quantized") == 10 })
+
+	store.Write("input", float32(4))
+	eventually(t, func() bool { return read[float32](t, store, "quantized") == 0 })
+}
+
+func TestQuantizerRejectsInvalidParameters(t *testing.T) {
+	tests := []struct {
+		quantizer *resource.Decorator[float32]
+		err       error
+	}{
+		{quantizer: resource.NewQuantizer[float32]("input", 0, 0, 10), err: resource.ErrInvalidBits},
+		{quantizer: resource.NewQuantizer[float32]("input", 33, 0, 10), err: resource.ErrInvalidBits},
+		{quantizer: resource.NewQuantizer[float32]("input", 8, 10, 10), err: resource.ErrInvalidRange},
+		{quantizer: resource.NewQuantizer[float32]("input", 8, 10, 0), err: resource.ErrInvalidRange},
+		{quantizer: resource.NewQuantizer[float32]("input", 8, math.NaN(), 10), err: resource.ErrInvalidRange},
+	}
+
+	for _, test := range tests {
+		if err := start(test.quantizer); !errors.Is(err, test.err) {
+			t.Fatalf("expected %v, got %v", test.err, err)
+		}
+	}
+}
+
+func TestClamp(t *testing.T) {
+	tests := []struct {
+		input    float32
+		expected float32
+	}{
+		{input: -1, expected: 0},
+		{input: 5, expected: 5},
+		{input: 11, expected: 10},
+	}
+
+	for _, test := range tests {
+		if output := decorate(t, test.input, resource.NewClamp[float32]("input", 0, 10)); output != test.expected {
+			t.Fatalf("input %v: expected %v, got %v", test.input, test.expected, output)
+		}
+	}
+}
+
+func TestLinearDrift(t *testing.T) {
+	store, _, manual := simulate(t, map[string]storage.Resource{
+		"input":   resource.NewStatic[float32](5),
+		"drifted": resource.NewLinearDrift[float32]("input", 0.5, time.Second),
+	})
+
+	manual.Advance(4 * time.Second)
+
+	if output := read[float32](t, store, "drifted"); output != 7 {
+		t.Fatalf("expected the drift to add 2 over four seconds, got %v", output)
+	}
+}
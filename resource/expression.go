@@ -0,0 +1,114 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/expression"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type Expression[T storage.Supported] struct {
+	*Computed[T]
+	expression *expression.Expression
+	aliases    map[string]string
+}
+
+var ErrExpressionType = errors.New("expression type does not match resource type")
+
+func NewExpression[T storage.Supported](source string) (*Expression[T], error) {
+	parsed, err := expression.Parse(source)
+
+	if err != nil {
+		return nil, err
+	}
+
+	computed := &Expression[T]{
+		Computed:   nil,
+		expression: parsed,
+		aliases:    make(map[string]string),
+	}
+
+	computed.Computed = newComputed(computed.evaluate, parsed.References())
+
+	return computed, nil
+}
+
+func (computed *Expression[T]) Expression() *expression.Expression {
+	return computed.expression
+}
+
+func (computed *Expression[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if err := computed.Check(name, storage); err != nil {
+		return err
+	}
+
+	return computed.Computed.Start(ctx, name, storage, events)
+}
+
+func (computed *Expression[T]) Rewrite(rewrite func(reference string) string) {
+	for _, reference := range computed.expression.References() {
+		computed.aliases[reference] = rewrite(computed.reference(reference))
+	}
+
+	computed.Computed.Rewrite(rewrite)
+}
+
+func (computed *Expression[T]) Check(name string, storage *storage.Storage) error {
+	result, err := computed.expression.Check(func(reference string) (expression.Type, bool) {
+		value, err := storage.Read(computed.resolve(name, storage, reference))
+
+		if err != nil {
+			return expression.Invalid, false
+		}
+
+		kind, _ := expression.TypeOf(value)
+
+		return kind, true
+	})
+
+	if err != nil {
+		return err
+	}
+
+	target, _ := expression.TypeOf(*new(T))
+
+	if !expression.Assignable(result, target) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrExpressionType, target, result)
+	}
+
+	return nil
+}
+
+func (computed *Expression[T]) evaluate(name string, storage *storage.Storage) (T, error) {
+	value, err := computed.expression.Evaluate(func(reference string) (any, error) {
+		return storage.Read(computed.resolve(name, storage, reference))
+	})
+
+	if err != nil {
+		return *new(T), err
+	}
+
+	target, _ := expression.TypeOf(*new(T))
+	converted, ok := expression.Convert(value, target)
+
+	if !ok {
+		return *new(T), fmt.Errorf("%w: expected %s, got %T", ErrExpressionType, target, value)
+	}
+
+	return converted.(T), nil
+}
+
+func (computed *Expression[T]) reference(reference string) string {
+	if alias, ok := computed.aliases[reference]; ok {
+		return alias
+	}
+
+	return reference
+}
+
+func (computed *Expression[T]) resolve(name string, storage *storage.Storage, reference string) string {
+	return storage.Resolve(name, computed.reference(reference))
+}
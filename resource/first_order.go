@@ -0,0 +1,173 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type FirstOrder[T storage.SupportedNumeric] struct {
+	name         string
+	storage      *storage.Storage
+	events       *event.Events
+	input        string
+	gain         float64
+	timeConstant time.Duration
+	delay        time.Duration
+	interval     time.Duration
+	target       float64
+	output       float64
+	deadTime     *deadTime
+	mutex        sync.RWMutex
+	stamp        stamp
+	ticker       clock.Ticker
+	listener     chan any
+	wg           sync.WaitGroup
+}
+
+func NewFirstOrder[T storage.SupportedNumeric](input string, gain float64, timeConstant time.Duration, delay time.Duration, interval time.Duration) *FirstOrder[T] {
+	return &FirstOrder[T]{
+		name:         "",
+		storage:      nil,
+		events:       nil,
+		input:        input,
+		gain:         gain,
+		timeConstant: timeConstant,
+		delay:        delay,
+		interval:     interval,
+		target:       0,
+		output:       0,
+		deadTime:     nil,
+		mutex:        sync.RWMutex{},
+		stamp:        newStamp(),
+		ticker:       nil,
+		listener:     nil,
+		wg:           sync.WaitGroup{},
+	}
+}
+
+func (lag *FirstOrder[T]) loop() {
+	defer lag.wg.Done()
+
+	for range lag.listener {
+		lag.updateTarget()
+	}
+}
+
+func (lag *FirstOrder[T]) updateTarget() {
+	target, err := readNumeric(lag.storage, storage.Resolve(lag.name, lag.input))
+
+	lag.mutex.Lock()
+	defer lag.mutex.Unlock()
+
+	if err != nil {
+		lag.stamp.signal(lag.events, lag.name, fromFloat[T](lag.output), quality.UncertainLastUsableValue)
+		return
+	}
+
+	lag.target = target
+	lag.stamp.signal(lag.events, lag.name, fromFloat[T](lag.output), quality.Good)
+}
+
+func (lag *FirstOrder[T]) tick(now time.Time) {
+	lag.mutex.Lock()
+	defer lag.mutex.Unlock()
+
+	input := lag.deadTime.shift(lag.target)
+	previous := fromFloat[T](lag.output)
+	factor := 1 - math.Exp(-lag.interval.Seconds()/lag.timeConstant.Seconds())
+
+	lag.output += (lag.gain*input - lag.output) * factor
+
+	if current := fromFloat[T](lag.output); current != previous {
+		lag.events.Emit(event.Changed(lag.name), lag.stamp.changed(lag.name, current))
+	}
+}
+
+func (lag *FirstOrder[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if err := checkInterval(lag.interval); err != nil {
+		return err
+	}
+
+	if err := checkDelay(lag.delay); err != nil {
+		return err
+	}
+
+	if lag.timeConstant <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidTimeConstant, lag.timeConstant)
+	}
+
+	lag.name = name
+	lag.storage = storage
+	lag.events = events
+	lag.stamp.start(storage.Clock(), quality.Good)
+	lag.listener = make(chan any, 1)
+
+	lag.updateTarget()
+	lag.output = lag.gain * lag.target
+	lag.deadTime = newDeadTime(lag.delay, lag.interval, lag.target)
+	lag.ticker = storage.Clock().Every(lag.interval, lag.tick)
+
+	lag.wg.Add(1)
+	go lag.loop()
+
+	lag.events.SubscribeWith(event.Changed(storage.Resolve(lag.name, lag.input)), lag.listener, event.Coalesce, 1)
+
+	return nil
+}
+
+func (lag *FirstOrder[T]) Stop(ctx context.Context) error {
+	lag.events.Unsubscribe(event.Changed(storage.Resolve(lag.name, lag.input)), lag.listener)
+	close(lag.listener)
+
+	lag.ticker.Stop()
+
+	if err := wait(ctx, &lag.wg); err != nil {
+		return err
+	}
+
+	lag.mutex.Lock()
+	lag.stamp.stop()
+	lag.mutex.Unlock()
+
+	lag.ticker = nil
+	lag.deadTime = nil
+	lag.name = ""
+	lag.storage = nil
+	lag.events = nil
+
+	return nil
+}
+
+func (lag *FirstOrder[T]) Dependencies() []string {
+	return []string{lag.input}
+}
+
+func (lag *FirstOrder[T]) Rewrite(rewrite func(reference string) string) {
+	lag.input = rewrite(lag.input)
+}
+
+func (lag *FirstOrder[T]) Delayed() bool {
+	return true
+}
+
+func (lag *FirstOrder[T]) Read() (any, error) {
+	lag.mutex.RLock()
+	defer lag.mutex.RUnlock()
+
+	return fromFloat[T](lag.output), nil
+}
+
+func (lag *FirstOrder[T]) Sample() (storage.Sample, error) {
+	lag.mutex.RLock()
+	defer lag.mutex.RUnlock()
+
+	return lag.stamp.sample(fromFloat[T](lag.output)), nil
+}
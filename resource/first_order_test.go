@@ -0,0 +1,87 @@
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func step(t *testing.T, store *storage.Storage, manual *clock.Manual, interval time.Duration, name string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+
+	for read[float32](t, store, name) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the step never reached the output")
+		}
+
+		manual.Advance(interval)
+	}
+}
+
+func near(t *testing.T, label string, value float64, expected float64, tolerance float64) {
+	t.Helper()
+
+	if math.Abs(value-expected) > tolerance {
+		t.Fatalf("%s: expected %g ± %g, got %g", label, expected, tolerance, value)
+	}
+}
+
+func TestFirstOrderStepResponse(t *testing.T) {
+	store, _, manual := simulate(t, map[string]storage.Resource{
+		"input": resource.NewStatic[float32](0),
+		"lag":   resource.NewFirstOrder[float32]("input", 2, time.Second, 0, 100*time.Millisecond),
+	})
+
+	store.Write("input", float32(1))
+	step(t, store, manual, 100*time.Millisecond, "lag")
+
+	near(t, "first tick", float64(read[float32](t, store, "lag")), 2*(1-math.Exp(-0.1)), 1e-5)
+
+	manual.Advance(900 * time.Millisecond)
+	near(t, "one time constant", float64(read[float32](t, store, "lag")), 2*(1-math.Exp(-1)), 1e-5)
+
+	manual.Advance(9 * time.Second)
+	near(t, "steady state", float64(read[float32](t, store, "lag")), 2, 1e-3)
+}
+
+func TestFirstOrderRejectsInvalidParameters(t *testing.T) {
+	tests := []struct {
+		lag *resource.FirstOrder[float32]
+		err error
+	}{
+		{lag: resource.NewFirstOrder[float32]("input", 1, 0, 0, time.Second), err: resource.ErrInvalidTimeConstant},
+		{lag: resource.NewFirstOrder[float32]("input", 1, -time.Second, 0, time.Second), err: resource.ErrInvalidTimeConstant},
+		{lag: resource.NewFirstOrder[float32]("input", 1, time.Second, -time.Second, time.Second), err: resource.ErrInvalidDelay},
+		{lag: resource.NewFirstOrder[float32]("input", 1, time.Second, 0, 0), err: resource.ErrInvalidInterval},
+	}
+
+	for _, test := range tests {
+		if err := start(test.lag); !errors.Is(err, test.err) {
+			t.Fatalf("expected %v, got %v", test.err, err)
+		}
+	}
+}
+
+func start(tested storage.Resource) error {
+	store := storage.NewStorage(map[string]storage.Resource{
+		"input":  resource.NewStatic[float32](0),
+		"tested": tested,
+	})
+
+	err := store.Start(context.Background(), event.NewEvents(time.Second), clock.NewManual(time.Time{}), 1)
+
+	if err == nil {
+		store.Stop(context.Background())
+	}
+
+	return err
+}
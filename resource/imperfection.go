@@ -0,0 +1,120 @@
+package resource
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/studiolambda/immersim/storage"
+)
+
+func NewWhiteNoise[T storage.SupportedNumeric](source string, deviation float64, interval time.Duration) *Decorator[T] {
+	return newDecorator[T]("white_noise", source, interval, func() transform {
+		return func(input float64, elapsed time.Duration, generator *rand.Rand) float64 {
+			return input + deviation*generator.NormFloat64()
+		}
+	})
+}
+
+func NewPinkNoise[T storage.SupportedNumeric](source string, amplitude float64, interval time.Duration) *Decorator[T] {
+	return newDecorator[T]("pink_noise", source, interval, func() transform {
+		var b0, b1, b2 float64
+
+		return func(input float64, elapsed time.Duration, generator *rand.Rand) float64 {
+			white := generator.NormFloat64()
+			b0 = 0.99765*b0 + white*0.0990460
+			b1 = 0.96300*b1 + white*0.2965164
+			b2 = 0.57000*b2 + white*1.0526913
+
+			return input + amplitude*0.25*(b0+b1+b2+white*0.1848)
+		}
+	})
+}
+
+func NewBrownNoise[T storage.SupportedNumeric](source string, amplitude float64, interval time.Duration) *Decorator[T] {
+	return newDecorator[T]("brown_noise", source, interval, func() transform {
+		var brown float64
+
+		return func(input float64, elapsed time.Duration, generator *rand.Rand) float64 {
+			brown = (brown + 0.02*generator.NormFloat64()) / 1.02
+
+			return input + amplitude*3.5*brown
+		}
+	})
+}
+
+func NewLinearDrift[T storage.SupportedNumeric](source string, rate float64, interval time.Duration) *Decorator[T] {
+	return newDecorator[T]("linear_drift", source, interval, func() transform {
+		var offset float64
+
+		return func(input float64, elapsed time.Duration, generator *rand.Rand) float64 {
+			offset += rate * elapsed.Seconds()
+
+			return input + offset
+		}
+	})
+}
+
+func NewRandomWalk[T storage.SupportedNumeric](source string, deviation float64, interval time.Duration) *Decorator[T] {
+	return newDecorator[T]("random_walk", source, interval, func() transform {
+		var offset float64
+
+		return func(input float64, elapsed time.Duration, generator *rand.Rand) float64 {
+			offset += deviation * math.Sqrt(elapsed.Seconds()) * generator.NormFloat64()
+
+			return input + offset
+		}
+	})
+}
+
+func NewQuantizer[T storage.SupportedNumeric](source string, bits int, minimum float64, maximum float64) *Decorator[T] {
+	levels := math.Exp2(float64(bits)) - 1
+	resolution := (maximum - minimum) / levels
+
+	decorator := newDecorator[T]("quantize", source, 0, func() transform {
+		return func(input float64, elapsed time.Duration, generator *rand.Rand) float64 {
+			clamped := min(max(input, minimum), maximum)
+
+			return minimum + math.Round((clamped-minimum)/resolution)*resolution
+		}
+	})
+
+	decorator.check = func() error {
+		if bits < 1 || bits > 32 {
+			return fmt.Errorf("%w: %d", ErrInvalidBits, bits)
+		}
+
+		if !(minimum < maximum) {
+			return fmt.Errorf("%w: %g >= %g", ErrInvalidRange, minimum, maximum)
+		}
+
+		return nil
+	}
+
+	return decorator
+}
+
+func NewClamp[T storage.SupportedNumeric](source string, minimum float64, maximum float64) *Decorator[T] {
+	return newDecorator[T]("clamp", source, 0, func() transform {
+		return func(input float64, elapsed time.Duration, generator *rand.Rand) float64 {
+			return min(max(input, minimum), maximum)
+		}
+	})
+}
+
+func NewDeadband[T storage.SupportedNumeric](source string, band float64) *Decorator[T] {
+	return newDecorator[T]("deadband", source, 0, func() transform {
+		primed := false
+		last := 0.0
+
+		return func(input float64, elapsed time.Duration, generator *rand.Rand) float64 {
+			if !primed || math.Abs(input-last) >= band {
+				primed = true
+				last = input
+			}
+
+			return last
+		}
+	})
+}
@@ -1,10 +1,13 @@
 package resource
 
 import (
+	"context"
 	"sync"
 	"time"
 
+	"github.com/studiolambda/immersim/clock"
 	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
 	"github.com/studiolambda/immersim/storage"
 )
 
@@ -15,10 +18,12 @@ type Increment[T storage.SupportedNumeric] struct {
 	current  T
 	initial  T
 	mutex    sync.RWMutex
+	stamp    stamp
 	quit     chan struct{}
 	wg       sync.WaitGroup
 	step     T
 	interval time.Duration
+	ticker   clock.Ticker
 	reset    chan any
 	pause    chan any
 	resume   chan any
@@ -32,10 +37,12 @@ func NewIncrement[T storage.SupportedNumeric](initial T, step T, interval time.D
 		current:  initial,
 		initial:  initial,
 		mutex:    sync.RWMutex{},
+		stamp:    newStamp(),
 		quit:     nil,
 		wg:       sync.WaitGroup{},
 		step:     step,
 		interval: interval,
+		ticker:   nil,
 		reset:    nil,
 		pause:    nil,
 		resume:   nil,
@@ -45,32 +52,18 @@ func NewIncrement[T storage.SupportedNumeric](initial T, step T, interval time.D
 func (increment *Increment[T]) loop() {
 	defer increment.wg.Done()
 
-	ticker := time.NewTicker(increment.interval)
-	defer ticker.Stop()
-
 	for {
 		select {
 		case <-increment.pause:
-			ticker.Stop()
+			increment.ticker.Stop()
 		case <-increment.resume:
-			ticker.Reset(increment.interval)
+			increment.ticker.Reset(increment.interval)
 		case <-increment.reset:
 			increment.mutex.Lock()
 
 			increment.current = increment.initial
-			increment.events.Emit(event.Changed(increment.name), event.ChangedPayload{
-				Resource: increment.name,
-				Value:    increment.current,
-			})
+			increment.events.Emit(event.Changed(increment.name), increment.stamp.changed(increment.name, increment.current))
 
-			increment.mutex.Unlock()
-		case <-ticker.C:
-			increment.mutex.Lock()
-			increment.current += increment.step
-			increment.events.Emit(event.Changed(increment.name), event.ChangedPayload{
-				Resource: increment.name,
-				Value:    increment.current,
-			})
 			increment.mutex.Unlock()
 		case <-increment.quit:
 			return
@@ -78,43 +71,74 @@ func (increment *Increment[T]) loop() {
 	}
 }
 
-func (increment *Increment[T]) Start(name string, storage *storage.Storage, events *event.Events) {
+func (increment *Increment[T]) tick(now time.Time) {
+	increment.mutex.Lock()
+	increment.current += increment.step
+	increment.events.Emit(event.Changed(increment.name), increment.stamp.changed(increment.name, increment.current))
+	increment.mutex.Unlock()
+}
+
+func (increment *Increment[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if err := checkInterval(increment.interval); err != nil {
+		return err
+	}
+
 	increment.name = name
 	increment.storage = storage
 	increment.events = events
+	increment.stamp.start(storage.Clock(), quality.Good)
 	increment.quit = make(chan struct{})
 	increment.reset = make(chan any)
 	increment.pause = make(chan any)
 	increment.resume = make(chan any)
 
+	increment.ticker = storage.Clock().Every(increment.interval, increment.tick)
+
 	increment.wg.Add(1)
 	go increment.loop()
 
 	increment.events.Subscribe(event.Action(increment.name, "reset"), increment.reset)
 	increment.events.Subscribe(event.Action(increment.name, "resume"), increment.resume)
 	increment.events.Subscribe(event.Action(increment.name, "pause"), increment.pause)
+
+	return nil
 }
 
-func (increment *Increment[T]) Stop() {
+func (increment *Increment[T]) Stop(ctx context.Context) error {
 	increment.events.Unsubscribe(event.Action(increment.name, "reset"), increment.reset)
 	increment.events.Unsubscribe(event.Action(increment.name, "resume"), increment.resume)
 	increment.events.Unsubscribe(event.Action(increment.name, "pause"), increment.pause)
 
 	close(increment.quit)
 
-	increment.wg.Wait()
+	increment.ticker.Stop()
+
+	if err := wait(ctx, &increment.wg); err != nil {
+		return err
+	}
 
 	close(increment.reset)
 	close(increment.resume)
 	close(increment.pause)
 
+	increment.mutex.Lock()
+	increment.stamp.stop()
+	increment.mutex.Unlock()
+
 	increment.name = ""
 	increment.storage = nil
 	increment.events = nil
 	increment.quit = nil
+	increment.ticker = nil
 	increment.reset = nil
 	increment.resume = nil
 	increment.pause = nil
+
+	return nil
+}
+
+func (increment *Increment[T]) Actions() []string {
+	return []string{"reset", "pause", "resume"}
 }
 
 func (increment *Increment[T]) Read() (any, error) {
@@ -123,3 +147,10 @@ func (increment *Increment[T]) Read() (any, error) {
 
 	return increment.current, nil
 }
+
+func (increment *Increment[T]) Sample() (storage.Sample, error) {
+	increment.mutex.RLock()
+	defer increment.mutex.RUnlock()
+
+	return increment.stamp.sample(increment.current), nil
+}
@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidInterval     = errors.New("interval must be positive")
+	ErrInvalidDelay        = errors.New("delay must not be negative")
+	ErrInvalidTimeConstant = errors.New("time constant must be positive")
+	ErrInvalidFrequency    = errors.New("frequency must be positive")
+	ErrInvalidDamping      = errors.New("damping must be positive")
+	ErrInvalidAmplitude    = errors.New("amplitude must be finite and not negative")
+	ErrInvalidBits         = errors.New("bits must be between 1 and 32")
+	ErrInvalidRange        = errors.New("minimum must be lower than maximum")
+)
+
+func checkInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidInterval, interval)
+	}
+
+	return nil
+}
+
+func checkDelay(delay time.Duration) error {
+	if delay < 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidDelay, delay)
+	}
+
+	return nil
+}
+
+func checkPositive(value float64, err error) error {
+	if !(value > 0) {
+		return fmt.Errorf("%w: %g", err, value)
+	}
+
+	return nil
+}
+
+func checkAmplitude(amplitude float64) error {
+	if !(amplitude >= 0) || math.IsInf(amplitude, 1) {
+		return fmt.Errorf("%w: %g", ErrInvalidAmplitude, amplitude)
+	}
+
+	return nil
+}
+
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,17 +1,21 @@
 package resource
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/studiolambda/immersim/clock"
 	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
 	"github.com/studiolambda/immersim/storage"
 )
 
 type LinearFeedback[T storage.SupportedNumeric] struct {
 	current      T
+	target       T
 	step         T
 	events       *event.Events
 	storage      *storage.Storage
@@ -19,7 +23,9 @@ type LinearFeedback[T storage.SupportedNumeric] struct {
 	stepInterval time.Duration
 	setpoint     string
 	mutex        sync.RWMutex
+	stamp        stamp
 	listener     chan any
+	ticker       clock.Ticker
 	wg           sync.WaitGroup
 }
 
@@ -30,6 +36,7 @@ var (
 func NewLinearFeedback[T storage.SupportedNumeric](step T, stepInterval time.Duration, setpoint string) *LinearFeedback[T] {
 	return &LinearFeedback[T]{
 		current:      *new(T),
+		target:       *new(T),
 		events:       nil,
 		storage:      nil,
 		name:         "",
@@ -37,7 +44,9 @@ func NewLinearFeedback[T storage.SupportedNumeric](step T, stepInterval time.Dur
 		stepInterval: stepInterval,
 		setpoint:     setpoint,
 		mutex:        sync.RWMutex{},
+		stamp:        newStamp(),
 		listener:     nil,
+		ticker:       nil,
 		wg:           sync.WaitGroup{},
 	}
 }
@@ -45,53 +54,51 @@ func NewLinearFeedback[T storage.SupportedNumeric](step T, stepInterval time.Dur
 func (feedback *LinearFeedback[T]) loop() {
 	defer feedback.wg.Done()
 
-	ticker := time.NewTicker(feedback.stepInterval)
-	defer ticker.Stop()
+	for range feedback.listener {
+		feedback.updateTarget()
+	}
+}
 
-	target, _ := feedback.readSetpoint()
+func (feedback *LinearFeedback[T]) updateTarget() {
+	target, err := feedback.readSetpoint()
 
-	for {
-		select {
-		case _, ok := <-feedback.listener:
-			if !ok {
-				return
-			}
+	feedback.mutex.Lock()
+	defer feedback.mutex.Unlock()
 
-			target, _ = feedback.readSetpoint()
-		case <-ticker.C:
-			feedback.mutex.Lock()
+	if err != nil {
+		feedback.stamp.signal(feedback.events, feedback.name, feedback.current, quality.UncertainLastUsableValue)
+		return
+	}
 
-			if feedback.current < target {
-				feedback.current += feedback.step
+	feedback.target = target
+	feedback.stamp.signal(feedback.events, feedback.name, feedback.current, quality.Good)
+}
 
-				if feedback.current > target {
-					feedback.current = target
-				}
+func (feedback *LinearFeedback[T]) tick(now time.Time) {
+	feedback.mutex.Lock()
+	defer feedback.mutex.Unlock()
 
-				feedback.events.Emit(event.Changed(feedback.name), event.ChangedPayload{
-					Resource: feedback.name,
-					Value:    feedback.current,
-				})
-			} else if feedback.current > target {
-				feedback.current -= feedback.step
+	if feedback.current < feedback.target {
+		feedback.current += feedback.step
 
-				if feedback.current < target {
-					feedback.current = target
-				}
+		if feedback.current > feedback.target {
+			feedback.current = feedback.target
+		}
 
-				feedback.events.Emit(event.Changed(feedback.name), event.ChangedPayload{
-					Resource: feedback.name,
-					Value:    feedback.current,
-				})
-			}
+		feedback.events.Emit(event.Changed(feedback.name), feedback.stamp.changed(feedback.name, feedback.current))
+	} else if feedback.current > feedback.target {
+		feedback.current -= feedback.step
 
-			feedback.mutex.Unlock()
+		if feedback.current < feedback.target {
+			feedback.current = feedback.target
 		}
+
+		feedback.events.Emit(event.Changed(feedback.name), feedback.stamp.changed(feedback.name, feedback.current))
 	}
 }
 
 func (feedback *LinearFeedback[T]) readSetpoint() (T, error) {
-	value, err := feedback.storage.Read(feedback.setpoint)
+	value, err := feedback.storage.Read(storage.Resolve(feedback.name, feedback.setpoint))
 
 	if err != nil {
 		return *new(T), err
@@ -117,27 +124,60 @@ func (feedback *LinearFeedback[T]) readSetpoint() (T, error) {
 	return *new(T), fmt.Errorf("%w: %T", ErrNotNumeric, value)
 }
 
-func (feedback *LinearFeedback[T]) Start(name string, storage *storage.Storage, events *event.Events) {
+func (feedback *LinearFeedback[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if err := checkInterval(feedback.stepInterval); err != nil {
+		return err
+	}
+
 	feedback.name = name
 	feedback.storage = storage
 	feedback.events = events
+	feedback.stamp.start(storage.Clock(), quality.Good)
 	feedback.listener = make(chan any, 1)
 
+	feedback.updateTarget()
+	feedback.ticker = storage.Clock().Every(feedback.stepInterval, feedback.tick)
+
 	feedback.wg.Add(1)
 	go feedback.loop()
 
-	feedback.events.Subscribe(event.Changed(feedback.setpoint), feedback.listener)
+	feedback.events.SubscribeWith(event.Changed(storage.Resolve(feedback.name, feedback.setpoint)), feedback.listener, event.Coalesce, 1)
+
+	return nil
 }
 
-func (feedback *LinearFeedback[T]) Stop() {
-	feedback.events.Unsubscribe(event.Changed(feedback.setpoint), feedback.listener)
+func (feedback *LinearFeedback[T]) Stop(ctx context.Context) error {
+	feedback.events.Unsubscribe(event.Changed(storage.Resolve(feedback.name, feedback.setpoint)), feedback.listener)
 	close(feedback.listener)
 
-	feedback.wg.Wait()
+	feedback.ticker.Stop()
+
+	if err := wait(ctx, &feedback.wg); err != nil {
+		return err
+	}
+
+	feedback.mutex.Lock()
+	feedback.stamp.stop()
+	feedback.mutex.Unlock()
 
+	feedback.ticker = nil
 	feedback.name = ""
 	feedback.storage = nil
 	feedback.events = nil
+
+	return nil
+}
+
+func (feedback *LinearFeedback[T]) Dependencies() []string {
+	return []string{feedback.setpoint}
+}
+
+func (feedback *LinearFeedback[T]) Rewrite(rewrite func(reference string) string) {
+	feedback.setpoint = rewrite(feedback.setpoint)
+}
+
+func (feedback *LinearFeedback[T]) Delayed() bool {
+	return true
 }
 
 func (feedback *LinearFeedback[T]) Read() (any, error) {
@@ -146,3 +186,10 @@ func (feedback *LinearFeedback[T]) Read() (any, error) {
 
 	return feedback.current, nil
 }
+
+func (feedback *LinearFeedback[T]) Sample() (storage.Sample, error) {
+	feedback.mutex.RLock()
+	defer feedback.mutex.RUnlock()
+
+	return feedback.stamp.sample(feedback.current), nil
+}
@@ -0,0 +1,35 @@
+package resource
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/studiolambda/immersim/storage"
+)
+
+func readNumeric(storage *storage.Storage, name string) (float64, error) {
+	value, err := storage.Read(name)
+
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := value.(type) {
+	case int32:
+		return float64(v), nil
+	case float32:
+		return float64(v), nil
+	}
+
+	return 0, fmt.Errorf("%w: %T", ErrNotNumeric, value)
+}
+
+func fromFloat[T storage.SupportedNumeric](value float64) T {
+	var zero T
+
+	if _, ok := any(zero).(int32); ok {
+		return T(math.Round(value))
+	}
+
+	return T(value)
+}
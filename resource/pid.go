@@ -0,0 +1,295 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type PID[T storage.SupportedNumeric] struct {
+	name            string
+	storage         *storage.Storage
+	events          *event.Events
+	processVariable string
+	setpoint        string
+	kp              float64
+	ki              float64
+	kd              float64
+	interval        time.Duration
+	minimum         float64
+	maximum         float64
+	output          float64
+	integral        float64
+	previous        float64
+	primed          bool
+	automatic       bool
+	transfer        bool
+	last            time.Time
+	mutex           sync.RWMutex
+	stamp           stamp
+	ticker          clock.Ticker
+	quit            chan struct{}
+	wg              sync.WaitGroup
+	auto            chan any
+	manual          chan any
+	reset           chan any
+}
+
+var (
+	ErrAutomaticMode = errors.New("output is only writable in manual mode")
+)
+
+func NewPID[T storage.SupportedNumeric](
+	processVariable string,
+	setpoint string,
+	kp float64,
+	ki float64,
+	kd float64,
+	interval time.Duration,
+	minimum T,
+	maximum T,
+) *PID[T] {
+	return &PID[T]{
+		name:            "",
+		storage:         nil,
+		events:          nil,
+		processVariable: processVariable,
+		setpoint:        setpoint,
+		kp:              kp,
+		ki:              ki,
+		kd:              kd,
+		interval:        interval,
+		minimum:         float64(minimum),
+		maximum:         float64(maximum),
+		output:          0,
+		integral:        0,
+		previous:        0,
+		primed:          false,
+		automatic:       true,
+		transfer:        false,
+		last:            time.Time{},
+		mutex:           sync.RWMutex{},
+		stamp:           newStamp(),
+		ticker:          nil,
+		quit:            nil,
+		wg:              sync.WaitGroup{},
+		auto:            nil,
+		manual:          nil,
+		reset:           nil,
+	}
+}
+
+func (pid *PID[T]) loop() {
+	defer pid.wg.Done()
+
+	for {
+		select {
+		case <-pid.auto:
+			pid.mutex.Lock()
+
+			if !pid.automatic {
+				pid.automatic = true
+				pid.transfer = true
+			}
+
+			pid.mutex.Unlock()
+		case <-pid.manual:
+			pid.mutex.Lock()
+			pid.automatic = false
+			pid.mutex.Unlock()
+		case <-pid.reset:
+			pid.mutex.Lock()
+
+			pid.integral = 0
+			pid.primed = false
+			pid.last = time.Time{}
+			pid.output = pid.clamp(0)
+			pid.emit()
+
+			pid.mutex.Unlock()
+		case <-pid.quit:
+			return
+		}
+	}
+}
+
+func (pid *PID[T]) tick(now time.Time) {
+	processVariable, pvErr := readNumeric(pid.storage, storage.Resolve(pid.name, pid.processVariable))
+	setpoint, spErr := readNumeric(pid.storage, storage.Resolve(pid.name, pid.setpoint))
+
+	pid.mutex.Lock()
+	defer pid.mutex.Unlock()
+
+	if pvErr != nil || spErr != nil {
+		pid.stamp.signal(pid.events, pid.name, pid.current(), quality.UncertainLastUsableValue)
+		return
+	}
+
+	elapsed := pid.interval.Seconds()
+
+	if !pid.last.IsZero() && now.After(pid.last) {
+		elapsed = now.Sub(pid.last).Seconds()
+	}
+
+	derivative := 0.0
+
+	if pid.primed {
+		derivative = -pid.kd * (processVariable - pid.previous) / elapsed
+	}
+
+	pid.previous = processVariable
+	pid.primed = true
+	pid.last = now
+
+	if !pid.automatic {
+		pid.stamp.signal(pid.events, pid.name, pid.current(), quality.Good)
+		return
+	}
+
+	deviation := setpoint - processVariable
+
+	if pid.transfer {
+		pid.transfer = false
+		pid.integral = pid.output - pid.kp*deviation - derivative
+	} else {
+		pid.integral = pid.clamp(pid.integral + pid.ki*deviation*elapsed)
+	}
+
+	pid.output = pid.clamp(pid.kp*deviation + pid.integral + derivative)
+	pid.emit()
+}
+
+func (pid *PID[T]) clamp(value float64) float64 {
+	return min(max(value, pid.minimum), pid.maximum)
+}
+
+func (pid *PID[T]) current() T {
+	return fromFloat[T](pid.output)
+}
+
+func (pid *PID[T]) emit() {
+	pid.events.Emit(event.Changed(pid.name), pid.stamp.changed(pid.name, pid.current()))
+}
+
+func (pid *PID[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if err := checkInterval(pid.interval); err != nil {
+		return err
+	}
+
+	pid.name = name
+	pid.storage = storage
+	pid.events = events
+	pid.stamp.start(storage.Clock(), quality.Good)
+	pid.quit = make(chan struct{})
+	pid.auto = make(chan any)
+	pid.manual = make(chan any)
+	pid.reset = make(chan any)
+	pid.ticker = storage.Clock().Every(pid.interval, pid.tick)
+
+	pid.wg.Add(1)
+	go pid.loop()
+
+	pid.events.Subscribe(event.Action(pid.name, "auto"), pid.auto)
+	pid.events.Subscribe(event.Action(pid.name, "manual"), pid.manual)
+	pid.events.Subscribe(event.Action(pid.name, "reset"), pid.reset)
+
+	return nil
+}
+
+func (pid *PID[T]) Stop(ctx context.Context) error {
+	pid.events.Unsubscribe(event.Action(pid.name, "auto"), pid.auto)
+	pid.events.Unsubscribe(event.Action(pid.name, "manual"), pid.manual)
+	pid.events.Unsubscribe(event.Action(pid.name, "reset"), pid.reset)
+
+	close(pid.quit)
+
+	pid.ticker.Stop()
+
+	if err := wait(ctx, &pid.wg); err != nil {
+		return err
+	}
+
+	close(pid.auto)
+	close(pid.manual)
+	close(pid.reset)
+
+	pid.mutex.Lock()
+	pid.stamp.stop()
+	pid.mutex.Unlock()
+
+	pid.name = ""
+	pid.storage = nil
+	pid.events = nil
+	pid.ticker = nil
+	pid.quit = nil
+	pid.auto = nil
+	pid.manual = nil
+	pid.reset = nil
+
+	return nil
+}
+
+func (pid *PID[T]) Actions() []string {
+	return []string{"auto", "manual", "reset"}
+}
+
+func (pid *PID[T]) Dependencies() []string {
+	return []string{pid.processVariable, pid.setpoint}
+}
+
+func (pid *PID[T]) Rewrite(rewrite func(reference string) string) {
+	pid.processVariable = rewrite(pid.processVariable)
+	pid.setpoint = rewrite(pid.setpoint)
+}
+
+func (pid *PID[T]) Delayed() bool {
+	return true
+}
+
+func (pid *PID[T]) Automatic() bool {
+	pid.mutex.RLock()
+	defer pid.mutex.RUnlock()
+
+	return pid.automatic
+}
+
+func (pid *PID[T]) Read() (any, error) {
+	pid.mutex.RLock()
+	defer pid.mutex.RUnlock()
+
+	return pid.current(), nil
+}
+
+func (pid *PID[T]) Sample() (storage.Sample, error) {
+	pid.mutex.RLock()
+	defer pid.mutex.RUnlock()
+
+	return pid.stamp.sample(pid.current()), nil
+}
+
+func (pid *PID[T]) Write(value any) error {
+	pid.mutex.Lock()
+	defer pid.mutex.Unlock()
+
+	output, ok := value.(T)
+
+	if !ok {
+		return fmt.Errorf("%w: expected %T, go %T", ErrMissmatchedTypes, *new(T), value)
+	}
+
+	if pid.automatic {
+		return errors.Join(storage.ErrResourceNotWritable, ErrAutomaticMode)
+	}
+
+	pid.output = pid.clamp(float64(output))
+	pid.emit()
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func simulate(t *testing.T, memory map[string]storage.Resource) (*storage.Storage, *event.Events, *clock.Manual) {
+	t.Helper()
+
+	store := storage.NewStorage(memory)
+	events := event.NewEvents(time.Second)
+	manual := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err := store.Start(context.Background(), events, manual, 1); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	t.Cleanup(func() {
+		store.Stop(context.Background())
+	})
+
+	return store, events, manual
+}
+
+func read[T any](t *testing.T, store *storage.Storage, name string) T {
+	t.Helper()
+
+	value, err := store.Read(name)
+
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+
+	return value.(T)
+}
+
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newPID(t *testing.T, kp float64, ki float64) (*storage.Storage, *event.Events, *clock.Manual, *resource.PID[float32]) {
+	t.Helper()
+
+	pid := resource.NewPID[float32]("process", "setpoint", kp, ki, 0, time.Second, 0, 100)
+	store, events, manual := simulate(t, map[string]storage.Resource{
+		"process":  resource.NewStatic[float32](0),
+		"setpoint": resource.NewStatic[float32](0),
+		"output":   pid,
+	})
+
+	return store, events, manual, pid
+}
+
+func TestPIDAntiWindup(t *testing.T) {
+	store, _, manual, _ := newPID(t, 1, 1)
+	store.Write("setpoint", float32(1000))
+	manual.Advance(10 * time.Second)
+
+	if output := read[float32](t, store, "output"); output != 100 {
+		t.Fatalf("expected the output to saturate at 100, got %v", output)
+	}
+
+	store.Write("setpoint", float32(0))
+	store.Write("process", float32(50))
+	manual.Advance(time.Second)
+
+	if output := read[float32](t, store, "output"); output >= 100 {
+		t.Fatalf("expected the output to leave saturation after one tick, got %v", output)
+	}
+}
+
+func TestPIDBumplessTransfer(t *testing.T) {
+	store, events, manual, pid := newPID(t, 1, 0.1)
+	store.Write("process", float32(10))
+	store.Write("setpoint", float32(20))
+
+	events.Emit(event.Action("output", "manual"), nil)
+	eventually(t, func() bool { return !pid.Automatic() })
+
+	if err := store.Write("output", float32(40)); err != nil {
+		t.Fatalf("write in manual mode: %v", err)
+	}
+
+	manual.Advance(3 * time.Second)
+
+	if output := read[float32](t, store, "output"); output != 40 {
+		t.Fatalf("expected the manual output to hold, got %v", output)
+	}
+
+	events.Emit(event.Action("output", "auto"), nil)
+	eventually(t, pid.Automatic)
+	manual.Advance(time.Second)
+
+	if output := read[float32](t, store, "output"); output != 40 {
+		t.Fatalf("expected a bumpless transfer from 40, got %v", output)
+	}
+
+	manual.Advance(time.Second)
+
+	if output := read[float32](t, store, "output"); output <= 40 {
+		t.Fatalf("expected the integral to keep acting after the transfer, got %v", output)
+	}
+}
+
+func TestPIDRejectsWriteInAutomaticMode(t *testing.T) {
+	store, _, _, _ := newPID(t, 1, 0)
+	err := store.Write("output", float32(10))
+
+	if !errors.Is(err, storage.ErrResourceNotWritable) || !errors.Is(err, resource.ErrAutomaticMode) {
+		t.Fatalf("expected %v, got %v", resource.ErrAutomaticMode, err)
+	}
+}
+
+func TestPIDReset(t *testing.T) {
+	store, events, manual, _ := newPID(t, 0, 1)
+	store.Write("setpoint", float32(10))
+	manual.Advance(3 * time.Second)
+
+	if output := read[float32](t, store, "output"); output != 30 {
+		t.Fatalf("expected the integral to accumulate to 30, got %v", output)
+	}
+
+	events.Emit(event.Action("output", "reset"), nil)
+	eventually(t, func() bool { return read[float32](t, store, "output") == 0 })
+	manual.Advance(time.Second)
+
+	if output := read[float32](t, store, "output"); output != 10 {
+		t.Fatalf("expected the integral to restart from zero, got %v", output)
+	}
+}
@@ -1,92 +1,109 @@
 package resource
 
 import (
+	"context"
 	"math/rand/v2"
 	"sync"
 	"time"
 
+	"github.com/studiolambda/immersim/clock"
 	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
 	"github.com/studiolambda/immersim/storage"
 )
 
 type Random[T storage.Supported] struct {
-	name     string
-	current  T
-	min      T
-	max      T
-	interval time.Duration
-	mutex    sync.RWMutex
-	events   *event.Events
-	quit     chan struct{}
-	wg       sync.WaitGroup
+	name      string
+	current   T
+	min       T
+	max       T
+	interval  time.Duration
+	mutex     sync.RWMutex
+	stamp     stamp
+	events    *event.Events
+	ticker    clock.Ticker
+	source    rand.Source
+	generator *rand.Rand
 }
 
 func NewRandom[T storage.Supported](min T, max T, interval time.Duration) *Random[T] {
+	return NewRandomWithSource(min, max, interval, nil)
+}
+
+func NewSeededRandom[T storage.Supported](min T, max T, interval time.Duration, seed uint64) *Random[T] {
+	return NewRandomWithSource(min, max, interval, rand.NewPCG(seed, 0))
+}
+
+func NewRandomWithSource[T storage.Supported](min T, max T, interval time.Duration, source rand.Source) *Random[T] {
 	return &Random[T]{
-		name:     "",
-		events:   nil,
-		current:  *new(T),
-		min:      min,
-		max:      max,
-		interval: interval,
-		mutex:    sync.RWMutex{},
+		name:      "",
+		events:    nil,
+		current:   *new(T),
+		min:       min,
+		max:       max,
+		interval:  interval,
+		mutex:     sync.RWMutex{},
+		stamp:     newStamp(),
+		ticker:    nil,
+		source:    source,
+		generator: nil,
 	}
 }
 
-func (random *Random[T]) loop() {
-	defer random.wg.Done()
-
-	ticker := time.NewTicker(random.interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			var value any
-
-			switch any(random.current).(type) {
-			case int32:
-				min := any(random.min).(int32)
-				max := any(random.max).(int32)
-				value = min + rand.Int32N(max-min+1)
-			case float32:
-				min := any(random.min).(float32)
-				max := any(random.max).(float32)
-				value = min + rand.Float32()*(max-min)
-			case bool:
-				value = rand.Int32N(2) == 1
-			}
-
-			random.mutex.Lock()
-			random.current = value.(T)
-			random.events.Emit(event.Changed(random.name), event.ChangedPayload{
-				Resource: random.name,
-				Value:    random.current,
-			})
-			random.mutex.Unlock()
-
-		case <-random.quit:
-			return
-		}
+func (random *Random[T]) tick(now time.Time) {
+	var value any
+
+	switch any(random.current).(type) {
+	case int32:
+		min := any(random.min).(int32)
+		max := any(random.max).(int32)
+		value = min + random.generator.Int32N(max-min+1)
+	case float32:
+		min := any(random.min).(float32)
+		max := any(random.max).(float32)
+		value = min + random.generator.Float32()*(max-min)
+	case bool:
+		value = random.generator.Int32N(2) == 1
 	}
+
+	random.mutex.Lock()
+	random.current = value.(T)
+	random.events.Emit(event.Changed(random.name), random.stamp.changed(random.name, random.current))
+	random.mutex.Unlock()
 }
 
-func (random *Random[T]) Start(name string, storage *storage.Storage, events *event.Events) {
+func (random *Random[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if err := checkInterval(random.interval); err != nil {
+		return err
+	}
+
 	random.name = name
 	random.events = events
-	random.quit = make(chan struct{})
+	random.stamp.start(storage.Clock(), quality.BadWaitingForInitialData)
+	random.generator = rand.New(random.source)
+
+	if random.source == nil {
+		random.generator = rand.New(storage.Source(name))
+	}
 
-	random.wg.Add(1)
-	go random.loop()
+	random.ticker = storage.Clock().Every(random.interval, random.tick)
+
+	return nil
 }
 
-func (random *Random[T]) Stop() {
-	close(random.quit)
-	random.wg.Wait()
+func (random *Random[T]) Stop(ctx context.Context) error {
+	random.ticker.Stop()
+
+	random.mutex.Lock()
+	random.stamp.stop()
+	random.mutex.Unlock()
 
+	random.ticker = nil
+	random.generator = nil
 	random.name = ""
 	random.events = nil
-	random.quit = nil
+
+	return nil
 }
 
 func (random *Random[T]) Read() (any, error) {
@@ -95,3 +112,10 @@ func (random *Random[T]) Read() (any, error) {
 
 	return random.current, nil
 }
+
+func (random *Random[T]) Sample() (storage.Sample, error) {
+	random.mutex.RLock()
+	defer random.mutex.RUnlock()
+
+	return random.stamp.sample(random.current), nil
+}
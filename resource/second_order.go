@@ -0,0 +1,187 @@
+package resource
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type SecondOrder[T storage.SupportedNumeric] struct {
+	name               string
+	storage            *storage.Storage
+	events             *event.Events
+	input              string
+	gain               float64
+	naturalFrequencyHz float64
+	damping            float64
+	delay              time.Duration
+	interval           time.Duration
+	target             float64
+	output             float64
+	velocity           float64
+	deadTime           *deadTime
+	mutex              sync.RWMutex
+	stamp              stamp
+	ticker             clock.Ticker
+	listener           chan any
+	wg                 sync.WaitGroup
+}
+
+func NewSecondOrder[T storage.SupportedNumeric](input string, gain float64, naturalFrequencyHz float64, damping float64, delay time.Duration, interval time.Duration) *SecondOrder[T] {
+	return &SecondOrder[T]{
+		name:               "",
+		storage:            nil,
+		events:             nil,
+		input:              input,
+		gain:               gain,
+		naturalFrequencyHz: naturalFrequencyHz,
+		damping:            damping,
+		delay:              delay,
+		interval:           interval,
+		target:             0,
+		output:             0,
+		velocity:           0,
+		deadTime:           nil,
+		mutex:              sync.RWMutex{},
+		stamp:              newStamp(),
+		ticker:             nil,
+		listener:           nil,
+		wg:                 sync.WaitGroup{},
+	}
+}
+
+func (system *SecondOrder[T]) loop() {
+	defer system.wg.Done()
+
+	for range system.listener {
+		system.updateTarget()
+	}
+}
+
+func (system *SecondOrder[T]) updateTarget() {
+	target, err := readNumeric(system.storage, storage.Resolve(system.name, system.input))
+
+	system.mutex.Lock()
+	defer system.mutex.Unlock()
+
+	if err != nil {
+		system.stamp.signal(system.events, system.name, fromFloat[T](system.output), quality.UncertainLastUsableValue)
+		return
+	}
+
+	system.target = target
+	system.stamp.signal(system.events, system.name, fromFloat[T](system.output), quality.Good)
+}
+
+func (system *SecondOrder[T]) tick(now time.Time) {
+	system.mutex.Lock()
+	defer system.mutex.Unlock()
+
+	input := system.deadTime.shift(system.target)
+	previous := fromFloat[T](system.output)
+	omega := 2 * math.Pi * system.naturalFrequencyHz
+	steps := max(int(math.Ceil(system.interval.Seconds()*omega/0.05)), 1)
+	step := system.interval.Seconds() / float64(steps)
+
+	for range steps {
+		acceleration := omega*omega*(system.gain*input-system.output) - 2*system.damping*omega*system.velocity
+		system.velocity += acceleration * step
+		system.output += system.velocity * step
+	}
+
+	if current := fromFloat[T](system.output); current != previous {
+		system.events.Emit(event.Changed(system.name), system.stamp.changed(system.name, current))
+	}
+}
+
+func (system *SecondOrder[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if err := checkInterval(system.interval); err != nil {
+		return err
+	}
+
+	if err := checkDelay(system.delay); err != nil {
+		return err
+	}
+
+	if err := checkPositive(system.naturalFrequencyHz, ErrInvalidFrequency); err != nil {
+		return err
+	}
+
+	if err := checkPositive(system.damping, ErrInvalidDamping); err != nil {
+		return err
+	}
+
+	system.name = name
+	system.storage = storage
+	system.events = events
+	system.stamp.start(storage.Clock(), quality.Good)
+	system.listener = make(chan any, 1)
+
+	system.updateTarget()
+	system.output = system.gain * system.target
+	system.velocity = 0
+	system.deadTime = newDeadTime(system.delay, system.interval, system.target)
+	system.ticker = storage.Clock().Every(system.interval, system.tick)
+
+	system.wg.Add(1)
+	go system.loop()
+
+	system.events.SubscribeWith(event.Changed(storage.Resolve(system.name, system.input)), system.listener, event.Coalesce, 1)
+
+	return nil
+}
+
+func (system *SecondOrder[T]) Stop(ctx context.Context) error {
+	system.events.Unsubscribe(event.Changed(storage.Resolve(system.name, system.input)), system.listener)
+	close(system.listener)
+
+	system.ticker.Stop()
+
+	if err := wait(ctx, &system.wg); err != nil {
+		return err
+	}
+
+	system.mutex.Lock()
+	system.stamp.stop()
+	system.mutex.Unlock()
+
+	system.ticker = nil
+	system.deadTime = nil
+	system.name = ""
+	system.storage = nil
+	system.events = nil
+
+	return nil
+}
+
+func (system *SecondOrder[T]) Dependencies() []string {
+	return []string{system.input}
+}
+
+func (system *SecondOrder[T]) Rewrite(rewrite func(reference string) string) {
+	system.input = rewrite(system.input)
+}
+
+func (system *SecondOrder[T]) Delayed() bool {
+	return true
+}
+
+func (system *SecondOrder[T]) Read() (any, error) {
+	system.mutex.RLock()
+	defer system.mutex.RUnlock()
+
+	return fromFloat[T](system.output), nil
+}
+
+func (system *SecondOrder[T]) Sample() (storage.Sample, error) {
+	system.mutex.RLock()
+	defer system.mutex.RUnlock()
+
+	return system.stamp.sample(fromFloat[T](system.output)), nil
+}
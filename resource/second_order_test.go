@@ -0,0 +1,84 @@
+package resource_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func respond(t *testing.T, naturalFrequencyHz float64, damping float64, duration time.Duration) ([]float64, time.Duration) {
+	t.Helper()
+
+	interval := 10 * time.Millisecond
+	store, _, manual := simulate(t, map[string]storage.Resource{
+		"input":  resource.NewStatic[float32](0),
+		"system": resource.NewSecondOrder[float32]("input", 1, naturalFrequencyHz, damping, 0, interval),
+	})
+
+	store.Write("input", float32(1))
+	step(t, store, manual, interval, "system")
+
+	response := []float64{float64(read[float32](t, store, "system"))}
+
+	for elapsed := interval; elapsed < duration; elapsed += interval {
+		manual.Advance(interval)
+		response = append(response, float64(read[float32](t, store, "system")))
+	}
+
+	return response, interval
+}
+
+func peak(response []float64) (int, float64) {
+	index := 0
+
+	for i, value := range response {
+		if value > response[index] {
+			index = i
+		}
+	}
+
+	return index, response[index]
+}
+
+func TestSecondOrderUnderdampedStep(t *testing.T) {
+	response, interval := respond(t, 1, 0.5, 5*time.Second)
+	index, maximum := peak(response)
+
+	damped := 2 * math.Pi * math.Sqrt(1-0.5*0.5)
+	near(t, "overshoot", maximum, 1+math.Exp(-0.5*math.Pi/math.Sqrt(1-0.5*0.5)), 0.02)
+	near(t, "peak time", (time.Duration(index) * interval).Seconds(), math.Pi/damped, 0.03)
+	near(t, "steady state", response[len(response)-1], 1, 0.01)
+}
+
+func TestSecondOrderCriticallyDampedStep(t *testing.T) {
+	response, _ := respond(t, 1, 1, 3*time.Second)
+
+	if _, maximum := peak(response); maximum > 1.001 {
+		t.Fatalf("expected no overshoot, got %g", maximum)
+	}
+
+	near(t, "steady state", response[len(response)-1], 1, 0.01)
+}
+
+func TestSecondOrderRejectsInvalidParameters(t *testing.T) {
+	tests := []struct {
+		system *resource.SecondOrder[float32]
+		err    error
+	}{
+		{system: resource.NewSecondOrder[float32]("input", 1, 0, 0.5, 0, time.Second), err: resource.ErrInvalidFrequency},
+		{system: resource.NewSecondOrder[float32]("input", 1, -1, 0.5, 0, time.Second), err: resource.ErrInvalidFrequency},
+		{system: resource.NewSecondOrder[float32]("input", 1, math.NaN(), 0.5, 0, time.Second), err: resource.ErrInvalidFrequency},
+		{system: resource.NewSecondOrder[float32]("input", 1, 1, 0, 0, time.Second), err: resource.ErrInvalidDamping},
+		{system: resource.NewSecondOrder[float32]("input", 1, 1, -0.5, 0, time.Second), err: resource.ErrInvalidDamping},
+	}
+
+	for _, test := range tests {
+		if err := start(test.system); !errors.Is(err, test.err) {
+			t.Fatalf("expected %v, got %v", test.err, err)
+		}
+	}
+}
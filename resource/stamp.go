@@ -0,0 +1,81 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type stamp struct {
+	clock     clock.Clock
+	timestamp time.Time
+	quality   quality.Quality
+}
+
+func newStamp() stamp {
+	return stamp{
+		clock:     nil,
+		timestamp: time.Time{},
+		quality:   quality.BadWaitingForInitialData,
+	}
+}
+
+func (stamp *stamp) start(clock clock.Clock, initial quality.Quality) {
+	stamp.clock = clock
+	stamp.timestamp = clock.Now()
+	stamp.quality = initial
+}
+
+func (stamp *stamp) stop() {
+	stamp.quality = quality.BadOutOfService
+}
+
+func (stamp *stamp) mark(quality quality.Quality) bool {
+	if stamp.quality == quality {
+		return false
+	}
+
+	stamp.quality = quality
+	stamp.timestamp = stamp.clock.Now()
+
+	return true
+}
+
+func (stamp *stamp) signal(events *event.Events, name string, value any, quality quality.Quality) {
+	if stamp.mark(quality) {
+		events.Emit(event.Changed(name), stamp.payload(name, value))
+	}
+}
+
+func (stamp *stamp) changed(name string, value any) event.ChangedPayload {
+	return stamp.record(name, value, quality.Good)
+}
+
+func (stamp *stamp) record(name string, value any, quality quality.Quality) event.ChangedPayload {
+	stamp.quality = quality
+	stamp.timestamp = stamp.clock.Now()
+
+	return stamp.payload(name, value)
+}
+
+func (stamp *stamp) payload(name string, value any) event.ChangedPayload {
+	return event.ChangedPayload{
+		Resource:        name,
+		Value:           value,
+		Quality:         stamp.quality,
+		SourceTimestamp: stamp.timestamp,
+		ServerTimestamp: stamp.clock.Now(),
+	}
+}
+
+func (stamp *stamp) sample(value any) storage.Sample {
+	return storage.Sample{
+		Value:           value,
+		Quality:         stamp.quality,
+		SourceTimestamp: stamp.timestamp,
+		ServerTimestamp: time.Time{},
+	}
+}
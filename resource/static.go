@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -11,14 +12,19 @@ import (
 
 type Static[T storage.Supported] struct {
 	*Constant[T]
-	mutex sync.RWMutex
+	storage *storage.Storage
+	mutex   sync.RWMutex
 }
 
-var ErrMissmatchedTypes = errors.New("missatched types")
+var (
+	ErrMissmatchedTypes = errors.New("missatched types")
+	ErrNotRunning       = errors.New("resource is not running")
+)
 
 func NewStatic[T storage.Supported](value T) *Static[T] {
 	return &Static[T]{
 		Constant: NewConstant(value),
+		storage:  nil,
 		mutex:    sync.RWMutex{},
 	}
 }
@@ -30,16 +36,53 @@ func (static *Static[T]) Read() (any, error) {
 	return static.Constant.Read()
 }
 
+func (static *Static[T]) Sample() (storage.Sample, error) {
+	static.mutex.RLock()
+	defer static.mutex.RUnlock()
+
+	return static.Constant.Sample()
+}
+
+func (static *Static[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	static.mutex.Lock()
+	static.storage = storage
+	static.mutex.Unlock()
+
+	return static.Constant.Start(ctx, name, storage, events)
+}
+
+func (static *Static[T]) Stop(ctx context.Context) error {
+	err := static.Constant.Stop(ctx)
+
+	static.mutex.Lock()
+	static.storage = nil
+	static.mutex.Unlock()
+
+	return err
+}
+
 func (static *Static[T]) Write(value any) error {
 	static.mutex.Lock()
 	defer static.mutex.Unlock()
 
+	if static.storage == nil {
+		return ErrNotRunning
+	}
+
 	if val, ok := value.(T); ok {
+		if metadata, ok := static.storage.Metadata(static.name); ok {
+			if err := metadata.Check(static.name, val); err != nil {
+				return err
+			}
+		}
+
 		static.current = val
-		static.events.Emit(event.Changed(static.name), event.ChangedPayload{
-			Resource: static.name,
-			Value:    static.current,
-		})
+
+		static.Constant.mutex.Lock()
+		payload := static.stamp.changed(static.name, static.current)
+		static.Constant.mutex.Unlock()
+
+		static.events.Emit(event.Changed(static.name), payload)
 
 		return nil
 	}
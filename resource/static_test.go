@@ -0,0 +1,47 @@
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func TestStaticWriteRequiresRunning(t *testing.T) {
+	static := resource.NewStatic[int32](1)
+
+	if err := static.Write(int32(2)); !errors.Is(err, resource.ErrNotRunning) {
+		t.Fatalf("before start: expected %v, got %v", resource.ErrNotRunning, err)
+	}
+
+	store := storage.NewStorage(map[string]storage.Resource{"value": static})
+
+	if err := store.Start(context.Background(), event.NewEvents(time.Second), clock.NewManual(time.Time{}), 1); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	if err := static.Write(int32(3)); err != nil {
+		t.Fatalf("while running: %v", err)
+	}
+
+	if err := static.Write(float32(3)); !errors.Is(err, resource.ErrMissmatchedTypes) {
+		t.Fatalf("expected %v, got %v", resource.ErrMissmatchedTypes, err)
+	}
+
+	if err := store.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	if err := static.Write(int32(4)); !errors.Is(err, resource.ErrNotRunning) {
+		t.Fatalf("after stop: expected %v, got %v", resource.ErrNotRunning, err)
+	}
+
+	if value, _ := static.Read(); value != int32(3) {
+		t.Fatalf("expected the rejected writes to keep 3, got %v", value)
+	}
+}
@@ -0,0 +1,312 @@
+package resource
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type Waveform[T storage.Supported] struct {
+	name      string
+	kind      string
+	events    *event.Events
+	shape     func(phase float64) float64
+	frequency float64
+	end       float64
+	sweep     time.Duration
+	amplitude float64
+	offset    float64
+	phase     float64
+	cycle     float64
+	elapsed   time.Duration
+	interval  time.Duration
+	current   T
+	running   bool
+	mutex     sync.RWMutex
+	stamp     stamp
+	ticker    clock.Ticker
+	quit      chan struct{}
+	wg        sync.WaitGroup
+	start     chan any
+	stop      chan any
+	reset     chan any
+}
+
+func NewSquare[T storage.Supported](frequency float64, amplitude float64, offset float64, phase float64, interval time.Duration) *Waveform[T] {
+	return newWaveform[T]("square", square, frequency, amplitude, offset, phase, interval)
+}
+
+func NewTriangle[T storage.SupportedNumeric](frequency float64, amplitude float64, offset float64, phase float64, interval time.Duration) *Waveform[T] {
+	return newWaveform[T]("triangle", triangle, frequency, amplitude, offset, phase, interval)
+}
+
+func NewSawtooth[T storage.SupportedNumeric](frequency float64, amplitude float64, offset float64, phase float64, interval time.Duration) *Waveform[T] {
+	return newWaveform[T]("sawtooth", sawtooth, frequency, amplitude, offset, phase, interval)
+}
+
+func NewPulse[T storage.Supported](frequency float64, duty float64, amplitude float64, offset float64, phase float64, interval time.Duration) *Waveform[T] {
+	return newWaveform[T]("pulse", pulse(duty), frequency, amplitude, offset, phase, interval)
+}
+
+func NewChirp[T storage.SupportedNumeric](start float64, end float64, sweep time.Duration, amplitude float64, offset float64, phase float64, interval time.Duration) *Waveform[T] {
+	waveform := newWaveform[T]("chirp", sine, start, amplitude, offset, phase, interval)
+	waveform.end = end
+	waveform.sweep = sweep
+
+	return waveform
+}
+
+func newWaveform[T storage.Supported](kind string, shape func(phase float64) float64, frequency float64, amplitude float64, offset float64, phase float64, interval time.Duration) *Waveform[T] {
+	waveform := &Waveform[T]{
+		name:      "",
+		kind:      kind,
+		events:    nil,
+		shape:     shape,
+		frequency: frequency,
+		end:       frequency,
+		sweep:     0,
+		amplitude: amplitude,
+		offset:    offset,
+		phase:     phase - math.Floor(phase),
+		cycle:     0,
+		elapsed:   0,
+		interval:  interval,
+		current:   *new(T),
+		running:   true,
+		mutex:     sync.RWMutex{},
+		stamp:     newStamp(),
+		ticker:    nil,
+		quit:      nil,
+		wg:        sync.WaitGroup{},
+		start:     nil,
+		stop:      nil,
+		reset:     nil,
+	}
+
+	waveform.current = waveform.sample()
+
+	return waveform
+}
+
+func sine(phase float64) float64 {
+	return math.Sin(2 * math.Pi * phase)
+}
+
+func square(phase float64) float64 {
+	if phase < 0.5 {
+		return 1
+	}
+
+	return -1
+}
+
+func triangle(phase float64) float64 {
+	return 1 - 4*math.Abs(phase-0.5)
+}
+
+func sawtooth(phase float64) float64 {
+	return 2*phase - 1
+}
+
+func pulse(duty float64) func(phase float64) float64 {
+	return func(phase float64) float64 {
+		if phase < duty {
+			return 1
+		}
+
+		return 0
+	}
+}
+
+func (waveform *Waveform[T]) sample() T {
+	level := waveform.shape(math.Mod(waveform.cycle+waveform.phase, 1))
+
+	switch any(waveform.current).(type) {
+	case bool:
+		return any(level > 0).(T)
+	case int32:
+		return any(fromFloat[int32](waveform.offset + waveform.amplitude*level)).(T)
+	case float32:
+		return any(fromFloat[float32](waveform.offset + waveform.amplitude*level)).(T)
+	}
+
+	return waveform.current
+}
+
+func (waveform *Waveform[T]) instantaneous() float64 {
+	if waveform.sweep <= 0 {
+		return waveform.frequency
+	}
+
+	progress := float64(waveform.elapsed%waveform.sweep) / float64(waveform.sweep)
+
+	return waveform.frequency + (waveform.end-waveform.frequency)*progress
+}
+
+func (waveform *Waveform[T]) tick(now time.Time) {
+	waveform.mutex.Lock()
+	defer waveform.mutex.Unlock()
+
+	if !waveform.running {
+		return
+	}
+
+	waveform.cycle = math.Mod(waveform.cycle+waveform.instantaneous()*waveform.interval.Seconds(), 1)
+	waveform.elapsed += waveform.interval
+
+	waveform.update()
+}
+
+func (waveform *Waveform[T]) update() {
+	if current := waveform.sample(); current != waveform.current {
+		waveform.current = current
+		waveform.events.Emit(event.Changed(waveform.name), waveform.stamp.changed(waveform.name, waveform.current))
+	}
+}
+
+func (waveform *Waveform[T]) loop() {
+	defer waveform.wg.Done()
+
+	for {
+		select {
+		case <-waveform.start:
+			waveform.mutex.Lock()
+			waveform.running = true
+			waveform.mutex.Unlock()
+		case <-waveform.stop:
+			waveform.mutex.Lock()
+			waveform.running = false
+			waveform.mutex.Unlock()
+		case <-waveform.reset:
+			waveform.mutex.Lock()
+			waveform.cycle = 0
+			waveform.elapsed = 0
+			waveform.update()
+			waveform.mutex.Unlock()
+		case <-waveform.quit:
+			return
+		}
+	}
+}
+
+func (waveform *Waveform[T]) SetFrequency(frequency float64) error {
+	if err := checkPositive(frequency, ErrInvalidFrequency); err != nil {
+		return err
+	}
+
+	waveform.mutex.Lock()
+	defer waveform.mutex.Unlock()
+
+	waveform.frequency = frequency
+
+	return nil
+}
+
+func (waveform *Waveform[T]) SetAmplitude(amplitude float64) error {
+	if err := checkAmplitude(amplitude); err != nil {
+		return err
+	}
+
+	waveform.mutex.Lock()
+	defer waveform.mutex.Unlock()
+
+	waveform.amplitude = amplitude
+
+	return nil
+}
+
+func (waveform *Waveform[T]) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if err := checkInterval(waveform.interval); err != nil {
+		return err
+	}
+
+	if err := checkPositive(waveform.frequency, ErrInvalidFrequency); err != nil {
+		return err
+	}
+
+	if err := checkPositive(waveform.end, ErrInvalidFrequency); err != nil {
+		return err
+	}
+
+	if err := checkAmplitude(waveform.amplitude); err != nil {
+		return err
+	}
+
+	waveform.name = name
+	waveform.events = events
+	waveform.stamp.start(storage.Clock(), quality.Good)
+	waveform.quit = make(chan struct{})
+	waveform.start = make(chan any)
+	waveform.stop = make(chan any)
+	waveform.reset = make(chan any)
+	waveform.ticker = storage.Clock().Every(waveform.interval, waveform.tick)
+
+	waveform.wg.Add(1)
+	go waveform.loop()
+
+	waveform.events.Subscribe(event.Action(waveform.name, "start"), waveform.start)
+	waveform.events.Subscribe(event.Action(waveform.name, "stop"), waveform.stop)
+	waveform.events.Subscribe(event.Action(waveform.name, "reset"), waveform.reset)
+
+	return nil
+}
+
+func (waveform *Waveform[T]) Stop(ctx context.Context) error {
+	waveform.events.Unsubscribe(event.Action(waveform.name, "start"), waveform.start)
+	waveform.events.Unsubscribe(event.Action(waveform.name, "stop"), waveform.stop)
+	waveform.events.Unsubscribe(event.Action(waveform.name, "reset"), waveform.reset)
+
+	close(waveform.quit)
+
+	waveform.ticker.Stop()
+
+	if err := wait(ctx, &waveform.wg); err != nil {
+		return err
+	}
+
+	close(waveform.start)
+	close(waveform.stop)
+	close(waveform.reset)
+
+	waveform.mutex.Lock()
+	waveform.stamp.stop()
+	waveform.mutex.Unlock()
+
+	waveform.name = ""
+	waveform.events = nil
+	waveform.ticker = nil
+	waveform.quit = nil
+	waveform.start = nil
+	waveform.stop = nil
+	waveform.reset = nil
+
+	return nil
+}
+
+func (waveform *Waveform[T]) Kind() string {
+	return waveform.kind
+}
+
+func (waveform *Waveform[T]) Actions() []string {
+	return []string{"start", "stop", "reset"}
+}
+
+func (waveform *Waveform[T]) Read() (any, error) {
+	waveform.mutex.RLock()
+	defer waveform.mutex.RUnlock()
+
+	return waveform.current, nil
+}
+
+func (waveform *Waveform[T]) Sample() (storage.Sample, error) {
+	waveform.mutex.RLock()
+	defer waveform.mutex.RUnlock()
+
+	return waveform.stamp.sample(waveform.current), nil
+}
@@ -0,0 +1,129 @@
+package resource_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func TestWaveformShapes(t *testing.T) {
+	interval := 250 * time.Millisecond
+	tests := []struct {
+		kind     string
+		waveform storage.Resource
+		expected []float64
+	}{
+		{kind: "sine", waveform: resource.NewSine[float32](1, 1, 0, 0, interval), expected: []float64{0, 1, 0, -1}},
+		{kind: "sine with phase", waveform: resource.NewSine[float32](1, 1, 0, 0.25, interval), expected: []float64{1, 0, -1, 0}},
+		{kind: "square", waveform: resource.NewSquare[float32](1, 1, 0, 0, interval), expected: []float64{1, 1, -1, -1}},
+		{kind: "triangle", waveform: resource.NewTriangle[float32](1, 1, 0, 0, interval), expected: []float64{-1, 0, 1, 0}},
+		{kind: "sawtooth", waveform: resource.NewSawtooth[float32](1, 1, 0, 0, interval), expected: []float64{-1, -0.5, 0, 0.5}},
+		{kind: "pulse", waveform: resource.NewPulse[float32](1, 0.25, 2, 1, 0, interval), expected: []float64{3, 1, 1, 1}},
+	}
+
+	for _, test := range tests {
+		store, _, manual := simulate(t, map[string]storage.Resource{"wave": test.waveform})
+
+		for i, expected := range test.expected {
+			near(t, test.kind, float64(read[float32](t, store, "wave")), expected, 1e-6)
+
+			if i < len(test.expected)-1 {
+				manual.Advance(interval)
+			}
+		}
+	}
+}
+
+func TestSineWaveIsPhaseContinuous(t *testing.T) {
+	sine := resource.NewSineWave(1, 1, 0, 100*time.Millisecond)
+	store, _, manual := simulate(t, map[string]storage.Resource{"wave": sine})
+
+	manual.Advance(300 * time.Millisecond)
+	before := read[float32](t, store, "wave")
+
+	if err := sine.SetFrequency(2); err != nil {
+		t.Fatalf("set frequency: %v", err)
+	}
+
+	if after := read[float32](t, store, "wave"); after != before {
+		t.Fatalf("expected the frequency change not to jump the output, got %v then %v", before, after)
+	}
+
+	manual.Advance(100 * time.Millisecond)
+	near(t, "after the change", float64(read[float32](t, store, "wave")), math.Sin(2*math.Pi*0.5), 1e-6)
+}
+
+func TestWaveformActions(t *testing.T) {
+	interval := 250 * time.Millisecond
+	store, events, manual := simulate(t, map[string]storage.Resource{
+		"wave": resource.NewSawtooth[float32](1, 1, 0, 0, interval),
+	})
+
+	events.Emit(event.Action("wave", "stop"), nil)
+	eventually(t, func() bool {
+		before := read[float32](t, store, "wave")
+		manual.Advance(interval)
+
+		return read[float32](t, store, "wave") == before
+	})
+
+	events.Emit(event.Action("wave", "start"), nil)
+	eventually(t, func() bool {
+		before := read[float32](t, store, "wave")
+		manual.Advance(interval)
+
+		return read[float32](t, store, "wave") != before
+	})
+
+	if read[float32](t, store, "wave") == -1 {
+		manual.Advance(interval)
+	}
+
+	events.Emit(event.Action("wave", "reset"), nil)
+	eventually(t, func() bool { return read[float32](t, store, "wave") == -1 })
+}
+
+func TestWaveformRejectsInvalidParameters(t *testing.T) {
+	tests := []struct {
+		waveform storage.Resource
+		err      error
+	}{
+		{waveform: resource.NewSineWave(0, 1, 0, time.Second), err: resource.ErrInvalidFrequency},
+		{waveform: resource.NewSineWave(math.NaN(), 1, 0, time.Second), err: resource.ErrInvalidFrequency},
+		{waveform: resource.NewSineWave(1, -1, 0, time.Second), err: resource.ErrInvalidAmplitude},
+		{waveform: resource.NewSineWave(1, math.Inf(1), 0, time.Second), err: resource.ErrInvalidAmplitude},
+		{waveform: resource.NewSineWave(1, 1, 0, 0), err: resource.ErrInvalidInterval},
+		{waveform: resource.NewChirp[float32](1, 0, time.Second, 1, 0, 0, time.Second), err: resource.ErrInvalidFrequency},
+	}
+
+	for _, test := range tests {
+		if err := start(test.waveform); !errors.Is(err, test.err) {
+			t.Fatalf("expected %v, got %v", test.err, err)
+		}
+	}
+}
+
+func TestWaveformSettersValidate(t *testing.T) {
+	sine := resource.NewSineWave(1, 1, 0, time.Second)
+
+	for _, frequency := range []float64{0, -1, math.NaN()} {
+		if err := sine.SetFrequency(frequency); !errors.Is(err, resource.ErrInvalidFrequency) {
+			t.Fatalf("frequency %g: expected %v, got %v", frequency, resource.ErrInvalidFrequency, err)
+		}
+	}
+
+	for _, amplitude := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if err := sine.SetAmplitude(amplitude); !errors.Is(err, resource.ErrInvalidAmplitude) {
+			t.Fatalf("amplitude %g: expected %v, got %v", amplitude, resource.ErrInvalidAmplitude, err)
+		}
+	}
+
+	if err := start(sine); err != nil {
+		t.Fatalf("expected the rejected values to leave the waveform valid, got %v", err)
+	}
+}
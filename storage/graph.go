@@ -0,0 +1,326 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type Delayed interface {
+	Delayed() bool
+}
+
+type Graph struct {
+	nodes        []string
+	dependencies map[string][]string
+	delayed      map[string]bool
+}
+
+var (
+	ErrMissingReference = errors.New("missing reference")
+	ErrCycle            = errors.New("dependency cycle")
+)
+
+func (storage *Storage) Graph() *Graph {
+	storage.mutex.RLock()
+	resources := maps.Clone(storage.memory)
+	storage.mutex.RUnlock()
+
+	return newGraph(resources)
+}
+
+func (storage *Storage) propose(added map[string]Resource) *Graph {
+	storage.mutex.RLock()
+	resources := maps.Clone(storage.memory)
+	storage.mutex.RUnlock()
+
+	if resources == nil {
+		resources = make(map[string]Resource)
+	}
+
+	maps.Copy(resources, added)
+
+	return newGraph(resources)
+}
+
+func newGraph(resources map[string]Resource) *Graph {
+	graph := &Graph{
+		nodes:        make([]string, 0, len(resources)),
+		dependencies: make(map[string][]string),
+		delayed:      make(map[string]bool),
+	}
+
+	for name := range resources {
+		graph.nodes = append(graph.nodes, name)
+	}
+
+	slices.Sort(graph.nodes)
+
+	for _, name := range graph.nodes {
+		resource := resources[name]
+
+		if dependent, ok := resource.(Dependent); ok {
+			dependencies := make([]string, 0, len(dependent.Dependencies()))
+
+			for _, dependency := range dependent.Dependencies() {
+				dependencies = append(dependencies, Resolve(name, dependency))
+			}
+
+			slices.Sort(dependencies)
+			graph.dependencies[name] = slices.Compact(dependencies)
+		}
+
+		if delayed, ok := resource.(Delayed); ok {
+			graph.delayed[name] = delayed.Delayed()
+		}
+	}
+
+	return graph
+}
+
+func (graph *Graph) Nodes() []string {
+	return slices.Clone(graph.nodes)
+}
+
+func (graph *Graph) Dependencies(name string) []string {
+	return slices.Clone(graph.dependencies[name])
+}
+
+func (graph *Graph) Dependents(name string) []string {
+	var dependents []string
+
+	for _, node := range graph.nodes {
+		if slices.Contains(graph.dependencies[node], name) {
+			dependents = append(dependents, node)
+		}
+	}
+
+	return dependents
+}
+
+func (graph *Graph) Missing() map[string][]string {
+	missing := make(map[string][]string)
+
+	for _, node := range graph.nodes {
+		for _, dependency := range graph.dependencies[node] {
+			if _, ok := slices.BinarySearch(graph.nodes, dependency); !ok {
+				missing[node] = append(missing[node], dependency)
+			}
+		}
+	}
+
+	return missing
+}
+
+func (graph *Graph) Cycles() [][]string {
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	stacked := make(map[string]bool)
+	stack := []string{}
+	cycles := [][]string{}
+
+	var connect func(node string)
+
+	connect = func(node string) {
+		index[node] = len(index)
+		lowlink[node] = index[node]
+		stack = append(stack, node)
+		stacked[node] = true
+
+		for _, next := range graph.immediate(node) {
+			if _, visited := index[next]; !visited {
+				connect(next)
+				lowlink[node] = min(lowlink[node], lowlink[next])
+			} else if stacked[next] {
+				lowlink[node] = min(lowlink[node], index[next])
+			}
+		}
+
+		if lowlink[node] != index[node] {
+			return
+		}
+
+		var component []string
+
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			stacked[top] = false
+			component = append(component, top)
+
+			if top == node {
+				break
+			}
+		}
+
+		if len(component) > 1 || slices.Contains(graph.immediate(node), node) {
+			slices.Sort(component)
+			path := graph.path(component)
+			slices.Reverse(path)
+			cycles = append(cycles, path)
+		}
+	}
+
+	for _, node := range graph.nodes {
+		if _, visited := index[node]; !visited {
+			connect(node)
+		}
+	}
+
+	slices.SortFunc(cycles, func(a []string, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+
+	return cycles
+}
+
+func (graph *Graph) Validate() error {
+	return graph.validate(graph.nodes)
+}
+
+func (graph *Graph) validate(nodes []string) error {
+	var errs []error
+
+	missing := graph.Missing()
+
+	for _, node := range nodes {
+		for _, dependency := range missing[node] {
+			errs = append(errs, fmt.Errorf("%w: %s references %s", ErrMissingReference, node, dependency))
+		}
+	}
+
+	for _, cycle := range graph.Cycles() {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrCycle, strings.Join(cycle, " -> ")))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (graph *Graph) DOT() string {
+	var builder strings.Builder
+
+	builder.WriteString("digraph storage {\n")
+
+	for _, node := range graph.nodes {
+		fmt.Fprintf(&builder, "\t%q;\n", node)
+	}
+
+	missing := graph.Missing()
+	drawn := make(map[string]bool)
+
+	for _, node := range graph.nodes {
+		for _, dependency := range missing[node] {
+			if !drawn[dependency] {
+				drawn[dependency] = true
+				fmt.Fprintf(&builder, "\t%q [color=red, style=dashed];\n", dependency)
+			}
+		}
+	}
+
+	for _, node := range graph.nodes {
+		for _, dependency := range graph.dependencies[node] {
+			if graph.delayed[node] {
+				fmt.Fprintf(&builder, "\t%q -> %q [style=dashed];\n", dependency, node)
+				continue
+			}
+
+			fmt.Fprintf(&builder, "\t%q -> %q;\n", dependency, node)
+		}
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+func (graph *Graph) immediate(node string) []string {
+	if graph.delayed[node] {
+		return nil
+	}
+
+	return graph.dependencies[node]
+}
+
+func (graph *Graph) path(component []string) []string {
+	start := component[0]
+	visited := make(map[string]bool)
+	path := []string{start}
+
+	var walk func(node string) bool
+
+	walk = func(node string) bool {
+		for _, next := range graph.immediate(node) {
+			if next == start {
+				path = append(path, start)
+				return true
+			}
+
+			if visited[next] || !slices.Contains(component, next) {
+				continue
+			}
+
+			visited[next] = true
+			path = append(path, next)
+
+			if walk(next) {
+				return true
+			}
+
+			path = path[:len(path)-1]
+		}
+
+		return false
+	}
+
+	walk(start)
+
+	return path
+}
+
+func (graph *Graph) Order() []string {
+	pending := make(map[string]int)
+
+	for _, node := range graph.nodes {
+		for _, dependency := range graph.immediate(node) {
+			if _, ok := slices.BinarySearch(graph.nodes, dependency); ok {
+				pending[node]++
+			}
+		}
+	}
+
+	order := make([]string, 0, len(graph.nodes))
+	placed := make(map[string]bool)
+
+	for len(order) < len(graph.nodes) {
+		progressed := false
+
+		for _, node := range graph.nodes {
+			if placed[node] || pending[node] > 0 {
+				continue
+			}
+
+			placed[node] = true
+			order = append(order, node)
+			progressed = true
+
+			for _, dependent := range graph.nodes {
+				if slices.Contains(graph.immediate(dependent), node) {
+					pending[dependent]--
+				}
+			}
+		}
+
+		if !progressed {
+			for _, node := range graph.nodes {
+				if !placed[node] {
+					placed[node] = true
+					order = append(order, node)
+				}
+			}
+		}
+	}
+
+	return order
+}
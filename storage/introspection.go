@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type Kinded interface {
+	Kind() string
+}
+
+type Dependent interface {
+	Dependencies() []string
+}
+
+type Info struct {
+	Name         string   `json:"name"`
+	Kind         string   `json:"kind"`
+	Type         string   `json:"type,omitempty"`
+	Readable     bool     `json:"readable"`
+	Writable     bool     `json:"writable"`
+	Actions      []string `json:"actions"`
+	Dependencies []string `json:"dependencies"`
+}
+
+func (storage *Storage) Inspect(name string) (Info, bool) {
+	resource, ok := storage.Lookup(name)
+
+	if !ok {
+		return Info{}, false
+	}
+
+	info := Info{
+		Name:         name,
+		Kind:         KindOf(resource),
+		Type:         "",
+		Readable:     false,
+		Writable:     false,
+		Actions:      []string{},
+		Dependencies: []string{},
+	}
+
+	if reader, ok := resource.(Reader); ok {
+		if value, err := reader.Read(); err == nil {
+			info.Type = fmt.Sprintf("%T", value)
+		}
+
+		info.Readable = storage.readable(name)
+	}
+
+	if _, ok := resource.(Writer); ok {
+		info.Writable = storage.writable(name)
+	}
+
+	if actionable, ok := resource.(Actionable); ok {
+		info.Actions = append(info.Actions, actionable.Actions()...)
+		slices.Sort(info.Actions)
+	}
+
+	if dependent, ok := resource.(Dependent); ok {
+		for _, dependency := range dependent.Dependencies() {
+			info.Dependencies = append(info.Dependencies, Resolve(name, dependency))
+		}
+
+		slices.Sort(info.Dependencies)
+		info.Dependencies = slices.Compact(info.Dependencies)
+	}
+
+	return info, true
+}
+
+func (storage *Storage) Inventory() []Info {
+	names := storage.Names()
+	inventory := make([]Info, 0, len(names))
+
+	for _, name := range names {
+		if info, ok := storage.Inspect(name); ok {
+			inventory = append(inventory, info)
+		}
+	}
+
+	return inventory
+}
+
+func KindOf(resource Resource) string {
+	if kinded, ok := resource.(Kinded); ok {
+		return kinded.Kind()
+	}
+
+	kind := reflect.TypeOf(resource)
+
+	for kind.Kind() == reflect.Pointer {
+		kind = kind.Elem()
+	}
+
+	name, _, _ := strings.Cut(kind.Name(), "[")
+	runes := []rune(name)
+
+	var builder strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			builder.WriteRune('_')
+		}
+
+		builder.WriteRune(unicode.ToLower(r))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/studiolambda/immersim/event"
+)
+
+var (
+	ErrResourceExists   = errors.New("resource already exists")
+	ErrResourceNotFound = errors.New("resource not found")
+	ErrResourceInUse    = errors.New("resource is used by other resources")
+)
+
+func (storage *Storage) Add(ctx context.Context, name string, resource Resource) error {
+	if err := CheckName(name); err != nil {
+		return err
+	}
+
+	storage.lifecycle.Lock()
+	defer storage.lifecycle.Unlock()
+
+	storage.mutex.Lock()
+
+	if _, ok := storage.memory[name]; ok {
+		storage.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrResourceExists, name)
+	}
+
+	storage.mutex.Unlock()
+
+	if storage.running {
+		if err := storage.propose(map[string]Resource{name: resource}).validate([]string{name}); err != nil {
+			return err
+		}
+	}
+
+	storage.mutex.Lock()
+
+	if storage.memory == nil {
+		storage.memory = make(map[string]Resource)
+	}
+
+	storage.memory[name] = resource
+	storage.mutex.Unlock()
+
+	if !storage.running {
+		return nil
+	}
+
+	if err := resource.Start(ctx, name, storage, storage.events); err != nil {
+		storage.mutex.Lock()
+		delete(storage.memory, name)
+		storage.mutex.Unlock()
+
+		return fmt.Errorf("%w: %s: %w", ErrStart, name, err)
+	}
+
+	storage.refresh()
+	storage.events.Emit(event.Added, event.LifecyclePayload{Resource: name})
+
+	return nil
+}
+
+func (storage *Storage) Remove(ctx context.Context, name string, force bool) error {
+	storage.lifecycle.Lock()
+	defer storage.lifecycle.Unlock()
+
+	resource, ok := storage.Lookup(name)
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrResourceNotFound, name)
+	}
+
+	if dependents := storage.Dependents(name); len(dependents) > 0 && !force {
+		return fmt.Errorf("%w: %s is used by %s", ErrResourceInUse, name, strings.Join(dependents, ", "))
+	}
+
+	storage.mutex.Lock()
+	delete(storage.memory, name)
+	delete(storage.metadata, name)
+	storage.mutex.Unlock()
+
+	if !storage.running {
+		return nil
+	}
+
+	storage.refresh()
+	storage.events.Emit(event.Removed, event.LifecyclePayload{Resource: name})
+
+	if err := resource.Stop(ctx); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrStop, name, err)
+	}
+
+	return nil
+}
+
+func (storage *Storage) Replace(ctx context.Context, name string, resource Resource) error {
+	storage.lifecycle.Lock()
+	defer storage.lifecycle.Unlock()
+
+	previous, ok := storage.Lookup(name)
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrResourceNotFound, name)
+	}
+
+	if !storage.running {
+		storage.mutex.Lock()
+		storage.memory[name] = resource
+		storage.mutex.Unlock()
+
+		return nil
+	}
+
+	if err := storage.propose(map[string]Resource{name: resource}).validate([]string{name}); err != nil {
+		return err
+	}
+
+	storage.mutex.Lock()
+	delete(storage.memory, name)
+	storage.mutex.Unlock()
+
+	storage.refresh()
+
+	if err := previous.Stop(ctx); err != nil {
+		storage.restore(name, previous)
+
+		return fmt.Errorf("%w: %s: %w", ErrStop, name, err)
+	}
+
+	storage.mutex.Lock()
+	storage.memory[name] = resource
+	storage.mutex.Unlock()
+
+	if err := resource.Start(ctx, name, storage, storage.events); err != nil {
+		storage.restore(name, previous)
+
+		return errors.Join(
+			fmt.Errorf("%w: %s: %w", ErrStart, name, err),
+			previous.Start(context.WithoutCancel(ctx), name, storage, storage.events),
+		)
+	}
+
+	storage.refresh()
+	storage.events.Emit(event.Replaced, event.LifecyclePayload{Resource: name})
+
+	return nil
+}
+
+func (storage *Storage) Dependents(name string) []string {
+	var dependents []string
+
+	for _, candidate := range storage.Names() {
+		resource, _ := storage.Lookup(candidate)
+
+		dependent, ok := resource.(Dependent)
+
+		if !ok || candidate == name {
+			continue
+		}
+
+		for _, dependency := range dependent.Dependencies() {
+			if Resolve(candidate, dependency) == name {
+				dependents = append(dependents, candidate)
+				break
+			}
+		}
+	}
+
+	return dependents
+}
+
+func (storage *Storage) restore(name string, resource Resource) {
+	storage.mutex.Lock()
+	storage.memory[name] = resource
+	storage.mutex.Unlock()
+
+	storage.refresh()
+}
+
+func (storage *Storage) refresh() {
+	if storage.propagator != nil {
+		storage.propagator.refresh()
+	}
+}
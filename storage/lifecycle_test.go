@@ -0,0 +1,225 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/storage"
+)
+
+func startLifecycle(t *testing.T, memory map[string]storage.Resource) (*storage.Storage, chan any) {
+	t.Helper()
+
+	store := storage.NewStorage(memory)
+	events := event.NewEvents(time.Second)
+	lifecycle := make(chan any, 16)
+
+	for _, name := range []event.Event{event.Added, event.Removed, event.Replaced} {
+		events.SubscribeWith(name, lifecycle, event.Block, 16)
+	}
+
+	if err := store.Start(context.Background(), events, clock.NewManual(time.Time{}), 1); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	t.Cleanup(func() {
+		store.Stop(context.Background())
+	})
+
+	return store, lifecycle
+}
+
+func expectLifecycle(t *testing.T, lifecycle chan any, resource string) {
+	t.Helper()
+
+	select {
+	case payload := <-lifecycle:
+		if payload.(event.LifecyclePayload).Resource != resource {
+			t.Fatalf("expected a lifecycle event for %s, got %v", resource, payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for a lifecycle event for %s", resource)
+	}
+}
+
+func expectQuiet(t *testing.T, lifecycle chan any) {
+	t.Helper()
+
+	select {
+	case payload := <-lifecycle:
+		t.Fatalf("expected no lifecycle event, got %v", payload)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestAdd(t *testing.T) {
+	store, lifecycle := startLifecycle(t, map[string]storage.Resource{"level": &probe{}})
+	added := &probe{dependencies: []string{"level"}}
+
+	if err := store.Add(context.Background(), "alarm", added); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	if !added.started {
+		t.Fatal("expected the added resource to start")
+	}
+
+	expectLifecycle(t, lifecycle, "alarm")
+
+	if err := store.Add(context.Background(), "alarm", &probe{}); !errors.Is(err, storage.ErrResourceExists) {
+		t.Fatalf("expected %v, got %v", storage.ErrResourceExists, err)
+	}
+}
+
+func TestAddRejectsInvalidGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource *probe
+		err      error
+	}{
+		{name: "pump", resource: &probe{dependencies: []string{"missing"}}, err: storage.ErrMissingReference},
+		{name: "self", resource: &probe{dependencies: []string{"self"}}, err: storage.ErrCycle},
+		{name: "level", resource: &probe{dependencies: []string{"alarm"}}, err: storage.ErrCycle},
+	}
+
+	for _, test := range tests {
+		store, lifecycle := startLifecycle(t, map[string]storage.Resource{
+			"level": &probe{},
+			"alarm": &probe{dependencies: []string{"level"}},
+		})
+
+		if err := store.Remove(context.Background(), "level", true); err != nil {
+			t.Fatalf("forced remove: %v", err)
+		}
+
+		expectLifecycle(t, lifecycle, "level")
+
+		if err := store.Add(context.Background(), test.name, test.resource); !errors.Is(err, test.err) {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+
+		if test.resource.started || slices.Contains(store.Names(), test.name) {
+			t.Fatalf("%s: expected the rejected resource not to be added", test.name)
+		}
+
+		expectQuiet(t, lifecycle)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	level := &probe{}
+	store, lifecycle := startLifecycle(t, map[string]storage.Resource{
+		"level": level,
+		"alarm": &probe{dependencies: []string{"level"}},
+	})
+
+	if err := store.Remove(context.Background(), "level", false); !errors.Is(err, storage.ErrResourceInUse) {
+		t.Fatalf("expected %v, got %v", storage.ErrResourceInUse, err)
+	}
+
+	if err := store.Remove(context.Background(), "missing", false); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrResourceNotFound, err)
+	}
+
+	if err := store.Remove(context.Background(), "alarm", false); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	expectLifecycle(t, lifecycle, "alarm")
+
+	if err := store.Remove(context.Background(), "level", false); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	if !level.stopped || len(store.Names()) != 0 {
+		t.Fatalf("expected the removed resources to stop and leave, got %v", store.Names())
+	}
+
+	expectLifecycle(t, lifecycle, "level")
+}
+
+func TestForcedRemove(t *testing.T) {
+	level := &probe{}
+	store, lifecycle := startLifecycle(t, map[string]storage.Resource{
+		"level": level,
+		"alarm": &probe{dependencies: []string{"level"}},
+	})
+
+	if err := store.Remove(context.Background(), "level", true); err != nil {
+		t.Fatalf("forced remove: %v", err)
+	}
+
+	if !level.stopped || !slices.Equal(store.Names(), []string{"alarm"}) {
+		t.Fatalf("expected only the dependent to remain, got %v", store.Names())
+	}
+
+	expectLifecycle(t, lifecycle, "level")
+
+	if err := store.Add(context.Background(), "pump", &probe{}); err != nil {
+		t.Fatalf("expected an unrelated dangling reference not to block adds, got %v", err)
+	}
+
+	expectLifecycle(t, lifecycle, "pump")
+
+	if err := store.Add(context.Background(), "level", &probe{}); err != nil {
+		t.Fatalf("expected the removed resource to be added back, got %v", err)
+	}
+
+	expectLifecycle(t, lifecycle, "level")
+}
+
+func TestReplace(t *testing.T) {
+	previous := &probe{}
+	store, lifecycle := startLifecycle(t, map[string]storage.Resource{
+		"level": previous,
+		"alarm": &probe{dependencies: []string{"level"}},
+	})
+	replacement := &probe{}
+
+	if err := store.Replace(context.Background(), "level", replacement); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	if !previous.stopped || !replacement.started {
+		t.Fatal("expected the previous resource to stop and the replacement to start")
+	}
+
+	expectLifecycle(t, lifecycle, "level")
+
+	if err := store.Replace(context.Background(), "missing", &probe{}); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected %v, got %v", storage.ErrResourceNotFound, err)
+	}
+}
+
+func TestReplaceRejectsInvalidGraph(t *testing.T) {
+	tests := []struct {
+		resource *probe
+		err      error
+	}{
+		{resource: &probe{dependencies: []string{"missing"}}, err: storage.ErrMissingReference},
+		{resource: &probe{dependencies: []string{"alarm"}}, err: storage.ErrCycle},
+	}
+
+	for _, test := range tests {
+		previous := &probe{}
+		store, lifecycle := startLifecycle(t, map[string]storage.Resource{
+			"level": previous,
+			"alarm": &probe{dependencies: []string{"level"}},
+		})
+
+		if err := store.Replace(context.Background(), "level", test.resource); !errors.Is(err, test.err) {
+			t.Fatalf("expected %v, got %v", test.err, err)
+		}
+
+		if current, _ := store.Lookup("level"); current != previous || previous.stopped || test.resource.started {
+			t.Fatal("expected the previous resource to keep running")
+		}
+
+		expectQuiet(t, lifecycle)
+	}
+}
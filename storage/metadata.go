@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Access int
+
+type Range struct {
+	Min float64 `json:"min" yaml:"min"`
+	Max float64 `json:"max" yaml:"max"`
+}
+
+type Metadata struct {
+	Description string `json:"description,omitempty"`
+	Unit        string `json:"unit,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Range       *Range `json:"range,omitempty"`
+	Raw         *Range `json:"raw,omitempty"`
+	Access      Access `json:"access"`
+}
+
+type Describer interface {
+	Metadata() Metadata
+}
+
+type RangeError struct {
+	Resource string
+	Value    float64
+	Range    Range
+}
+
+const (
+	AccessDefault   Access = 0
+	AccessRead      Access = 1
+	AccessWrite     Access = 2
+	AccessReadWrite Access = AccessRead | AccessWrite
+)
+
+var (
+	ErrOutOfRange    = errors.New("value out of range")
+	ErrUnknownAccess = errors.New("unknown access level")
+)
+
+func ParseAccess(name string) (Access, error) {
+	switch name {
+	case "read":
+		return AccessRead, nil
+	case "write":
+		return AccessWrite, nil
+	case "read_write":
+		return AccessReadWrite, nil
+	}
+
+	return AccessDefault, fmt.Errorf("%w: %s", ErrUnknownAccess, name)
+}
+
+func (access Access) Readable() bool {
+	return access == AccessDefault || access&AccessRead != 0
+}
+
+func (access Access) Writable() bool {
+	return access == AccessDefault || access&AccessWrite != 0
+}
+
+func (access Access) String() string {
+	switch access {
+	case AccessRead:
+		return "read"
+	case AccessWrite:
+		return "write"
+	case AccessReadWrite:
+		return "read_write"
+	}
+
+	return "default"
+}
+
+func (access Access) MarshalText() ([]byte, error) {
+	return []byte(access.String()), nil
+}
+
+func (metadata Metadata) Check(resource string, value any) error {
+	if metadata.Range == nil {
+		return nil
+	}
+
+	var number float64
+
+	switch v := value.(type) {
+	case int32:
+		number = float64(v)
+	case float32:
+		number = float64(v)
+	default:
+		return nil
+	}
+
+	if number < metadata.Range.Min || number > metadata.Range.Max {
+		return &RangeError{Resource: resource, Value: number, Range: *metadata.Range}
+	}
+
+	return nil
+}
+
+func (metadata Metadata) Scale(raw float64) float64 {
+	if metadata.Range == nil || metadata.Raw == nil || metadata.Raw.Max == metadata.Raw.Min {
+		return raw
+	}
+
+	return metadata.Range.Min + (raw-metadata.Raw.Min)*(metadata.Range.Max-metadata.Range.Min)/(metadata.Raw.Max-metadata.Raw.Min)
+}
+
+func (metadata Metadata) Unscale(engineering float64) float64 {
+	if metadata.Range == nil || metadata.Raw == nil || metadata.Range.Max == metadata.Range.Min {
+		return engineering
+	}
+
+	return metadata.Raw.Min + (engineering-metadata.Range.Min)*(metadata.Raw.Max-metadata.Raw.Min)/(metadata.Range.Max-metadata.Range.Min)
+}
+
+func (err *RangeError) Error() string {
+	return fmt.Sprintf("%s: %s: %g not in [%g, %g]", ErrOutOfRange, err.Resource, err.Value, err.Range.Min, err.Range.Max)
+}
+
+func (err *RangeError) Unwrap() error {
+	return ErrOutOfRange
+}
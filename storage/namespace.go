@@ -0,0 +1,315 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/studiolambda/immersim/event"
+)
+
+type Rewritable interface {
+	Rewrite(rewrite func(reference string) string)
+}
+
+type Entry struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Folder bool   `json:"folder"`
+}
+
+const Separator = "/"
+
+var ErrInvalidName = errors.New("invalid resource name")
+
+func Join(parts ...string) string {
+	segments := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		if part = strings.Trim(part, Separator); part != "" {
+			segments = append(segments, part)
+		}
+	}
+
+	return strings.Join(segments, Separator)
+}
+
+func Dir(name string) string {
+	if index := strings.LastIndex(name, Separator); index >= 0 {
+		return name[:index]
+	}
+
+	return ""
+}
+
+func Base(name string) string {
+	return name[strings.LastIndex(name, Separator)+1:]
+}
+
+func Resolve(name string, reference string) string {
+	if strings.HasPrefix(reference, Separator) {
+		return strings.TrimPrefix(reference, Separator)
+	}
+
+	if reference != "." && reference != ".." && !strings.HasPrefix(reference, "./") && !strings.HasPrefix(reference, "../") {
+		return reference
+	}
+
+	resolved := path.Join(Dir(name), reference)
+
+	if resolved == "." || resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return reference
+	}
+
+	return resolved
+}
+
+func CheckName(name string) error {
+	if name == "" || strings.ContainsAny(name, ":*") || strings.HasPrefix(name, "@") {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	for _, segment := range strings.Split(name, Separator) {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidName, name)
+		}
+	}
+
+	return nil
+}
+
+func (storage *Storage) Resolve(name string, reference string) string {
+	return Resolve(name, reference)
+}
+
+func (storage *Storage) Browse(folder string) []Entry {
+	folder = strings.Trim(folder, Separator)
+	entries := []Entry{}
+	seen := make(map[string]bool)
+
+	for _, name := range storage.Names() {
+		relative := name
+
+		if folder != "" {
+			if !strings.HasPrefix(name, folder+Separator) {
+				continue
+			}
+
+			relative = name[len(folder)+1:]
+		}
+
+		child, _, nested := strings.Cut(relative, Separator)
+
+		if seen[child] {
+			continue
+		}
+
+		seen[child] = true
+		entries = append(entries, Entry{Name: child, Path: Join(folder, child), Folder: nested})
+	}
+
+	return entries
+}
+
+func (storage *Storage) Folders() []string {
+	var folders []string
+
+	for _, name := range storage.Names() {
+		for folder := Dir(name); folder != ""; folder = Dir(folder) {
+			folders = append(folders, folder)
+		}
+	}
+
+	slices.Sort(folders)
+
+	return slices.Compact(folders)
+}
+
+func (storage *Storage) Mount(ctx context.Context, prefix string, mounted *Storage) error {
+	prefix = strings.Trim(prefix, Separator)
+
+	if err := CheckName(prefix); err != nil {
+		return err
+	}
+
+	if mounted.Running() {
+		return ErrRunning
+	}
+
+	names := mounted.Names()
+
+	for _, name := range names {
+		if err := CheckName(Join(prefix, name)); err != nil {
+			return err
+		}
+	}
+
+	storage.lifecycle.Lock()
+	defer storage.lifecycle.Unlock()
+
+	storage.mutex.RLock()
+
+	for _, name := range names {
+		if _, ok := storage.memory[Join(prefix, name)]; ok {
+			storage.mutex.RUnlock()
+			return fmt.Errorf("%w: %s", ErrResourceExists, Join(prefix, name))
+		}
+	}
+
+	storage.mutex.RUnlock()
+
+	resources, metadata := mounted.take(names)
+	rewritten := make(map[string]map[string]string, len(resources))
+
+	for name, resource := range resources {
+		if rewritable, ok := resource.(Rewritable); ok {
+			rewritten[name] = make(map[string]string)
+			rewritable.Rewrite(func(reference string) string {
+				if _, found := slices.BinarySearch(names, strings.TrimPrefix(reference, Separator)); found {
+					rewritten[name][Join(prefix, reference)] = reference
+					return Join(prefix, reference)
+				}
+
+				return reference
+			})
+		}
+	}
+
+	restore := func() {
+		for name, resource := range resources {
+			if rewritable, ok := resource.(Rewritable); ok {
+				rewritable.Rewrite(func(reference string) string {
+					if original, ok := rewritten[name][reference]; ok {
+						return original
+					}
+
+					return reference
+				})
+			}
+		}
+
+		mounted.give(resources, metadata)
+	}
+
+	added := make(map[string]Resource, len(names))
+	prefixed := make([]string, 0, len(names))
+
+	for _, name := range names {
+		added[Join(prefix, name)] = resources[name]
+		prefixed = append(prefixed, Join(prefix, name))
+	}
+
+	if storage.running {
+		if err := storage.propose(added).validate(prefixed); err != nil {
+			restore()
+			return err
+		}
+	}
+
+	storage.mutex.Lock()
+
+	if storage.memory == nil {
+		storage.memory = make(map[string]Resource)
+	}
+
+	maps.Copy(storage.memory, added)
+
+	for name, described := range metadata {
+		storage.metadata[Join(prefix, name)] = described
+	}
+
+	storage.mutex.Unlock()
+
+	if !storage.running {
+		return nil
+	}
+
+	started := make([]string, 0, len(added))
+
+	for _, name := range storage.Graph().Order() {
+		resource, ok := added[name]
+
+		if !ok {
+			continue
+		}
+
+		if err := resource.Start(ctx, name, storage, storage.events); err != nil {
+			err = errors.Join(
+				fmt.Errorf("%w: %s: %w", ErrStart, name, err),
+				storage.unmount(context.WithoutCancel(ctx), added, started),
+			)
+			restore()
+
+			return err
+		}
+
+		started = append(started, name)
+	}
+
+	storage.refresh()
+
+	for _, name := range started {
+		storage.events.Emit(event.Added, event.LifecyclePayload{Resource: name})
+	}
+
+	return nil
+}
+
+func (storage *Storage) take(names []string) (map[string]Resource, map[string]Metadata) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	resources := make(map[string]Resource, len(names))
+	metadata := make(map[string]Metadata)
+
+	for _, name := range names {
+		resources[name] = storage.memory[name]
+		delete(storage.memory, name)
+
+		if described, ok := storage.metadata[name]; ok {
+			metadata[name] = described
+			delete(storage.metadata, name)
+		}
+	}
+
+	return resources, metadata
+}
+
+func (storage *Storage) give(resources map[string]Resource, metadata map[string]Metadata) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if storage.memory == nil {
+		storage.memory = make(map[string]Resource)
+	}
+
+	maps.Copy(storage.memory, resources)
+	maps.Copy(storage.metadata, metadata)
+}
+
+func (storage *Storage) unmount(ctx context.Context, added map[string]Resource, started []string) error {
+	storage.mutex.Lock()
+
+	for name := range added {
+		delete(storage.memory, name)
+		delete(storage.metadata, name)
+	}
+
+	storage.mutex.Unlock()
+
+	storage.refresh()
+
+	var errs []error
+
+	for i := len(started) - 1; i >= 0; i-- {
+		if err := added[started[i]].Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrStop, started[i], err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
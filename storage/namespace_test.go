@@ -0,0 +1,291 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/clock"
+	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/resource"
+	"github.com/studiolambda/immersim/storage"
+)
+
+type probe struct {
+	dependencies []string
+	err          error
+	started      bool
+	stopped      bool
+}
+
+var errBoom = errors.New("boom")
+
+func (probe *probe) Start(ctx context.Context, name string, storage *storage.Storage, events *event.Events) error {
+	if probe.err != nil {
+		return probe.err
+	}
+
+	probe.started = true
+
+	return nil
+}
+
+func (probe *probe) Stop(ctx context.Context) error {
+	probe.stopped = true
+
+	return nil
+}
+
+func (probe *probe) Dependencies() []string {
+	return probe.dependencies
+}
+
+func (probe *probe) Rewrite(rewrite func(reference string) string) {
+	for i, dependency := range probe.dependencies {
+		probe.dependencies[i] = rewrite(dependency)
+	}
+}
+
+func startStorage(t *testing.T, memory map[string]storage.Resource) *storage.Storage {
+	t.Helper()
+
+	store := storage.NewStorage(memory)
+
+	if err := store.Start(context.Background(), event.NewEvents(time.Second), clock.NewReal(), 1); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	t.Cleanup(func() {
+		store.Stop(context.Background())
+	})
+
+	return store
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference string
+		resolved  string
+	}{
+		{name: "plant/tank/alarm", reference: "./level", resolved: "plant/tank/level"},
+		{name: "plant/tank/alarm", reference: "../pump/speed", resolved: "plant/pump/speed"},
+		{name: "plant/tank/alarm", reference: "/setpoint", resolved: "setpoint"},
+		{name: "plant/tank/alarm", reference: "setpoint", resolved: "setpoint"},
+		{name: "alarm", reference: "./level", resolved: "level"},
+		{name: "alarm", reference: "../level", resolved: "../level"},
+		{name: "alarm", reference: ".", resolved: "."},
+		{name: "tank/alarm", reference: ".", resolved: "tank"},
+		{name: "alarm", reference: "./.level", resolved: ".level"},
+	}
+
+	for _, test := range tests {
+		if resolved := storage.Resolve(test.name, test.reference); resolved != test.resolved {
+			t.Fatalf("%s from %s: expected %q, got %q", test.reference, test.name, test.resolved, resolved)
+		}
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	for _, name := range []string{"", "tank//level", "tank/./level", "tank/../level", "tank:fill", "tank/*", "@added"} {
+		if err := storage.CheckName(name); !errors.Is(err, storage.ErrInvalidName) {
+			t.Fatalf("%q: expected %v, got %v", name, storage.ErrInvalidName, err)
+		}
+	}
+
+	if err := storage.CheckName("plant/tank/level"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestMountRewritesReferences(t *testing.T) {
+	store := startStorage(t, map[string]storage.Resource{
+		"setpoint": resource.NewStatic[float32](10),
+	})
+
+	alarm, err := resource.NewExpression[bool]("level > setpoint")
+
+	if err != nil {
+		t.Fatalf("expression: %v", err)
+	}
+
+	mounted := storage.NewStorage(map[string]storage.Resource{
+		"alarm": alarm,
+		"level": resource.NewStatic[float32](12),
+	})
+	mounted.Annotate("level", storage.Metadata{Unit: "m"})
+
+	if err := store.Mount(context.Background(), "tank", mounted); err != nil {
+		t.Fatalf("mount: %v", err)
+	}
+
+	if dependencies := alarm.Dependencies(); !slices.Contains(dependencies, "tank/level") || !slices.Contains(dependencies, "setpoint") {
+		t.Fatalf("expected level to be rewritten, got %v", dependencies)
+	}
+
+	if value, err := store.Read("tank/alarm"); err != nil || value != true {
+		t.Fatalf("expected the mounted alarm to be raised, got %v, %v", value, err)
+	}
+
+	if metadata, ok := store.Metadata("tank/level"); !ok || metadata.Unit != "m" {
+		t.Fatalf("expected metadata to be mounted, got %+v", metadata)
+	}
+}
+
+func TestMountRollback(t *testing.T) {
+	store := startStorage(t, map[string]storage.Resource{
+		"existing": resource.NewStatic[int32](1),
+	})
+
+	started := &probe{}
+	failing := &probe{dependencies: []string{"./started"}, err: errBoom}
+	mounted := storage.NewStorage(map[string]storage.Resource{
+		"started": started,
+		"failing": failing,
+	})
+	mounted.Annotate("started", storage.Metadata{Unit: "m"})
+
+	err := store.Mount(context.Background(), "tank", mounted)
+
+	if !errors.Is(err, storage.ErrStart) || !errors.Is(err, errBoom) {
+		t.Fatalf("expected a start error, got %v", err)
+	}
+
+	if !started.started || !started.stopped {
+		t.Fatalf("expected the started resource to be stopped again, got %+v", started)
+	}
+
+	if names := store.Names(); len(names) != 1 || names[0] != "existing" {
+		t.Fatalf("expected only the existing resource to remain, got %v", names)
+	}
+
+	if _, ok := store.Metadata("tank/started"); ok {
+		t.Fatal("expected the mounted metadata to be removed")
+	}
+
+	if names := mounted.Names(); !slices.Equal(names, []string{"failing", "started"}) {
+		t.Fatalf("expected the resources to return to their storage, got %v", names)
+	}
+
+	if metadata, ok := mounted.Metadata("started"); !ok || metadata.Unit != "m" {
+		t.Fatalf("expected the metadata to return to its storage, got %+v", metadata)
+	}
+}
+
+func TestMountRetryAfterRollback(t *testing.T) {
+	store := startStorage(t, nil)
+	alarm := &probe{dependencies: []string{"level"}, err: errBoom}
+	mounted := storage.NewStorage(map[string]storage.Resource{
+		"level":      &probe{},
+		"tank/level": &probe{},
+		"alarm":      alarm,
+	})
+
+	if err := store.Mount(context.Background(), "tank", mounted); !errors.Is(err, errBoom) {
+		t.Fatalf("expected a start error, got %v", err)
+	}
+
+	if !slices.Equal(alarm.dependencies, []string{"level"}) {
+		t.Fatalf("expected the rollback to undo the rewrite, got %v", alarm.dependencies)
+	}
+
+	alarm.err = nil
+
+	if err := store.Mount(context.Background(), "tank", mounted); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	if !slices.Equal(alarm.dependencies, []string{"tank/level"}) {
+		t.Fatalf("expected a single prefix after the retry, got %v", alarm.dependencies)
+	}
+}
+
+func TestMountTakesOwnership(t *testing.T) {
+	store := startStorage(t, nil)
+	level := resource.NewStatic[int32](1)
+	mounted := storage.NewStorage(map[string]storage.Resource{"level": level})
+	mounted.Annotate("level", storage.Metadata{Unit: "m"})
+
+	if err := store.Mount(context.Background(), "tank", mounted); err != nil {
+		t.Fatalf("mount: %v", err)
+	}
+
+	if names := mounted.Names(); len(names) != 0 {
+		t.Fatalf("expected the mounted storage to give up its resources, got %v", names)
+	}
+
+	if _, ok := mounted.Metadata("level"); ok {
+		t.Fatal("expected the mounted storage to give up its metadata")
+	}
+
+	if found, _ := store.Lookup("tank/level"); found != level {
+		t.Fatal("expected the resource to move to the target storage")
+	}
+}
+
+func TestMountValidatesBeforeStarting(t *testing.T) {
+	tests := []struct {
+		dependencies []string
+		err          error
+	}{
+		{dependencies: []string{"missing"}, err: storage.ErrMissingReference},
+		{dependencies: []string{"."}, err: storage.ErrMissingReference},
+		{dependencies: []string{"alarm"}, err: storage.ErrCycle},
+	}
+
+	for _, test := range tests {
+		store := startStorage(t, nil)
+		level := &probe{}
+		alarm := &probe{dependencies: test.dependencies}
+		mounted := storage.NewStorage(map[string]storage.Resource{"level": level, "alarm": alarm})
+
+		if err := store.Mount(context.Background(), "tank", mounted); !errors.Is(err, test.err) {
+			t.Fatalf("%v: expected %v, got %v", test.dependencies, test.err, err)
+		}
+
+		if level.started || alarm.started || len(store.Names()) != 0 || len(mounted.Names()) != 2 {
+			t.Fatalf("%v: expected nothing to start or move", test.dependencies)
+		}
+
+		if !slices.Equal(alarm.dependencies, test.dependencies) {
+			t.Fatalf("%v: expected the rewrite to be undone, got %v", test.dependencies, alarm.dependencies)
+		}
+	}
+}
+
+func TestMountRejectsDuplicates(t *testing.T) {
+	store := startStorage(t, map[string]storage.Resource{
+		"tank/level": resource.NewStatic[int32](1),
+	})
+
+	started := &probe{}
+	mounted := storage.NewStorage(map[string]storage.Resource{
+		"started": started,
+		"level":   resource.NewStatic[int32](2),
+	})
+
+	if err := store.Mount(context.Background(), "tank", mounted); !errors.Is(err, storage.ErrResourceExists) {
+		t.Fatalf("expected %v, got %v", storage.ErrResourceExists, err)
+	}
+
+	if _, ok := store.Lookup("tank/started"); ok || started.started {
+		t.Fatal("expected nothing to be mounted")
+	}
+
+	if value, _ := store.Read("tank/level"); value != int32(1) {
+		t.Fatalf("expected the existing resource to be kept, got %v", value)
+	}
+}
+
+func TestMountRunningStorage(t *testing.T) {
+	store := startStorage(t, nil)
+	mounted := startStorage(t, map[string]storage.Resource{
+		"level": resource.NewStatic[int32](1),
+	})
+
+	if err := store.Mount(context.Background(), "tank", mounted); !errors.Is(err, storage.ErrRunning) {
+		t.Fatalf("expected %v, got %v", storage.ErrRunning, err)
+	}
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/studiolambda/immersim/event"
+)
+
+type Propagation int
+
+type Derived interface {
+	Recompute() (event.ChangedPayload, bool)
+}
+
+type propagator struct {
+	storage  *Storage
+	events   *event.Events
+	order    []string
+	sources  []string
+	written  map[string]Sample
+	listener chan any
+	mutex    sync.Mutex
+	wg       sync.WaitGroup
+}
+
+const (
+	PropagateAsync Propagation = iota
+	PropagateTopological
+)
+
+func (storage *Storage) SetPropagation(propagation Propagation) {
+	storage.lifecycle.Lock()
+	defer storage.lifecycle.Unlock()
+
+	storage.propagation = propagation
+}
+
+func (storage *Storage) Propagation() Propagation {
+	return storage.propagation
+}
+
+func (storage *Storage) Topological() bool {
+	return storage.propagation == PropagateTopological
+}
+
+func newPropagator(storage *Storage, events *event.Events) *propagator {
+	return &propagator{
+		storage:  storage,
+		events:   events,
+		order:    nil,
+		sources:  nil,
+		written:  make(map[string]Sample),
+		listener: nil,
+		mutex:    sync.Mutex{},
+		wg:       sync.WaitGroup{},
+	}
+}
+
+func (propagator *propagator) start() {
+	propagator.listener = make(chan any, 64)
+	propagator.refresh()
+	propagator.pass(nil)
+
+	propagator.wg.Add(1)
+	go propagator.loop()
+}
+
+func (propagator *propagator) stop() {
+	propagator.mutex.Lock()
+
+	for _, source := range propagator.sources {
+		propagator.events.Unsubscribe(event.Changed(source), propagator.listener)
+	}
+
+	propagator.order = nil
+	propagator.sources = nil
+	propagator.mutex.Unlock()
+
+	close(propagator.listener)
+	propagator.wg.Wait()
+
+	propagator.listener = nil
+}
+
+func (propagator *propagator) refresh() {
+	propagator.mutex.Lock()
+	defer propagator.mutex.Unlock()
+
+	for _, source := range propagator.sources {
+		propagator.events.Unsubscribe(event.Changed(source), propagator.listener)
+	}
+
+	propagator.order = nil
+	propagator.sources = nil
+
+	for _, name := range propagator.storage.Graph().Order() {
+		resource, _ := propagator.storage.Lookup(name)
+
+		if _, ok := resource.(Derived); ok {
+			propagator.order = append(propagator.order, name)
+			continue
+		}
+
+		propagator.sources = append(propagator.sources, name)
+		propagator.events.Subscribe(event.Changed(name), propagator.listener)
+	}
+}
+
+func (propagator *propagator) loop() {
+	defer propagator.wg.Done()
+
+	for payload := range propagator.listener {
+		changed := make(map[string]bool)
+		propagator.collect(changed, payload)
+
+		for drained := false; !drained; {
+			select {
+			case payload, ok := <-propagator.listener:
+				if !ok {
+					drained = true
+					continue
+				}
+
+				propagator.collect(changed, payload)
+			default:
+				drained = true
+			}
+		}
+
+		propagator.pass(changed)
+	}
+}
+
+func (propagator *propagator) collect(changed map[string]bool, payload any) {
+	changedPayload, ok := payload.(event.ChangedPayload)
+
+	if !ok {
+		return
+	}
+
+	propagator.mutex.Lock()
+	defer propagator.mutex.Unlock()
+
+	if written, ok := propagator.written[changedPayload.Resource]; ok {
+		delete(propagator.written, changedPayload.Resource)
+
+		if written.Value == changedPayload.Value && written.SourceTimestamp.Equal(changedPayload.SourceTimestamp) {
+			return
+		}
+	}
+
+	changed[changedPayload.Resource] = true
+}
+
+func (propagator *propagator) write(resource string) {
+	if sample, err := propagator.storage.ReadSample(resource); err == nil {
+		propagator.mutex.Lock()
+		propagator.written[resource] = sample
+		propagator.mutex.Unlock()
+	}
+
+	propagator.pass(map[string]bool{resource: true})
+}
+
+func (propagator *propagator) pass(changed map[string]bool) {
+	var emitted []event.ChangedPayload
+
+	propagator.mutex.Lock()
+
+	for _, name := range propagator.order {
+		resource, ok := propagator.storage.Lookup(name)
+
+		if !ok {
+			continue
+		}
+
+		if changed != nil && !propagator.affected(name, resource, changed) {
+			continue
+		}
+
+		payload, recomputed := resource.(Derived).Recompute()
+
+		if !recomputed {
+			continue
+		}
+
+		emitted = append(emitted, payload)
+
+		if changed != nil {
+			changed[name] = true
+		}
+	}
+
+	propagator.mutex.Unlock()
+
+	for _, payload := range emitted {
+		propagator.events.Emit(event.Changed(payload.Resource), payload)
+	}
+}
+
+func (propagator *propagator) affected(name string, resource Resource, changed map[string]bool) bool {
+	dependent, ok := resource.(Dependent)
+
+	if !ok {
+		return false
+	}
+
+	for _, dependency := range dependent.Dependencies() {
+		if changed[Resolve(name, dependency)] {
+			return true
+		}
+	}
+
+	return false
+}
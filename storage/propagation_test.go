@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/immersim/event"
+)
+
+type source struct {
+	value int32
+}
+
+type derived struct {
+	name         string
+	storage      *Storage
+	dependencies []string
+	compute      func(values []int32) int32
+	value        int32
+	recomputed   int
+}
+
+func (source *source) Start(ctx context.Context, name string, storage *Storage, events *event.Events) error {
+	return nil
+}
+
+func (source *source) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (source *source) Read() (any, error) {
+	return source.value, nil
+}
+
+func (derived *derived) Start(ctx context.Context, name string, storage *Storage, events *event.Events) error {
+	return nil
+}
+
+func (derived *derived) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (derived *derived) Read() (any, error) {
+	return derived.value, nil
+}
+
+func (derived *derived) Dependencies() []string {
+	return derived.dependencies
+}
+
+func (derived *derived) Recompute() (event.ChangedPayload, bool) {
+	values := make([]int32, 0, len(derived.dependencies))
+
+	for _, dependency := range derived.dependencies {
+		value, _ := derived.storage.Read(Resolve(derived.name, dependency))
+		values = append(values, value.(int32))
+	}
+
+	derived.recomputed++
+	previous := derived.value
+	derived.value = derived.compute(values)
+
+	return event.ChangedPayload{Resource: derived.name, Value: derived.value}, derived.value != previous
+}
+
+func diamond() (*Storage, *source, *derived, *derived) {
+	input := &source{value: 1}
+	double := &derived{name: "double", dependencies: []string{"input"}, compute: func(values []int32) int32 { return values[0] * 2 }}
+	sum := &derived{name: "sum", dependencies: []string{"input", "double"}, compute: func(values []int32) int32 { return values[0] + values[1] }}
+	storage := NewStorage(map[string]Resource{"input": input, "sum": sum, "double": double})
+	double.storage = storage
+	sum.storage = storage
+
+	return storage, input, double, sum
+}
+
+func TestPassRecomputesInTopologicalOrder(t *testing.T) {
+	storage, input, double, sum := diamond()
+	propagator := newPropagator(storage, event.NewEvents(time.Second))
+	propagator.refresh()
+	propagator.pass(nil)
+
+	if double.value != 2 || sum.value != 3 {
+		t.Fatalf("expected the initial pass to settle at 2 and 3, got %d and %d", double.value, sum.value)
+	}
+
+	input.value = 2
+	double.recomputed, sum.recomputed = 0, 0
+	propagator.pass(map[string]bool{"input": true})
+
+	if double.value != 4 || sum.value != 6 {
+		t.Fatalf("expected 4 and 6, got %d and %d", double.value, sum.value)
+	}
+
+	if double.recomputed != 1 || sum.recomputed != 1 {
+		t.Fatalf("expected a single recomputation each, got %d and %d", double.recomputed, sum.recomputed)
+	}
+}
+
+func TestPassSkipsUnaffectedResources(t *testing.T) {
+	storage, _, double, sum := diamond()
+	propagator := newPropagator(storage, event.NewEvents(time.Second))
+	propagator.refresh()
+	propagator.pass(nil)
+
+	double.recomputed, sum.recomputed = 0, 0
+	propagator.pass(map[string]bool{"other": true})
+
+	if double.recomputed != 0 || sum.recomputed != 0 {
+		t.Fatalf("expected no recomputation, got %d and %d", double.recomputed, sum.recomputed)
+	}
+}
+
+func TestPassResolvesRelativeDependencies(t *testing.T) {
+	input := &source{value: 1}
+	double := &derived{name: "tank/double", dependencies: []string{"./input"}, compute: func(values []int32) int32 { return values[0] * 2 }}
+	sum := &derived{name: "plant/sum", dependencies: []string{"../tank/input", "/tank/double"}, compute: func(values []int32) int32 { return values[0] + values[1] }}
+	storage := NewStorage(map[string]Resource{"tank/input": input, "tank/double": double, "plant/sum": sum})
+	double.storage = storage
+	sum.storage = storage
+
+	propagator := newPropagator(storage, event.NewEvents(time.Second))
+	propagator.refresh()
+	propagator.pass(nil)
+
+	input.value = 2
+	propagator.pass(map[string]bool{"tank/input": true})
+
+	if double.value != 4 || sum.value != 6 {
+		t.Fatalf("expected 4 and 6, got %d and %d", double.value, sum.value)
+	}
+
+	if info, ok := storage.Inspect("plant/sum"); !ok || !slices.Equal(info.Dependencies, []string{"tank/double", "tank/input"}) {
+		t.Fatalf("expected resolved dependencies, got %+v", info.Dependencies)
+	}
+}
+
+func TestPassEmitsAfterReleasingLock(t *testing.T) {
+	storage, input, _, sum := diamond()
+	events := event.NewEvents(time.Second)
+	propagator := newPropagator(storage, events)
+	propagator.refresh()
+	propagator.pass(nil)
+
+	reentered := false
+
+	events.Intercept(func(name event.Event, payload any, deliver func(payload any)) {
+		if !reentered {
+			reentered = true
+			propagator.pass(map[string]bool{"input": true})
+		}
+
+		deliver(payload)
+	})
+
+	done := make(chan struct{})
+
+	go func() {
+		input.value = 2
+		propagator.pass(map[string]bool{"input": true})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the pass to emit without holding its lock")
+	}
+
+	if !reentered || sum.value != 6 {
+		t.Fatalf("expected a reentrant pass and a sum of 6, got %v and %d", reentered, sum.value)
+	}
+}
@@ -1,23 +1,50 @@
 package storage
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/studiolambda/immersim/clock"
 	"github.com/studiolambda/immersim/event"
+	"github.com/studiolambda/immersim/quality"
 )
 
 type Reader interface {
 	Read() (any, error)
 }
 
+type Sample struct {
+	Value           any
+	Quality         quality.Quality
+	SourceTimestamp time.Time
+	ServerTimestamp time.Time
+}
+
+type Sampler interface {
+	Sample() (Sample, error)
+}
+
 type Writer interface {
 	Write(value any) error
 }
 
 type Resource interface {
-	Start(name string, storage *Storage, events *event.Events)
-	Stop()
+	Start(ctx context.Context, name string, storage *Storage, events *event.Events) error
+	Stop(ctx context.Context) error
+}
+
+type Actionable interface {
+	Actions() []string
+}
+
+type Interceptor interface {
+	Intercept(resource string, sample Sample, err error) (Sample, error)
 }
 
 type SupportedNumeric interface {
@@ -29,7 +56,17 @@ type Supported interface {
 }
 
 type Storage struct {
-	memory map[string]Resource
+	memory      map[string]Resource
+	events      *event.Events
+	running     bool
+	clock       clock.Clock
+	seed        uint64
+	interceptor Interceptor
+	metadata    map[string]Metadata
+	propagation Propagation
+	propagator  *propagator
+	mutex       sync.RWMutex
+	lifecycle   sync.Mutex
 }
 
 var (
@@ -37,48 +74,278 @@ var (
 	ErrWrite               = errors.New("failed to write resource")
 	ErrResourceNotReadable = errors.New("resource is not readable")
 	ErrResourceNotWritable = errors.New("resource is not writable")
+	ErrStart               = errors.New("failed to start resource")
+	ErrStop                = errors.New("failed to stop resource")
+	ErrRunning             = errors.New("storage is already running")
 )
 
 func NewStorage(memory map[string]Resource) *Storage {
 	return &Storage{
-		memory: memory,
+		memory:      memory,
+		events:      nil,
+		running:     false,
+		clock:       clock.NewReal(),
+		seed:        0,
+		interceptor: nil,
+		metadata:    make(map[string]Metadata),
+		propagation: PropagateAsync,
+		propagator:  nil,
+		mutex:       sync.RWMutex{},
+		lifecycle:   sync.Mutex{},
 	}
 }
 
-func (storage *Storage) Start(events *event.Events) {
-	for name, resource := range storage.memory {
-		resource.Start(name, storage, events)
+func (storage *Storage) Start(ctx context.Context, events *event.Events, clock clock.Clock, seed uint64) error {
+	storage.lifecycle.Lock()
+	defer storage.lifecycle.Unlock()
+
+	if storage.running {
+		return ErrRunning
+	}
+
+	var errs []error
+
+	for _, name := range storage.Names() {
+		if err := CheckName(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	graph := storage.Graph()
+
+	if err := errors.Join(append(errs, graph.Validate())...); err != nil {
+		return err
+	}
+
+	storage.clock = clock
+	storage.seed = seed
+	storage.events = events
+	storage.events.SetClock(clock)
+
+	started := make([]string, 0, len(graph.nodes))
+
+	for _, name := range graph.Order() {
+		resource, _ := storage.Lookup(name)
+		err := ctx.Err()
+
+		if err == nil {
+			err = resource.Start(ctx, name, storage, events)
+		}
+
+		if err != nil {
+			storage.events = nil
+
+			return errors.Join(
+				fmt.Errorf("%w: %s: %w", ErrStart, name, err),
+				storage.rollback(context.WithoutCancel(ctx), started),
+			)
+		}
+
+		started = append(started, name)
 	}
+
+	storage.running = true
+
+	if storage.Topological() {
+		propagator := newPropagator(storage, events)
+		propagator.start()
+
+		storage.mutex.Lock()
+		storage.propagator = propagator
+		storage.mutex.Unlock()
+	}
+
+	return nil
+}
+
+func (storage *Storage) Stop(ctx context.Context) error {
+	storage.lifecycle.Lock()
+	defer storage.lifecycle.Unlock()
+
+	if !storage.running {
+		return nil
+	}
+
+	if storage.propagator != nil {
+		storage.propagator.stop()
+
+		storage.mutex.Lock()
+		storage.propagator = nil
+		storage.mutex.Unlock()
+	}
+
+	err := storage.rollback(ctx, storage.Graph().Order())
+
+	storage.running = false
+	storage.events = nil
+
+	return err
 }
 
-func (storage *Storage) Stop() {
-	for _, resource := range storage.memory {
-		resource.Stop()
+func (storage *Storage) Running() bool {
+	storage.lifecycle.Lock()
+	defer storage.lifecycle.Unlock()
+
+	return storage.running
+}
+
+func (storage *Storage) rollback(ctx context.Context, started []string) error {
+	var errs []error
+
+	for i := len(started) - 1; i >= 0; i-- {
+		if resource, ok := storage.Lookup(started[i]); ok {
+			if err := resource.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s: %w", ErrStop, started[i], err))
+			}
+		}
 	}
+
+	return errors.Join(errs...)
+}
+
+func (storage *Storage) Clock() clock.Clock {
+	return storage.clock
+}
+
+func (storage *Storage) Intercept(interceptor Interceptor) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	storage.interceptor = interceptor
+}
+
+func (storage *Storage) Annotate(name string, metadata Metadata) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	storage.metadata[name] = metadata
+}
+
+func (storage *Storage) Metadata(name string) (Metadata, bool) {
+	storage.mutex.RLock()
+	metadata, ok := storage.metadata[name]
+	storage.mutex.RUnlock()
+
+	if ok {
+		return metadata, true
+	}
+
+	resource, _ := storage.Lookup(name)
+
+	if describer, ok := resource.(Describer); ok {
+		return describer.Metadata(), true
+	}
+
+	return Metadata{}, false
+}
+
+func (storage *Storage) Catalog() map[string]Metadata {
+	catalog := make(map[string]Metadata)
+
+	for _, name := range storage.Names() {
+		if metadata, ok := storage.Metadata(name); ok {
+			catalog[name] = metadata
+		}
+	}
+
+	return catalog
+}
+
+func (storage *Storage) Source(name string) rand.Source {
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+
+	return rand.NewPCG(storage.seed, hash.Sum64())
+}
+
+func (storage *Storage) Names() []string {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	names := make([]string, 0, len(storage.memory))
+
+	for name := range storage.memory {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+func (storage *Storage) Lookup(name string) (Resource, bool) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	resource, ok := storage.memory[name]
+
+	return resource, ok
 }
 
 func (storage *Storage) Read(resource string) (any, error) {
-	if reader, ok := storage.memory[resource].(Reader); ok {
-		result, err := reader.Read()
+	sample, err := storage.ReadSample(resource)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sample.Value, nil
+}
+
+func (storage *Storage) ReadSample(resource string) (Sample, error) {
+	found, _ := storage.Lookup(resource)
+
+	if reader, ok := found.(Reader); ok && storage.readable(resource) {
+		sample, err := storage.sample(reader)
+
+		storage.mutex.RLock()
+		interceptor := storage.interceptor
+		storage.mutex.RUnlock()
+
+		if interceptor != nil {
+			sample, err = interceptor.Intercept(resource, sample, err)
+		}
 
 		if err != nil {
-			return nil, errors.Join(
+			return Sample{}, errors.Join(
 				fmt.Errorf("%w: %s", ErrRead, resource),
 				err,
 			)
 		}
 
-		return result, nil
+		return sample, nil
 	}
 
-	return nil, errors.Join(
+	return Sample{}, errors.Join(
 		fmt.Errorf("%w: %s", ErrRead, resource),
 		ErrResourceNotReadable,
 	)
 }
 
+func (storage *Storage) sample(reader Reader) (Sample, error) {
+	now := storage.clock.Now()
+
+	if sampler, ok := reader.(Sampler); ok {
+		sample, err := sampler.Sample()
+		sample.ServerTimestamp = now
+
+		return sample, err
+	}
+
+	value, err := reader.Read()
+
+	return Sample{
+		Value:           value,
+		Quality:         quality.Good,
+		SourceTimestamp: now,
+		ServerTimestamp: now,
+	}, err
+}
+
 func (storage *Storage) Write(resource string, value any) error {
-	if writer, ok := storage.memory[resource].(Writer); ok {
+	found, _ := storage.Lookup(resource)
+
+	if writer, ok := found.(Writer); ok && storage.writable(resource) {
 		if err := writer.Write(value); err != nil {
 			return errors.Join(
 				fmt.Errorf("%w: %s", ErrWrite, resource),
@@ -86,6 +353,14 @@ func (storage *Storage) Write(resource string, value any) error {
 			)
 		}
 
+		storage.mutex.RLock()
+		propagator := storage.propagator
+		storage.mutex.RUnlock()
+
+		if propagator != nil {
+			propagator.write(resource)
+		}
+
 		return nil
 	}
 
@@ -94,3 +369,15 @@ func (storage *Storage) Write(resource string, value any) error {
 		ErrResourceNotWritable,
 	)
 }
+
+func (storage *Storage) readable(resource string) bool {
+	metadata, _ := storage.Metadata(resource)
+
+	return metadata.Access.Readable()
+}
+
+func (storage *Storage) writable(resource string) bool {
+	metadata, _ := storage.Metadata(resource)
+
+	return metadata.Access.Writable()
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+type Parameters map[string]any
+
+type Template struct {
+	name       string
+	parameters Parameters
+	build      func(parameters Parameters) (*Storage, error)
+}
+
+var (
+	ErrUnknownParameter = errors.New("unknown template parameter")
+	ErrMissingParameter = errors.New("missing template parameter")
+)
+
+func NewTemplate(name string, parameters Parameters, build func(parameters Parameters) (*Storage, error)) *Template {
+	return &Template{
+		name:       name,
+		parameters: parameters,
+		build:      build,
+	}
+}
+
+func Parameter[T any](parameters Parameters, name string) T {
+	value, _ := parameters[name].(T)
+
+	return value
+}
+
+func (template *Template) Name() string {
+	return template.name
+}
+
+func (template *Template) Parameters() Parameters {
+	return maps.Clone(template.parameters)
+}
+
+func (template *Template) Instantiate(overrides Parameters) (*Storage, error) {
+	parameters := template.Parameters()
+
+	if parameters == nil {
+		parameters = make(Parameters)
+	}
+
+	var errs []error
+
+	for _, name := range sortedKeys(overrides) {
+		if _, ok := parameters[name]; !ok {
+			errs = append(errs, fmt.Errorf("%w: %s in template %s", ErrUnknownParameter, name, template.name))
+			continue
+		}
+
+		parameters[name] = overrides[name]
+	}
+
+	for _, name := range sortedKeys(parameters) {
+		if parameters[name] == nil {
+			errs = append(errs, fmt.Errorf("%w: %s in template %s", ErrMissingParameter, name, template.name))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return template.build(parameters)
+}
+
+func sortedKeys(parameters Parameters) []string {
+	keys := make([]string, 0, len(parameters))
+
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func (storage *Storage) Instantiate(ctx context.Context, prefix string, template *Template, overrides Parameters) error {
+	instance, err := template.Instantiate(overrides)
+
+	if err != nil {
+		return err
+	}
+
+	return storage.Mount(ctx, prefix, instance)
+}